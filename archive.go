@@ -0,0 +1,153 @@
+package httpr
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveLimits restricts size of archives extracted with Response.ExtractTar and Response.ExtractTarGz,
+// protecting from archive bombs. Zero values mean no limit.
+type ArchiveLimits struct {
+	// MaxEntries is maximum number of archive entries.
+	MaxEntries int
+	// MaxFileSize is maximum size of single extracted file.
+	MaxFileSize int64
+	// MaxTotalSize is maximum total size of extracted files.
+	MaxTotalSize int64
+}
+
+// DefaultArchiveLimits are used, when limits aren't provided to Response.ExtractTar and Response.ExtractTarGz.
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxEntries:   10000,
+	MaxFileSize:  1 << 30,
+	MaxTotalSize: 4 << 30,
+}
+
+// ExtractTar extracts tar archive from response body into dstDir, creating it if necessary.
+// Entries escaping dstDir with absolute paths or ".." elements, as well as symbolic and hard links,
+// are rejected with ErrUnsafeArchive. Exceeding of limits results in ErrArchiveTooLarge;
+// if limits are omitted, DefaultArchiveLimits are used. Special files like devices are skipped,
+// setuid, setgid and sticky bits are cleared. On error, already extracted files are left in place.
+func (r *Response) ExtractTar(dstDir string, limits ...ArchiveLimits) error {
+	return extractTar(r.Reader(), dstDir, archiveLimits(limits))
+}
+
+// ExtractTarGz extracts gzip compressed tar archive from response body into dstDir,
+// see ExtractTar for details.
+func (r *Response) ExtractTarGz(dstDir string, limits ...ArchiveLimits) error {
+	gz, err := gzip.NewReader(r.Reader())
+	if err != nil {
+		return fmt.Errorf("failed to read gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	return extractTar(gz, dstDir, archiveLimits(limits))
+}
+
+func archiveLimits(limits []ArchiveLimits) ArchiveLimits {
+	if len(limits) == 0 {
+		return DefaultArchiveLimits
+	}
+	return limits[0]
+}
+
+func extractTar(src io.Reader, dstDir string, limits ArchiveLimits) error {
+	root, err := filepath.Abs(dstDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination directory: %w", err)
+	}
+	if err = os.MkdirAll(root, 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	var (
+		tr      = tar.NewReader(src)
+		entries int
+		total   int64
+	)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		entries++
+		if limits.MaxEntries > 0 && entries > limits.MaxEntries {
+			return fmt.Errorf("%w: more than %d entries", ErrArchiveTooLarge, limits.MaxEntries)
+		}
+
+		target, err := archiveEntryPath(root, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0o755); err != nil { //nolint:gosec
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if limits.MaxFileSize > 0 && hdr.Size > limits.MaxFileSize {
+				return fmt.Errorf("%w: file %q is larger than %d bytes", ErrArchiveTooLarge, hdr.Name, limits.MaxFileSize)
+			}
+			if total += hdr.Size; limits.MaxTotalSize > 0 && total > limits.MaxTotalSize {
+				return fmt.Errorf("%w: archive is larger than %d bytes", ErrArchiveTooLarge, limits.MaxTotalSize)
+			}
+			if err = extractTarFile(tr, target, hdr); err != nil {
+				return err
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("%w: link entry %q", ErrUnsafeArchive, hdr.Name)
+		default:
+			// Devices, FIFOs and other special files are never extracted.
+		}
+	}
+}
+
+// archiveEntryPath returns path of archive entry inside root, failing for entries escaping it.
+func archiveEntryPath(root, name string) (string, error) {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: absolute path %q", ErrUnsafeArchive, name)
+	}
+
+	target := filepath.Join(root, name)
+	if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: path %q escapes destination directory", ErrUnsafeArchive, name)
+	}
+	return target, nil
+}
+
+func extractTarFile(tr *tar.Reader, target string, hdr *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Existing file is removed first, so link planted at target path is never followed.
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, hdr.FileInfo().Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err = io.Copy(f, tr); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to extract file %q: %w", hdr.Name, err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	return nil
+}
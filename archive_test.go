@@ -0,0 +1,171 @@
+package httpr
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testTarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	mode     int64
+}
+
+func makeTar(t *testing.T, entries []testTarEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: e.mode, Size: int64(len(e.body))}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0o644
+		}
+		if e.typeflag == tar.TypeSymlink || e.typeflag == tar.TypeLink {
+			hdr.Linkname, hdr.Size = e.body, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to write tar header: %s", err)
+		}
+		if hdr.Size > 0 {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatalf("failed to write tar entry: %s", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func archiveResponse(body []byte) *Response {
+	return &Response{rawResp: &http.Response{StatusCode: http.StatusOK}, body: body}
+}
+
+func TestResponseExtractTar(t *testing.T) {
+	archive := makeTar(t, []testTarEntry{
+		{name: "bundle/", typeflag: tar.TypeDir},
+		{name: "bundle/a.txt", typeflag: tar.TypeReg, body: "hello"},
+		{name: "bundle/nested/b.txt", typeflag: tar.TypeReg, body: "world", mode: 0o4755},
+		{name: "bundle/fifo", typeflag: tar.TypeFifo},
+	})
+
+	dir := t.TempDir()
+	if err := archiveResponse(archive).ExtractTar(dir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for name, expected := range map[string]string{"bundle/a.txt": "hello", "bundle/nested/b.txt": "world"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read extracted file: %s", err)
+		}
+		if string(data) != expected {
+			t.Errorf("expected %s content %q, got %q instead", name, expected, data)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "bundle/nested/b.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if info.Mode()&os.ModeSetuid != 0 {
+		t.Error("expected setuid bit to be cleared")
+	}
+	if _, err = os.Lstat(filepath.Join(dir, "bundle/fifo")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected special file to be skipped")
+	}
+}
+
+func TestResponseExtractTarGz(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write(makeTar(t, []testTarEntry{{name: "a.txt", typeflag: tar.TypeReg, body: "hello"}}))
+	_ = gz.Close()
+
+	dir := t.TempDir()
+	if err := archiveResponse(buf.Bytes()).ExtractTarGz(dir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "a.txt")); err != nil || string(data) != "hello" {
+		t.Errorf("expected extracted file content %q, got %q (%v) instead", "hello", data, err)
+	}
+
+	if err := archiveResponse([]byte("not gzip")).ExtractTarGz(dir); err == nil {
+		t.Error("expected error for invalid gzip archive, got nil instead")
+	}
+}
+
+func TestResponseExtractTarUnsafe(t *testing.T) {
+	testCases := []struct {
+		name     string
+		entries  []testTarEntry
+		limits   []ArchiveLimits
+		expected error
+	}{
+		{
+			name:     "path traversal",
+			entries:  []testTarEntry{{name: "../evil.txt", typeflag: tar.TypeReg, body: "x"}},
+			expected: ErrUnsafeArchive,
+		},
+		{
+			name:     "nested path traversal",
+			entries:  []testTarEntry{{name: "a/../../evil.txt", typeflag: tar.TypeReg, body: "x"}},
+			expected: ErrUnsafeArchive,
+		},
+		{
+			name:     "absolute path",
+			entries:  []testTarEntry{{name: "/tmp/evil.txt", typeflag: tar.TypeReg, body: "x"}},
+			expected: ErrUnsafeArchive,
+		},
+		{
+			name:     "symlink",
+			entries:  []testTarEntry{{name: "link", typeflag: tar.TypeSymlink, body: "/etc/passwd"}},
+			expected: ErrUnsafeArchive,
+		},
+		{
+			name:     "hard link",
+			entries:  []testTarEntry{{name: "link", typeflag: tar.TypeLink, body: "/etc/passwd"}},
+			expected: ErrUnsafeArchive,
+		},
+		{
+			name:     "too many entries",
+			entries:  []testTarEntry{{name: "a", typeflag: tar.TypeReg}, {name: "b", typeflag: tar.TypeReg}},
+			limits:   []ArchiveLimits{{MaxEntries: 1}},
+			expected: ErrArchiveTooLarge,
+		},
+		{
+			name:     "file too large",
+			entries:  []testTarEntry{{name: "a", typeflag: tar.TypeReg, body: "hello"}},
+			limits:   []ArchiveLimits{{MaxFileSize: 4}},
+			expected: ErrArchiveTooLarge,
+		},
+		{
+			name:     "archive too large",
+			entries:  []testTarEntry{{name: "a", typeflag: tar.TypeReg, body: "hello"}, {name: "b", typeflag: tar.TypeReg, body: "world"}},
+			limits:   []ArchiveLimits{{MaxTotalSize: 8}},
+			expected: ErrArchiveTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "dst")
+			err := archiveResponse(makeTar(t, tc.entries)).ExtractTar(dir, tc.limits...)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v instead", tc.expected, err)
+			}
+			if _, err = os.Stat(filepath.Join(filepath.Dir(dir), "evil.txt")); !errors.Is(err, os.ErrNotExist) {
+				t.Error("expected file outside of destination directory not to be created")
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package httpr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenBucket limits throughput to rate bytes per second with burst of one second worth of bytes.
+// Tokens may go negative, so concurrent consumers queue up fairly instead of competing for refills.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(bytesPerSec int64) *tokenBucket {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+
+	return &tokenBucket{
+		rate:   float64(bytesPerSec),
+		tokens: float64(bytesPerSec),
+		last:   time.Now(),
+	}
+}
+
+// burst returns maximal number of bytes transferred at once.
+func (b *tokenBucket) burst() int {
+	return int(b.rate)
+}
+
+// wait consumes n tokens, blocking until they are available or context is done.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	deficit := -b.tokens
+	b.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(deficit / b.rate * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// bandwidthLimits holds token buckets for downloads and uploads. Nil bucket means unlimited direction.
+type bandwidthLimits struct {
+	read  *tokenBucket
+	write *tokenBucket
+}
+
+// throttledBody is io.ReadCloser, which reads from underlying body not faster than token bucket allows.
+type throttledBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	bucket *tokenBucket
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if burst := b.bucket.burst(); len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := b.bucket.wait(b.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+// throttleRequestBody wraps request body, so it's uploaded within bandwidth limit.
+func throttleRequestBody(req *http.Request, limits *bandwidthLimits) {
+	if limits == nil || limits.write == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	if _, ok := req.Body.(*throttledBody); ok {
+		return
+	}
+
+	req.Body = &throttledBody{ReadCloser: req.Body, ctx: req.Context(), bucket: limits.write}
+}
+
+// throttleResponseBody wraps response body, so it's downloaded within bandwidth limit.
+func throttleResponseBody(resp *http.Response, limits *bandwidthLimits) {
+	if limits == nil || limits.read == nil || resp.Body == nil {
+		return
+	}
+
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+	resp.Body = &throttledBody{ReadCloser: resp.Body, ctx: ctx, bucket: limits.read}
+}
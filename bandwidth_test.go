@@ -0,0 +1,92 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimit(t *testing.T) {
+	const (
+		rate = 10_000
+		size = 15_000
+	)
+	payload := bytes.Repeat([]byte{'x'}, size)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			body, _ := io.ReadAll(req.Body)
+			_, _ = w.Write([]byte{byte(len(body) / 1000)})
+			return
+		}
+		_, _ = w.Write(payload)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	// First second worth of bytes is available immediately, remaining 5000 bytes take 0.5s.
+	minDuration := 400 * time.Millisecond
+
+	testCases := []struct {
+		name    string
+		limit   Option
+		upload  bool
+		limited bool
+	}{
+		{name: "download", limit: WithBandwidthLimit(rate, 0), limited: true},
+		{name: "upload", limit: WithBandwidthLimit(0, rate), upload: true, limited: true},
+		{name: "download with upload limit", limit: WithBandwidthLimit(0, rate)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := New(tc.limit)
+
+			start := time.Now()
+			var (
+				resp *Response
+				err  error
+			)
+			if tc.upload {
+				resp, err = client.Post(ctx, ts.URL, payload)
+			} else {
+				resp, err = client.Get(ctx, ts.URL, nil)
+			}
+			elapsed := time.Since(start)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.upload && !bytes.Equal(resp.Bytes(), []byte{size / 1000}) {
+				t.Errorf("expected server to receive %d bytes, got %v instead", size, resp.Bytes())
+			}
+			if !tc.upload && len(resp.Bytes()) != size {
+				t.Errorf("expected %d bytes, got %d instead", size, len(resp.Bytes()))
+			}
+
+			if tc.limited && elapsed < minDuration {
+				t.Errorf("expected transfer to take at least %s, took %s instead", minDuration, elapsed)
+			}
+			if !tc.limited && elapsed >= minDuration {
+				t.Errorf("expected unlimited transfer, took %s instead", elapsed)
+			}
+		})
+	}
+}
+
+func TestBandwidthLimitCancel(t *testing.T) {
+	bucket := newTokenBucket(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := bucket.wait(ctx, 10); err != nil {
+		t.Fatalf("expected burst to be available, got %v instead", err)
+	}
+	if err := bucket.wait(ctx, 10); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v instead", err)
+	}
+}
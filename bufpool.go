@@ -0,0 +1,72 @@
+package httpr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	// _maxBodySizeHint limits Content-Length value trusted for preallocation of body buffer,
+	// so bogus header can't make client allocate huge buffer upfront.
+	_maxBodySizeHint = 8 << 20
+	// _maxPooledBufferSize limits capacity of buffers returned to pool, so occasional large
+	// bodies don't pin memory.
+	_maxPooledBufferSize = 4 << 20
+)
+
+var _bodyBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// readSized reads src until EOF. If size hint (usually Content-Length) is known, body is read into
+// single exactly sized allocation. Otherwise, it's read into pooled buffer, which is then copied,
+// so growing of buffer doesn't produce garbage on every request. Non-empty body shorter than
+// size hint is truncated, so io.ErrUnexpectedEOF is returned for it.
+func readSized(src io.Reader, sizeHint int64) ([]byte, error) {
+	if sizeHint <= 0 || sizeHint > _maxBodySizeHint {
+		return readPooled(src, nil)
+	}
+
+	// Extra byte of capacity is used to make sure body isn't longer than hinted.
+	body := make([]byte, sizeHint, sizeHint+1)
+	n, err := io.ReadFull(src, body)
+	if n == 0 && errors.Is(err, io.EOF) {
+		return body[:0], nil
+	}
+	if err != nil {
+		return body[:n], err
+	}
+
+	for {
+		n, err = src.Read(body[sizeHint : sizeHint+1])
+		if n > 0 {
+			return readPooled(src, body[:sizeHint+1])
+		}
+		if errors.Is(err, io.EOF) {
+			return body, nil
+		}
+		if err != nil {
+			return body, err
+		}
+	}
+}
+
+// readPooled reads prefix and remaining src contents into pooled buffer and returns their copy.
+func readPooled(src io.Reader, prefix []byte) ([]byte, error) {
+	buf, _ := _bodyBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= _maxPooledBufferSize {
+			_bodyBufferPool.Put(buf)
+		}
+	}()
+
+	buf.Write(prefix)
+	_, err := buf.ReadFrom(src)
+
+	body := make([]byte, buf.Len())
+	copy(body, buf.Bytes())
+	return body, err
+}
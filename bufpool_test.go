@@ -0,0 +1,86 @@
+package httpr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadSized(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	testCases := []struct {
+		name     string
+		sizeHint int64
+	}{
+		{name: "unknown size", sizeHint: -1},
+		{name: "exact size", sizeHint: int64(len(data))},
+		{name: "smaller hint", sizeHint: 100},
+		{name: "hint above limit", sizeHint: _maxBodySizeHint + 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := readSized(iotest.HalfReader(bytes.NewReader(data)), tc.sizeHint)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !bytes.Equal(body, data) {
+				t.Errorf("expected %d bytes of data, got %d instead", len(data), len(body))
+			}
+		})
+	}
+
+	t.Run("truncated body", func(t *testing.T) {
+		body, err := readSized(iotest.HalfReader(bytes.NewReader(data)), int64(len(data))*2)
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected io.ErrUnexpectedEOF, got %v instead", err)
+		}
+		if len(body) != len(data) {
+			t.Errorf("expected %d bytes of data, got %d instead", len(data), len(body))
+		}
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		body, err := readSized(bytes.NewReader(nil), 100)
+		if err != nil || len(body) != 0 {
+			t.Fatalf("expected empty body without error, got %d bytes and %v instead", len(body), err)
+		}
+	})
+
+	t.Run("pooled buffer isn't shared", func(t *testing.T) {
+		first, _ := readSized(bytes.NewReader([]byte("first")), -1)
+		_, _ = readSized(bytes.NewReader([]byte("second")), -1)
+		if string(first) != "first" {
+			t.Errorf("expected body 'first', got '%s' instead", first)
+		}
+	})
+}
+
+func BenchmarkReadBody(b *testing.B) {
+	for _, size := range []int{1 << 10, 64 << 10, 1 << 20} {
+		data := bytes.Repeat([]byte{'x'}, size)
+
+		b.Run("ReadAll/"+strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = io.ReadAll(bytes.NewReader(data))
+			}
+		})
+		b.Run("Pooled/"+strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = readSized(bytes.NewReader(data), -1)
+			}
+		})
+		b.Run("ContentLength/"+strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = readSized(bytes.NewReader(data), int64(size))
+			}
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package httpr
+
+import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultCacheMaxEntries = 1000
+	// _cacheHeuristicMaxAge caps heuristic freshness lifetime of responses without explicit one.
+	_cacheHeuristicMaxAge = 24 * time.Hour
+)
+
+// _cacheableStatuses are statuses of responses, which are cacheable by default (RFC 9110, section 15.1).
+var _cacheableStatuses = map[int]struct{}{
+	http.StatusOK:                   {},
+	http.StatusNonAuthoritativeInfo: {},
+	http.StatusNoContent:            {},
+	http.StatusMultipleChoices:      {},
+	http.StatusMovedPermanently:     {},
+	http.StatusPermanentRedirect:    {},
+	http.StatusNotFound:             {},
+	http.StatusMethodNotAllowed:     {},
+	http.StatusGone:                 {},
+	http.StatusRequestURITooLong:    {},
+	http.StatusNotImplemented:       {},
+}
+
+// Cache stores responses of GET requests, see WithCache. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns entry stored by key.
+	Get(key string) (*CacheEntry, bool)
+	// Set stores entry by key, replacing existing one. Entry must not be modified after it's stored.
+	Set(key string, entry *CacheEntry)
+	// Delete removes entry stored by key.
+	Delete(key string)
+}
+
+// CacheEntry is response stored in Cache. Body is stored after decompression and charset decoding.
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// VaryHeader contains values of request headers listed in 'Vary' response header,
+	// which stored response can only be reused for.
+	VaryHeader http.Header
+	// RequestTime and ResponseTime are times of request sending and response receiving,
+	// used for response age calculation.
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+// MemoryCache is in-memory Cache, which evicts least recently used entries,
+// when number of entries exceeds limit.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache creates MemoryCache, holding up to maxEntries entries. If maxEntries
+// isn't positive, limit of 1000 entries is used.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = _defaultCacheMaxEntries
+	}
+
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns entry stored by key.
+func (c *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+
+	return elem.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores entry by key, evicting least recently used entry, if limit is exceeded.
+func (c *MemoryCache) Set(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete removes entry stored by key.
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns number of stored entries.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// cacheLookup is result of cache lookup for request.
+type cacheLookup struct {
+	key   string
+	entry *CacheEntry
+	// fresh is set, if entry can be served without contacting origin.
+	fresh bool
+	// revalidating is set, if request was made conditional with validators of entry.
+	revalidating bool
+	requestTime  time.Time
+}
+
+// lookupCache looks up cached response for request. Nil is returned, if request can't use cache.
+// If stored response is stale, request is made conditional, so it can be revalidated.
+func lookupCache(req *http.Request, settings clientSettings, readBody bool) *cacheLookup {
+	if settings.cache == nil || !readBody {
+		return nil
+	}
+
+	key := cacheKey(req.URL)
+	now := settings.clock().Now()
+	if composeMethod(req.Method) != http.MethodGet {
+		// Unsafe methods invalidate stored response, see cacheLookup.store.
+		return &cacheLookup{key: key, requestTime: now}
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return nil
+	}
+
+	lookup := &cacheLookup{key: key, requestTime: now}
+	entry, ok := settings.cache.Get(key)
+	if !ok || !entry.matches(req) {
+		return lookup
+	}
+	lookup.entry = entry
+
+	lookup.fresh = entry.fresh(reqCC, now) && req.Header.Get("Pragma") != "no-cache"
+	if !lookup.fresh && !hasConditionalHeaders(req) {
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+			lookup.revalidating = true
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+			lookup.revalidating = true
+		}
+	}
+
+	return lookup
+}
+
+// store updates cache with received response: stores cacheable response, refreshes revalidated
+// entry (returning response composed from it) and invalidates entry on successful unsafe request.
+func (l *cacheLookup) store(req *http.Request, resp *Response, settings clientSettings, dst *Response) *Response {
+	if l == nil || resp == nil || resp.rawResp == nil || resp.fromCache {
+		return resp
+	}
+	now := settings.clock().Now()
+
+	if composeMethod(req.Method) != http.MethodGet {
+		if !isSafeMethod(req.Method) && resp.StatusCode() < http.StatusBadRequest {
+			settings.cache.Delete(l.key)
+		}
+		return resp
+	}
+
+	if l.revalidating && resp.StatusCode() == http.StatusNotModified {
+		entry := *l.entry
+		entry.Header = l.entry.Header.Clone()
+		for key, values := range resp.rawResp.Header {
+			switch key {
+			case "Content-Length", "Content-Encoding", "Transfer-Encoding", "Content-Range":
+			default:
+				entry.Header[key] = append([]string(nil), values...)
+			}
+		}
+		entry.RequestTime, entry.ResponseTime = l.requestTime, now
+		settings.cache.Set(l.key, &entry)
+
+		_ = resp.Close()
+		return entry.response(req, dst, now)
+	}
+
+	if !isCacheable(req, resp) {
+		return resp
+	}
+
+	body, err := resp.bodyBytes()
+	if err != nil {
+		return resp
+	}
+	settings.cache.Set(l.key, &CacheEntry{
+		StatusCode:   resp.StatusCode(),
+		Header:       resp.rawResp.Header.Clone(),
+		Body:         append([]byte(nil), body...),
+		VaryHeader:   varyHeader(req, resp.rawResp.Header),
+		RequestTime:  l.requestTime,
+		ResponseTime: now,
+	})
+
+	return resp
+}
+
+// staleResponse returns stored response instead of network error or 5xx response, if serving
+// of stale responses is enabled with WithStaleIfError and entry isn't too stale.
+func (l *cacheLookup) staleResponse(req *http.Request, resp *Response, err error, settings clientSettings, dst *Response) (*Response, bool) {
+	if l == nil || l.entry == nil || settings.staleIfError <= 0 {
+		return nil, false
+	}
+	if !RetryOnNetworkError(resp, err) && (err != nil || !RetryOn5xx(resp, nil)) {
+		return nil, false
+	}
+
+	now := settings.clock().Now()
+	if !l.entry.usableStale(settings.staleIfError, now) {
+		return nil, false
+	}
+
+	if resp != nil && resp != dst {
+		_ = resp.Close()
+	}
+	stale := l.entry.response(req, dst, now)
+	stale.stale = true
+
+	return stale, true
+}
+
+// usableStale reports whether stored response may be served stale on error (RFC 5861):
+// its staleness must not exceed maxStale or 'stale-if-error' directive, whichever is greater,
+// and it must not require revalidation.
+func (e *CacheEntry) usableStale(maxStale time.Duration, now time.Time) bool {
+	cc := parseCacheControl(e.Header)
+	for _, directive := range []string{"must-revalidate", "no-cache"} {
+		if _, ok := cc[directive]; ok {
+			return false
+		}
+	}
+
+	if staleIfError, ok := cacheControlSeconds(cc, "stale-if-error"); ok && staleIfError > maxStale {
+		maxStale = staleIfError
+	}
+	return e.age(now)-e.freshnessLifetime() <= maxStale
+}
+
+// response composes Response from stored entry.
+func (e *CacheEntry) response(req *http.Request, dst *Response, now time.Time) *Response {
+	r := dst
+	var buf []byte
+	if r == nil {
+		r = new(Response)
+	} else {
+		buf = r.reset()
+	}
+
+	header := e.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	r.rawResp = &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          http.NoBody,
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+	r.body = append(buf[:0], e.Body...)
+	r.fromCache = true
+
+	return r
+}
+
+// matches reports whether stored response can be used for request according to its 'Vary' header.
+func (e *CacheEntry) matches(req *http.Request) bool {
+	for key, values := range e.VaryHeader {
+		if strings.Join(values, ",") != strings.Join(req.Header.Values(key), ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// fresh reports whether stored response can be served without validation (RFC 9111, section 4.2).
+func (e *CacheEntry) fresh(reqCC map[string]string, now time.Time) bool {
+	cc := parseCacheControl(e.Header)
+	if _, ok := reqCC["no-cache"]; ok {
+		return false
+	}
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+
+	age := e.age(now)
+	if maxAge, ok := cacheControlSeconds(reqCC, "max-age"); ok && age > maxAge {
+		return false
+	}
+	if minFresh, ok := cacheControlSeconds(reqCC, "min-fresh"); ok {
+		age += minFresh
+	}
+
+	return age < e.freshnessLifetime()
+}
+
+// freshnessLifetime returns explicit freshness lifetime of stored response or heuristic one,
+// which is 10% of time passed since its last modification.
+func (e *CacheEntry) freshnessLifetime() time.Duration {
+	if maxAge, ok := cacheControlSeconds(parseCacheControl(e.Header), "max-age"); ok {
+		return maxAge
+	}
+
+	date := e.date()
+	if expires := e.Header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return expiresAt.Sub(date)
+	}
+
+	if lastModified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil && date.After(lastModified) {
+		lifetime := date.Sub(lastModified) / 10
+		if lifetime > _cacheHeuristicMaxAge {
+			lifetime = _cacheHeuristicMaxAge
+		}
+		return lifetime
+	}
+
+	return 0
+}
+
+// age returns current age of stored response (RFC 9111, section 4.2.3).
+func (e *CacheEntry) age(now time.Time) time.Duration {
+	apparentAge := e.ResponseTime.Sub(e.date())
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+
+	correctedAge := e.ResponseTime.Sub(e.RequestTime)
+	if seconds, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		correctedAge += time.Duration(seconds) * time.Second
+	}
+	if correctedAge > apparentAge {
+		apparentAge = correctedAge
+	}
+
+	return apparentAge + now.Sub(e.ResponseTime)
+}
+
+// date returns value of 'Date' header of stored response, falling back to response receiving time.
+func (e *CacheEntry) date() time.Time {
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return date
+	}
+	return e.ResponseTime
+}
+
+// isCacheable reports whether response of GET request may be stored (RFC 9111, section 3).
+// Responses without freshness information are stored too, so they can be revalidated.
+func isCacheable(req *http.Request, resp *Response) bool {
+	if _, ok := _cacheableStatuses[resp.StatusCode()]; !ok || resp.bodyFile != nil {
+		return false
+	}
+	if _, ok := parseCacheControl(req.Header)["no-store"]; ok {
+		return false
+	}
+	if _, ok := parseCacheControl(resp.rawResp.Header)["no-store"]; ok {
+		return false
+	}
+
+	for _, value := range resp.rawResp.Header.Values("Vary") {
+		if strings.TrimSpace(value) == "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// varyHeader returns values of request headers listed in 'Vary' response header.
+func varyHeader(req *http.Request, respHeader http.Header) http.Header {
+	var vary http.Header
+	for _, value := range respHeader.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if vary == nil {
+				vary = make(http.Header)
+			}
+			vary[http.CanonicalHeaderKey(name)] = append([]string(nil), req.Header.Values(name)...)
+		}
+	}
+	return vary
+}
+
+// parseCacheControl parses 'Cache-Control' header directives into map of lowercased names to values.
+func parseCacheControl(header http.Header) map[string]string {
+	var directives map[string]string
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			if directives == nil {
+				directives = make(map[string]string)
+			}
+			directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+	return directives
+}
+
+func cacheControlSeconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func hasConditionalHeaders(req *http.Request) bool {
+	for _, key := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"} {
+		if req.Header.Get(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKey returns key of stored response for URL, which is URL without fragment.
+func cacheKey(u *url.URL) string {
+	key := *u
+	key.Fragment, key.RawFragment = "", ""
+	return key.String()
+}
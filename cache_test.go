@@ -0,0 +1,290 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCache(t *testing.T) {
+	var (
+		hits  int32
+		clock = NewFakeClock(time.Now())
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Date", clock.Now().UTC().Format(http.TimeFormat))
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.Header().Set("Cache-Control", "max-age=120")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		_, _ = w.Write([]byte("body of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, client *Client, path string, headers ...string) *Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+path, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected request error: %v", err)
+		}
+		if expected := "body of " + path; resp.String() != expected {
+			t.Fatalf("expected body %q, got %q instead", expected, resp.String())
+		}
+		return resp
+	}
+	expectHits := func(t *testing.T, expected int32) {
+		t.Helper()
+		if got := atomic.SwapInt32(&hits, 0); got != expected {
+			t.Fatalf("expected %d request(s) to origin, got %d instead", expected, got)
+		}
+	}
+
+	t.Run("Fresh", func(t *testing.T) {
+		client := New(WithCache(NewMemoryCache(0)), WithClock(clock))
+
+		if get(t, client, "/fresh").FromCache() {
+			t.Fatal("expected first response not to be served from cache")
+		}
+		clock.Advance(30 * time.Second)
+		resp := get(t, client, "/fresh")
+		if !resp.FromCache() {
+			t.Fatal("expected response to be served from cache")
+		}
+		if age, _ := strconv.Atoi(resp.Header().Get("Age")); age < 30 {
+			t.Fatalf("expected age of at least 30 seconds, got %q instead", resp.Header().Get("Age"))
+		}
+		expectHits(t, 1)
+
+		clock.Advance(time.Minute)
+		if get(t, client, "/fresh").FromCache() {
+			t.Fatal("expected stale response without validators to be fetched again")
+		}
+		expectHits(t, 1)
+	})
+
+	t.Run("Revalidation", func(t *testing.T) {
+		client := New(WithCache(NewMemoryCache(0)), WithClock(clock))
+
+		get(t, client, "/etag")
+		clock.Advance(2 * time.Minute)
+		resp := get(t, client, "/etag")
+		if !resp.FromCache() || resp.StatusCode() != http.StatusOK {
+			t.Fatalf("expected revalidated response with status 200 from cache, got %d", resp.StatusCode())
+		}
+		expectHits(t, 2)
+
+		// Freshness lifetime is updated from 304 response.
+		clock.Advance(90 * time.Second)
+		if !get(t, client, "/etag").FromCache() {
+			t.Fatal("expected refreshed response to be served from cache")
+		}
+		expectHits(t, 0)
+
+		if get(t, client, "/etag", "Cache-Control", "no-cache").StatusCode() != http.StatusOK {
+			t.Fatal("expected response with status 200")
+		}
+		expectHits(t, 1)
+	})
+
+	t.Run("NoStore", func(t *testing.T) {
+		client := New(WithCache(NewMemoryCache(0)))
+
+		get(t, client, "/no-store")
+		get(t, client, "/no-store")
+		expectHits(t, 2)
+
+		get(t, client, "/fresh", "Cache-Control", "no-store")
+		get(t, client, "/fresh")
+		expectHits(t, 2)
+	})
+
+	t.Run("Vary", func(t *testing.T) {
+		client := New(WithCache(NewMemoryCache(0)))
+
+		get(t, client, "/vary", "Accept-Language", "en")
+		get(t, client, "/vary", "Accept-Language", "en")
+		expectHits(t, 1)
+		get(t, client, "/vary", "Accept-Language", "de")
+		expectHits(t, 1)
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		client := New(WithCache(NewMemoryCache(0)))
+
+		get(t, client, "/fresh")
+		if _, err := client.Post(context.Background(), server.URL+"/fresh", nil); err != nil {
+			t.Fatalf("unexpected request error: %v", err)
+		}
+		get(t, client, "/fresh")
+		expectHits(t, 3)
+	})
+
+	t.Run("DoRaw", func(t *testing.T) {
+		client := New(WithCache(NewMemoryCache(0)))
+
+		get(t, client, "/fresh")
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/fresh", nil)
+		resp, err := client.DoRaw(req)
+		if err != nil {
+			t.Fatalf("unexpected request error: %v", err)
+		}
+		_ = resp.Body.Close()
+		expectHits(t, 2)
+	})
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &CacheEntry{})
+	cache.Set("b", &CacheEntry{})
+	cache.Get("a")
+	cache.Set("c", &CacheEntry{})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("expected entry %q to be kept", key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d instead", cache.Len())
+	}
+}
+
+func TestCacheEntryFreshnessLifetime(t *testing.T) {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+	}{
+		{"MaxAge", http.Header{"Cache-Control": {"public, max-age=300"}}, 5 * time.Minute},
+		{"Expires", http.Header{"Expires": {date.Add(time.Hour).Format(http.TimeFormat)}}, time.Hour},
+		{"InvalidExpires", http.Header{"Expires": {"0"}}, 0},
+		{"Heuristic", http.Header{"Last-Modified": {date.Add(-10 * time.Hour).Format(http.TimeFormat)}}, time.Hour},
+		{"HeuristicCap", http.Header{"Last-Modified": {date.AddDate(-1, 0, 0).Format(http.TimeFormat)}}, 24 * time.Hour},
+		{"None", http.Header{}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.header.Set("Date", date.Format(http.TimeFormat))
+			entry := &CacheEntry{Header: tc.header, ResponseTime: date}
+			if got := entry.freshnessLifetime(); got != tc.expected {
+				t.Errorf("expected lifetime %s, got %s instead", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestClientStaleIfError(t *testing.T) {
+	var (
+		failing int32
+		clock   = NewFakeClock(time.Now())
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Date", clock.Now().UTC().Format(http.TimeFormat))
+		if r.URL.Path == "/must-revalidate" {
+			w.Header().Set("Cache-Control", "max-age=10, must-revalidate")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=10")
+		}
+		_, _ = w.Write([]byte("cached"))
+	}))
+	defer server.Close()
+
+	newClient := func(maxStale time.Duration) *Client {
+		return New(WithCache(NewMemoryCache(0)), WithClock(clock), WithStaleIfError(maxStale), WithRetryCount(1))
+	}
+	prime := func(t *testing.T, client *Client, rawURL string) {
+		t.Helper()
+		atomic.StoreInt32(&failing, 0)
+		if _, err := client.Get(context.Background(), rawURL, nil); err != nil {
+			t.Fatalf("unexpected request error: %v", err)
+		}
+		atomic.StoreInt32(&failing, 1)
+		clock.Advance(time.Minute)
+	}
+
+	t.Run("ServerError", func(t *testing.T) {
+		client := newClient(time.Hour)
+		prime(t, client, server.URL)
+
+		resp, err := client.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("unexpected request error: %v", err)
+		}
+		if !resp.Stale() || !resp.FromCache() || resp.StatusCode() != http.StatusOK || resp.String() != "cached" {
+			t.Fatalf("expected stale cached response, got %d %q (stale %t)", resp.StatusCode(), resp.String(), resp.Stale())
+		}
+	})
+
+	t.Run("NetworkError", func(t *testing.T) {
+		offline := httptest.NewServer(server.Config.Handler)
+		client := newClient(time.Hour)
+		prime(t, client, offline.URL+"/")
+		offline.Close()
+
+		resp, err := client.Get(context.Background(), offline.URL+"/", nil)
+		if err != nil || !resp.Stale() {
+			t.Fatalf("expected stale response instead of network error, got %v", err)
+		}
+	})
+
+	t.Run("TooStale", func(t *testing.T) {
+		client := newClient(time.Second)
+		prime(t, client, server.URL)
+
+		resp, err := client.Get(context.Background(), server.URL, nil)
+		if err != nil || resp.Stale() || resp.StatusCode() != http.StatusServiceUnavailable {
+			t.Fatalf("expected origin response, got %d (stale %t), %v", resp.StatusCode(), resp.Stale(), err)
+		}
+	})
+
+	t.Run("MustRevalidate", func(t *testing.T) {
+		client := newClient(time.Hour)
+		prime(t, client, server.URL+"/must-revalidate")
+
+		resp, err := client.Get(context.Background(), server.URL+"/must-revalidate", nil)
+		if err != nil || resp.Stale() || resp.StatusCode() != http.StatusServiceUnavailable {
+			t.Fatalf("expected origin response, got %d (stale %t), %v", resp.StatusCode(), resp.Stale(), err)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		client := newClient(0)
+		prime(t, client, server.URL)
+
+		if resp, _ := client.Get(context.Background(), server.URL, nil); resp.Stale() {
+			t.Fatal("expected stale responses to be disabled")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package httpr
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// CanaryTag is request tag (see Response.Tags), which tells, what target served request
+	// routed by WithCanary: CanaryTargetPrimary or CanaryTargetCanary.
+	CanaryTag = "httpr.target"
+	// CanaryTargetPrimary is value of CanaryTag for requests sent to their original URL.
+	CanaryTargetPrimary = "primary"
+	// CanaryTargetCanary is value of CanaryTag for requests routed to canary base URL.
+	CanaryTargetCanary = "canary"
+)
+
+// _canaryBuckets is number of buckets, which requests are distributed over, giving 0.01% precision.
+const _canaryBuckets = 10000
+
+// CanaryKeyFunc returns key, which makes canary routing sticky: requests with the same key
+// are always routed to the same target. Empty key means request is routed randomly.
+type CanaryKeyFunc func(req *http.Request) string
+
+// canaryRoute describes canary routing set with WithCanary.
+type canaryRoute struct {
+	target  *url.URL
+	err     error
+	buckets int
+	keyFn   CanaryKeyFunc
+}
+
+// WithCanary routes provided percent (0-100) of requests to alternate base URL for progressive rollout
+// testing of new backend version: scheme and host of request URL are replaced with ones of target,
+// and target path, if any, is prepended to request path. Every request is tagged with CanaryTag,
+// so Response.Tags and StatsHandler events tell, which target served it:
+//
+//	client := httpr.New(httpr.WithCanary("https://api-v2.example.com", 5))
+//	resp, err := client.Get(ctx, "https://api.example.com/users", nil)
+//	if resp.Tags()[httpr.CanaryTag] == httpr.CanaryTargetCanary {
+//		// served by https://api-v2.example.com/users
+//	}
+//
+// Requests are routed randomly, unless sticky key function is set with WithCanaryKey.
+// If target URL is invalid, requests fail with error.
+func WithCanary(targetBaseURL string, percent float64) Option {
+	route := &canaryRoute{buckets: int(percent * _canaryBuckets / 100)}
+	route.target, route.err = url.Parse(targetBaseURL)
+	if route.err == nil && (route.target.Scheme == "" || route.target.Host == "") {
+		route.err = fmt.Errorf("canary target %q must be absolute URL", targetBaseURL)
+	}
+
+	return func(settings *clientSettings) {
+		if settings.canary != nil {
+			route.keyFn = settings.canary.keyFn
+		}
+		settings.canary = route
+	}
+}
+
+// WithCanaryKey makes canary routing set with WithCanary sticky by key returned by provided function,
+// e.g. user ID or session cookie, so the same user is consistently served by the same target.
+func WithCanaryKey(keyFn CanaryKeyFunc) Option {
+	return func(settings *clientSettings) {
+		if settings.canary == nil {
+			settings.canary = &canaryRoute{keyFn: keyFn}
+			return
+		}
+		route := *settings.canary
+		route.keyFn = keyFn
+		settings.canary = &route
+	}
+}
+
+// routeCanary routes request according to canary settings, returning routed request and its tags
+// with CanaryTag set. Request URL isn't modified, routed request copy is returned instead. On error,
+// request and tags are returned unchanged.
+func routeCanary(req *http.Request, settings clientSettings, tags map[string]string) (*http.Request, map[string]string, error) {
+	route := settings.canary
+	if route == nil || (route.target == nil && route.err == nil) {
+		return req, tags, nil
+	}
+	if route.err != nil {
+		return req, tags, fmt.Errorf("invalid canary target: %w", route.err)
+	}
+
+	target := CanaryTargetPrimary
+	if route.selects(req) {
+		target = CanaryTargetCanary
+
+		u := *req.URL
+		u.Scheme, u.Host, u.User = route.target.Scheme, route.target.Host, route.target.User
+		if base := strings.TrimSuffix(route.target.Path, "/"); base != "" {
+			u.Path = base + "/" + strings.TrimPrefix(u.Path, "/")
+			u.RawPath = ""
+		}
+
+		routed := req.WithContext(req.Context())
+		if routed.Host == req.URL.Host {
+			routed.Host = ""
+		}
+		routed.URL = &u
+		req = routed
+	}
+
+	return req, mergeTags(tags, map[string]string{CanaryTag: target}), nil
+}
+
+// selects reports whether request must be routed to canary target.
+func (r *canaryRoute) selects(req *http.Request) bool {
+	if r.buckets <= 0 {
+		return false
+	}
+	if r.buckets >= _canaryBuckets {
+		return true
+	}
+
+	if r.keyFn != nil {
+		if key := r.keyFn(req); key != "" {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(key))
+			return int(h.Sum32()%_canaryBuckets) < r.buckets
+		}
+	}
+	return rand.Intn(_canaryBuckets) < r.buckets //nolint:gosec
+}
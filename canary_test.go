@@ -0,0 +1,127 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestWithCanary(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name + " " + r.URL.Path))
+		}))
+	}
+	primary, canary := newServer("primary"), newServer("canary")
+	defer primary.Close()
+	defer canary.Close()
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		expectedBody string
+		expectedTag  string
+	}{
+		{
+			name:         "NoCanary",
+			opts:         []Option{WithCanary(canary.URL, 0)},
+			expectedBody: "primary /users",
+			expectedTag:  CanaryTargetPrimary,
+		},
+		{
+			name:         "AllCanary",
+			opts:         []Option{WithCanary(canary.URL, 100)},
+			expectedBody: "canary /users",
+			expectedTag:  CanaryTargetCanary,
+		},
+		{
+			name:         "TargetPath",
+			opts:         []Option{WithCanary(canary.URL+"/v2/", 100)},
+			expectedBody: "canary /v2/users",
+			expectedTag:  CanaryTargetCanary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := New(tt.opts...).Get(context.Background(), primary.URL+"/users", nil)
+			if err != nil {
+				t.Fatalf("unexpected request error: %v", err)
+			}
+			if resp.String() != tt.expectedBody {
+				t.Fatalf("expected body %q, got %q instead", tt.expectedBody, resp.String())
+			}
+			if tag := resp.Tags()[CanaryTag]; tag != tt.expectedTag {
+				t.Fatalf("expected target tag %q, got %q instead", tt.expectedTag, tag)
+			}
+		})
+	}
+
+	t.Run("InvalidTarget", func(t *testing.T) {
+		if _, err := New(WithCanary("/relative", 50)).Get(context.Background(), primary.URL, nil); err == nil {
+			t.Fatal("expected error for invalid canary target")
+		}
+	})
+
+	t.Run("InvalidTargetFallback", func(t *testing.T) {
+		var ended bool
+		client := New(
+			WithCanary("/relative", 50),
+			WithStatsHandler(StatsHandlerFunc(func(_ context.Context, event StatsEvent) {
+				if _, ok := event.(RequestEnd); ok {
+					ended = true
+				}
+			})),
+			WithFallback(func(*http.Request, error) (*Response, error) {
+				return NewResponse(http.StatusOK, nil, []byte("fallback")), nil
+			}),
+		)
+
+		resp, err := client.Get(context.Background(), primary.URL, nil)
+		if err != nil {
+			t.Fatalf("expected fallback response, got error: %v", err)
+		}
+		if resp.String() != "fallback" {
+			t.Fatalf("expected body %q, got %q instead", "fallback", resp.String())
+		}
+		if !ended {
+			t.Fatal("expected RequestEnd to be handled")
+		}
+	})
+}
+
+func TestCanaryRouteSelects(t *testing.T) {
+	var settings clientSettings
+	WithCanary("https://canary.test.com", 30)(&settings)
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.test.com", nil)
+	var selected int
+	for i := 0; i < 10000; i++ {
+		if settings.canary.selects(req) {
+			selected++
+		}
+	}
+	if selected < 2500 || selected > 3500 {
+		t.Fatalf("expected about 30%% of requests to be routed to canary, got %d of 10000", selected)
+	}
+
+	WithCanaryKey(func(req *http.Request) string { return req.Header.Get("X-User-ID") })(&settings)
+	selected = 0
+	for user := 0; user < 1000; user++ {
+		req.Header.Set("X-User-ID", strconv.Itoa(user))
+		first := settings.canary.selects(req)
+		for i := 0; i < 5; i++ {
+			if settings.canary.selects(req) != first {
+				t.Fatalf("expected routing of user %d to be sticky", user)
+			}
+		}
+		if first {
+			selected++
+		}
+	}
+	if selected < 200 || selected > 400 {
+		t.Fatalf("expected about 30%% of users to be routed to canary, got %d of 1000", selected)
+	}
+}
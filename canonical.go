@@ -0,0 +1,116 @@
+package httpr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// _defaultCanonicalHeaders are headers included into canonical request, when none are selected.
+var _defaultCanonicalHeaders = []string{"Host", "Content-Type"}
+
+// Canonicalize returns stable byte representation of request, which can be signed with HMAC or
+// other bespoke signature schemes. Representation consists of newline separated lines:
+//
+//	METHOD
+//	/escaped/path
+//	sorted=query&with=RFC%203986&escaping=
+//	header-name:value (one line per selected header, sorted by name)
+//
+//	header-name;header-name (list of selected headers)
+//	hex(SHA-256(body))
+//
+// Selected headers are matched case-insensitively; "Host" and "Content-Type" are used, if none are
+// provided. Header values are trimmed, inner whitespace is collapsed and multiple values are joined
+// with commas; absent headers have empty value. Body is read from copy obtained with GetBody or
+// buffered, so request stays sendable.
+func Canonicalize(req *http.Request, headers ...string) ([]byte, error) {
+	if len(headers) == 0 {
+		headers = _defaultCanonicalHeaders
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(strings.ToUpper(composeMethod(req.Method)))
+	buf.WriteByte('\n')
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	buf.WriteString(path)
+	buf.WriteByte('\n')
+
+	buf.WriteString(canonicalQuery(req.URL.Query()))
+	buf.WriteByte('\n')
+
+	names := make([]string, 0, len(headers))
+	seen := make(map[string]struct{}, len(headers))
+	for _, name := range headers {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		buf.WriteString(name)
+		buf.WriteByte(':')
+		buf.WriteString(canonicalHeaderValue(req, name))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Join(names, ";"))
+	buf.WriteByte('\n')
+
+	h := sha256.New()
+	if err := hashBody(req, h); err != nil {
+		return nil, err
+	}
+	buf.WriteString(hex.EncodeToString(h.Sum(nil)))
+
+	return buf.Bytes(), nil
+}
+
+// canonicalQuery encodes query parameters sorted by key and value, escaping them per RFC 3986.
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for _, key := range sortedKeys(query) {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+
+		escapedKey := escapeRFC3986(key)
+		for _, value := range values {
+			pairs = append(pairs, escapedKey+"="+escapeRFC3986(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// escapeRFC3986 escapes everything except unreserved characters of RFC 3986.
+func escapeRFC3986(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func canonicalHeaderValue(req *http.Request, name string) string {
+	if name == "host" {
+		if req.Host != "" {
+			return strings.ToLower(req.Host)
+		}
+		return strings.ToLower(req.URL.Host)
+	}
+
+	values := req.Header.Values(name)
+	normalized := make([]string, len(values))
+	for i, value := range values {
+		normalized[i] = strings.Join(strings.Fields(value), " ")
+	}
+	return strings.Join(normalized, ",")
+}
@@ -0,0 +1,88 @@
+package httpr
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	testCases := []struct {
+		name     string
+		req      func() *http.Request
+		headers  []string
+		expected string
+	}{
+		{
+			name: "default headers",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "https://API.example.com/v1/users?b=2&a=x y&a=1&c=~*", nil)
+				return req
+			},
+			expected: "GET\n/v1/users\na=1&a=x%20y&b=2&c=~%2A\ncontent-type:\nhost:api.example.com\n\ncontent-type;host\n" + emptyHash,
+		},
+		{
+			name: "selected headers and body",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://api.example.com", strings.NewReader("hello"))
+				req.Header.Set("Content-Type", "text/plain")
+				req.Header.Add("X-Meta", "  a   b ")
+				req.Header.Add("X-Meta", "c")
+				return req
+			},
+			headers: []string{"X-Meta", "content-type", "Content-Type"},
+			expected: "POST\n/\n\ncontent-type:text/plain\nx-meta:a b,c\n\ncontent-type;x-meta\n" +
+				"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		{
+			name: "host override and escaped path",
+			req: func() *http.Request {
+				req, _ := http.NewRequest("", "https://api.example.com/files/a%2Fb%20c", nil)
+				req.Host = "Internal.example.com"
+				return req
+			},
+			headers:  []string{"Host"},
+			expected: "GET\n/files/a%2Fb%20c\n\nhost:internal.example.com\n\nhost\n" + emptyHash,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			canonical, err := Canonicalize(tc.req(), tc.headers...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(canonical) != tc.expected {
+				t.Errorf("expected canonical request:\n%q\ngot:\n%q instead", tc.expected, canonical)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeKeepsBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com", io.NopCloser(strings.NewReader("hello")))
+	req.Header.Add("X-Meta", " a ")
+
+	first, err := Canonicalize(req, "X-Meta")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	second, err := Canonicalize(req, "X-Meta")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("expected stable canonical request, got %q and %q", first, second)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != "hello" {
+		t.Errorf("expected request body to be preserved, got %q instead", body)
+	}
+	if req.Header.Get("X-Meta") != " a " {
+		t.Errorf("expected request header to be left untouched, got %q instead", req.Header.Get("X-Meta"))
+	}
+}
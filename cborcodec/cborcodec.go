@@ -0,0 +1,29 @@
+// Package cborcodec adds CBOR (RFC 8949) body support to httpr. It's separate module,
+// so core httpr module stays free of dependencies. Importing package registers codec for
+// httpr.MediaTypeCBOR globally, so CBOR bodies are sent with RequestBuilder.SetCodecBody
+// and decoded with Response.Decode according to response Content-Type, including
+// media types with "+cbor" suffix:
+//
+//	import _ "github.com/hickar/httpr/cborcodec"
+//
+//	req, err := httpr.NewRequest().Post(url, nil).SetCodecBody(httpr.MediaTypeCBOR, user).Build()
+//	// ...
+//	err = resp.Decode(&user)
+//
+// Struct fields without "cbor" tag are named after their "json" tag.
+package cborcodec
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/hickar/httpr"
+)
+
+// Codec marshals and unmarshals CBOR bodies.
+var Codec = httpr.Codec{
+	Marshal:   cbor.Marshal,
+	Unmarshal: cbor.Unmarshal,
+}
+
+func init() {
+	httpr.RegisterCodec(httpr.MediaTypeCBOR, Codec)
+}
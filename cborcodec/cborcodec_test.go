@@ -0,0 +1,57 @@
+package cborcodec
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/hickar/httpr"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestCBORBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if contentType := req.Header.Get("Content-Type"); contentType != httpr.MediaTypeCBOR {
+			t.Errorf("expected Content-Type %q, got %q instead", httpr.MediaTypeCBOR, contentType)
+		}
+
+		body, _ := io.ReadAll(req.Body)
+		var received map[string]any
+		if err := cbor.Unmarshal(body, &received); err != nil || received["name"] != "gopher" {
+			t.Errorf("expected fields to be named after json tags, got %v, %v", received, err)
+		}
+
+		w.Header().Set("Content-Type", "application/user+cbor")
+		_, _ = w.Write(body)
+	}))
+	defer ts.Close()
+
+	req, err := httpr.NewRequest().
+		Post(ts.URL, nil).
+		SetCodecBody(httpr.MediaTypeCBOR, user{ID: 42, Name: "gopher"}).
+		SetContext(context.Background()).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	resp, err := httpr.New().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+
+	var echoed user
+	if err = resp.Decode(&echoed); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if echoed != (user{ID: 42, Name: "gopher"}) {
+		t.Fatalf("expected echoed user, got %+v instead", echoed)
+	}
+}
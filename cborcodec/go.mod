@@ -0,0 +1,10 @@
+module github.com/hickar/httpr/cborcodec
+
+go 1.18
+
+require (
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/hickar/httpr v0.1.0
+)
+
+require github.com/x448/float16 v0.8.4 // indirect
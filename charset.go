@@ -0,0 +1,98 @@
+package httpr
+
+import (
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CharsetDecoder converts text encoded with some charset to UTF-8.
+type CharsetDecoder func(data []byte) ([]byte, error)
+
+var (
+	charsetsMu sync.RWMutex
+	charsets   = map[string]CharsetDecoder{
+		"iso-8859-1":   decodeLatin1,
+		"latin1":       decodeLatin1,
+		"windows-1251": decodeWindows1251,
+		"cp1251":       decodeWindows1251,
+	}
+)
+
+// RegisterCharsetDecoder registers decoder for provided charset name globally, replacing
+// previously registered one. Built-in decoders are "iso-8859-1" and "windows-1251".
+// Shift_JIS is registered by importing optional github.com/hickar/httpr/charset module,
+// which also adapts any golang.org/x/text encoding for registration.
+func RegisterCharsetDecoder(charset string, decoder CharsetDecoder) {
+	charsetsMu.Lock()
+	defer charsetsMu.Unlock()
+
+	charsets[strings.ToLower(charset)] = decoder
+}
+
+// decodeCharset converts body to UTF-8 according to charset declared in 'Content-Type' header.
+// Body is returned as is, if charset is not declared, is UTF-8 compatible or has no registered decoder.
+func decodeCharset(contentType string, body []byte) ([]byte, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body, nil //nolint:nilerr
+	}
+
+	charset := strings.ToLower(strings.TrimSpace(params["charset"]))
+	switch charset {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return body, nil
+	}
+
+	charsetsMu.RLock()
+	decoder, ok := charsets[charset]
+	charsetsMu.RUnlock()
+	if !ok {
+		return body, nil
+	}
+
+	return decoder(body)
+}
+
+func decodeLatin1(data []byte) ([]byte, error) {
+	decoded := make([]byte, 0, len(data))
+	for _, b := range data {
+		decoded = utf8.AppendRune(decoded, rune(b))
+	}
+
+	return decoded, nil
+}
+
+func decodeWindows1251(data []byte) ([]byte, error) {
+	decoded := make([]byte, 0, len(data))
+	for _, b := range data {
+		if b < 0x80 {
+			decoded = append(decoded, b)
+			continue
+		}
+		decoded = utf8.AppendRune(decoded, windows1251Table[b-0x80])
+	}
+
+	return decoded, nil
+}
+
+// windows1251Table maps upper half of Windows-1251 code page to Unicode code points.
+var windows1251Table = [128]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0xFFFD, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+	0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F,
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+	0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F,
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+	0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F,
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+	0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F,
+}
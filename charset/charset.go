@@ -0,0 +1,37 @@
+// Package charset adds charsets implemented by golang.org/x/text to httpr response decoding.
+// It's separate module, so core httpr module stays free of dependencies. Importing package
+// registers Shift_JIS decoder (under "shift_jis", "sjis", "ms_kanji", "csshiftjis" and "windows-31j"
+// names) globally:
+//
+//	import _ "github.com/hickar/httpr/charset"
+//
+// Other x/text encodings are registered with Register.
+package charset
+
+import (
+	"github.com/hickar/httpr"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func init() {
+	Register(japanese.ShiftJIS, "shift_jis", "sjis", "ms_kanji", "csshiftjis", "windows-31j")
+}
+
+// Register registers decoder of provided x/text encoding for charset names globally,
+// e.g. to decode EUC-KR pages:
+//
+//	charset.Register(korean.EUCKR, "euc-kr")
+func Register(enc encoding.Encoding, names ...string) {
+	decoder := Decoder(enc)
+	for _, name := range names {
+		httpr.RegisterCharsetDecoder(name, decoder)
+	}
+}
+
+// Decoder returns httpr.CharsetDecoder, which converts text encoded with provided x/text encoding to UTF-8.
+func Decoder(enc encoding.Encoding) httpr.CharsetDecoder {
+	return func(data []byte) ([]byte, error) {
+		return enc.NewDecoder().Bytes(data)
+	}
+}
@@ -0,0 +1,66 @@
+package charset
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hickar/httpr"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+)
+
+func TestCharsetDecoding(t *testing.T) {
+	Register(korean.EUCKR, "euc-kr")
+
+	tests := []struct {
+		name        string
+		contentType string
+		text        string
+		encode      func(string) (string, error)
+	}{
+		{
+			name:        "ShiftJIS",
+			contentType: "text/html; charset=Shift_JIS",
+			text:        "こんにちは、世界",
+			encode:      japanese.ShiftJIS.NewEncoder().String,
+		},
+		{
+			name:        "Windows31J",
+			contentType: "text/plain; charset=windows-31j",
+			text:        "日本語",
+			encode:      japanese.ShiftJIS.NewEncoder().String,
+		},
+		{
+			name:        "Registered",
+			contentType: "text/plain; charset=EUC-KR",
+			text:        "안녕하세요",
+			encode:      korean.EUCKR.NewEncoder().String,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.encode(tt.text)
+			if err != nil {
+				t.Fatalf("failed to encode test body: %v", err)
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write([]byte(body))
+			}))
+			defer ts.Close()
+
+			resp, err := httpr.New().Get(context.Background(), ts.URL, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if resp.String() != tt.text {
+				t.Errorf("expected body %q, got %q", tt.text, resp.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/hickar/httpr/charset
+
+go 1.18
+
+require github.com/hickar/httpr v0.1.0
+
+require golang.org/x/text v0.14.0
@@ -0,0 +1,63 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCharsetDecoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		opts        []Option
+		expected    string
+	}{
+		{
+			name:        "Latin1",
+			contentType: "text/plain; charset=ISO-8859-1",
+			body:        []byte{'c', 'a', 'f', 0xe9},
+			expected:    "café",
+		},
+		{
+			name:        "Windows1251",
+			contentType: "text/html; charset=windows-1251",
+			body:        []byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2},
+			expected:    "Привет",
+		},
+		{
+			name:        "UTF8",
+			contentType: "application/json; charset=utf-8",
+			body:        []byte("Привет"),
+			expected:    "Привет",
+		},
+		{
+			name:        "Disabled",
+			contentType: "text/plain; charset=ISO-8859-1",
+			body:        []byte{'c', 'a', 'f', 0xe9},
+			opts:        []Option{WithCharsetDecoding(false)},
+			expected:    string([]byte{'c', 'a', 'f', 0xe9}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write(tt.body)
+			}))
+			defer ts.Close()
+
+			resp, err := New(tt.opts...).Get(context.Background(), ts.URL, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if resp.String() != tt.expected {
+				t.Errorf("expected body %q, got %q", tt.expected, resp.String())
+			}
+		})
+	}
+}
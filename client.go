@@ -2,59 +2,336 @@ package httpr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"os"
+	"reflect"
+	"sync"
 	"time"
 )
 
 // Client struct is used for executing requests with client-scoped options.
+// Client settings are never modified after construction: request-scoped options
+// are applied to a per-call copy of them, therefore Client is safe for concurrent use.
 type Client struct {
 	client   *http.Client
 	settings clientSettings
+
+	ssrfOnce      sync.Once
+	ssrfTransport http.RoundTripper
+
+	hostStats *hostStatsTracker
 }
 
 type clientSettings struct {
-	rateLimiter          Limiter
-	retryCount           int
-	retryDelay           time.Duration
-	retryDelayDelta      time.Duration
-	retryConditionFn     RetryConditionFunc
-	timeout              time.Duration
-	transport            http.RoundTripper
-	cookieJar            http.CookieJar
-	decompressionEnabled bool
+	rateLimiter             Limiter
+	retryCount              int
+	retryDelay              time.Duration
+	retryDelayDelta         time.Duration
+	retryBackoffMultiplier  float64
+	retryMaxDelay           time.Duration
+	retryConditionFn        RetryConditionFunc
+	attemptRetryConditionFn AttemptRetryConditionFunc
+	statsHandler            StatsHandler
+	events                  *eventStream
+	tags                    map[string]string
+	openAPIRecorder         *OpenAPIRecorder
+	cache                   Cache
+	staleIfError            time.Duration
+	canary                  *canaryRoute
+	pprofLabels             bool
+	requestLabels           []string
+	timeout                 time.Duration
+	totalTimeout            time.Duration
+	responseHeaderTimeout   time.Duration
+	transport               http.RoundTripper
+	cookieJar               http.CookieJar
+	decompressionEnabled    bool
+	gzipSniffing            bool
+	defaultHeaders          http.Header
+	defaultQueryParams      url.Values
+	defaultCookies          []*http.Cookie
+	hostOverride            string
+	expectContinueTimeout   time.Duration
+	digestAlgorithm         DigestAlgorithm
+	decompressors           map[string]Decompressor
+	acceptEncodings         []string
+	charsetDecodingDisabled bool
+	preserveClient          bool
+	routePolicies           []routePolicy
+	responseTee             io.Writer
+	errorModels             map[int]reflect.Type
+	failOnStatusFn          func(code int) bool
+	maxResponseSize         int64
+	bodySpillThreshold      int64
+	bandwidth               *bandwidthLimits
+	transferStatsFn         TransferStatsFunc
+	clockSource             Clock
+	retryJitterFn           JitterFunc
+	rateLimitMaxWait        time.Duration
+	robotsPolicy            RobotsPolicy
+	robotsCache             *robotsCache
+	hostDelayer             *hostDelayer
+	userAgentFn             UserAgentProviderFunc
+	refererTracker          *refererTracker
+	ephemeralCookies        bool
+	ssrfProtection          bool
+	ipPreference            IPPreference
+	dialFallbackDelay       time.Duration
+	allowedHosts            []hostPattern
+	blockedHosts            []hostPattern
+	strictHeaders           bool
 
 	redirectCheckFn   func(*http.Request, []*http.Request) error
+	errorDecoderFn    ErrorDecoderFunc
+	fallbackFn        FallbackFunc
+	errorTargetFn     func() any
+	throttledFn       ThrottledFunc
 	preRequestHookFn  PreRequestHookFn
 	postRequestHookFn PostRequestHookFn
+	preAttemptHookFn  PreAttemptHookFn
+	postAttemptHookFn PostAttemptHookFn
 }
 
-// Do method executes provided requests with options. Passed request options override client-scoped ones.
+// Do method executes provided requests with options. Passed request options are applied over client settings,
+// overriding only settings they change.
 func (c *Client) Do(req *http.Request, opts ...Option) (*Response, error) {
+	settings := c.resolveSettings(req, opts)
+
+	return c.do(req, settings, true, nil)
+}
+
+// DoInto executes provided request the same way as Do, but stores result in provided response
+// instead of allocating new one. Body buffer of response is reused, if its capacity is sufficient.
+// Combined with AcquireResponse and Response.Release, this removes per-request allocations
+// on hot paths:
+//
+//	resp := httpr.AcquireResponse()
+//	defer resp.Release()
+//
+//	if err := client.DoInto(req, resp); err != nil {
+//		return err
+//	}
+//	err = resp.JSON(&result)
+//
+// On error, response may hold partial result of the last attempt.
+func (c *Client) DoInto(req *http.Request, resp *Response, opts ...Option) error {
+	if resp == nil {
+		return errors.New("response must not be nil")
+	}
+
+	settings := c.resolveSettings(req, opts)
+
+	_, err := c.do(req, settings, true, resp)
+	return err
+}
+
+// DoRaw executes provided request the same way as Do, applying hooks, retries and rate-limiting,
+// but returns underlying *http.Response with unread body. This can be used for streaming
+// response processing or proxying. Caller is responsible for closing response body.
+func (c *Client) DoRaw(req *http.Request, opts ...Option) (*http.Response, error) {
+	settings := c.resolveSettings(req, opts)
+
+	resp, err := c.do(req, settings, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.rawResp, nil
+}
+
+// DoBuilder builds request with provided RequestBuilder and executes it with options. Shortcut to Client.Do.
+func (c *Client) DoBuilder(rb *RequestBuilder, opts ...Option) (*Response, error) {
+	req, err := rb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, opts...)
+}
+
+// With creates derived client with provided options applied over current client settings.
+// Derived client shares underlying transport and thus connection pool with its parent,
+// unless another transport is provided with options.
+func (c *Client) With(opts ...Option) *Client {
+	var overrides clientSettings
+	for _, opt := range opts {
+		opt(&overrides)
+	}
+
+	settings := c.applyOptions(opts)
+	httpClient := *c.client
+	if overrides.transport != nil {
+		// New transport isn't tuned yet, so every transport-level setting, including inherited
+		// ones like SSRF protection, must be applied to it.
+		httpClient.Transport = tuneTransport(overrides.transport, settings)
+	} else {
+		httpClient.Transport = tuneTransport(httpClient.Transport, overrides)
+	}
+	if overrides.cookieJar != nil {
+		httpClient.Jar = overrides.cookieJar
+	}
+	if overrides.redirectCheckFn != nil {
+		httpClient.CheckRedirect = overrides.redirectCheckFn
+	}
+
+	return &Client{
+		client:    &httpClient,
+		settings:  settings,
+		hostStats: c.hostStats,
+	}
+}
+
+// Clone creates copy of the client, which shares underlying transport with its parent.
+// Shortcut to Client.With without options.
+func (c *Client) Clone() *Client {
+	return c.With()
+}
+
+// applyOptions returns copy of client settings with provided options applied.
+func (c *Client) applyOptions(opts []Option) clientSettings {
 	settings := c.settings
-	if len(opts) > 0 {
-		settings = newDefaultSettings()
-		for _, opt := range opts {
-			opt(&settings)
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	return settings
+}
+
+// resolveSettings returns copy of client settings with matching route policies
+// and provided request-scoped options applied, in that order.
+func (c *Client) resolveSettings(req *http.Request, opts []Option) clientSettings {
+	settings := c.settings
+	for _, policy := range c.settings.routePolicies {
+		if policy.matches(req) {
+			for _, opt := range policy.opts {
+				opt(&settings)
+			}
 		}
 	}
 
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	return settings
+}
+
+// do executes request with provided settings. If dst is not nil, it's reused for every attempt
+// instead of allocating new response.
+func (c *Client) do(req *http.Request, settings clientSettings, readBody bool, dst *Response) (result *Response, resultErr error) {
+	tags := requestTags(req, settings)
+	// Routing error is returned only after stats, events and fallback are set up, so they
+	// observe it as any other request failure.
+	var canaryErr error
+	if settings.canary != nil {
+		req, tags, canaryErr = routeCanary(req, settings, tags)
+	}
+	if len(tags) > 0 {
+		req = req.WithContext(context.WithValue(req.Context(), tagsContextKey{}, tags))
+	}
+	if settings.totalTimeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), settings.totalTimeout)
+		req = req.WithContext(ctx)
+		defer func() {
+			releaseContext(result, resultErr, readBody, cancel)
+		}()
+	}
+
+	var (
+		ctx      = req.Context()
+		start    = settings.clock().Now()
+		attempts int
+	)
+	if settings.statsHandler != nil {
+		handleStats(ctx, settings, RequestStart{Request: req, Tags: tags, Time: start})
+		defer func() {
+			handleStats(ctx, settings, RequestEnd{
+				Request:      req,
+				Tags:         tags,
+				Attempts:     attempts,
+				StatusCode:   result.StatusCode(),
+				RequestSize:  requestSize(req),
+				ResponseSize: result.bodySize(),
+				Duration:     settings.clock().Now().Sub(start),
+				Err:          resultErr,
+			})
+		}()
+	}
+
+	if settings.events != nil {
+		settings.events.emit(settings, RequestEvent{Type: EventQueued})
+		defer func() {
+			settings.events.emit(settings, RequestEvent{
+				Type: EventDone, Attempt: attempts, StatusCode: result.StatusCode(), BodySize: result.bodySize(), Err: resultErr,
+			})
+		}()
+	}
+
+	if settings.fallbackFn != nil {
+		defer func() {
+			result, resultErr = applyFallback(req, settings, result, resultErr, dst)
+		}()
+	}
+	if canaryErr != nil {
+		return nil, canaryErr
+	}
+
 	if settings.rateLimiter != nil {
-		settings.rateLimiter.Take()
+		if err := waitLimiter(ctx, settings.rateLimiter); err != nil {
+			return nil, err
+		}
+	}
+
+	applyDefaults(req, settings)
+	if settings.refererTracker != nil {
+		settings.refererTracker.apply(req)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if err := settings.preRequestHookFn(req); err != nil {
 		return nil, err
 	}
+	if err := checkHost(req.URL, settings); err != nil {
+		return nil, err
+	}
+	if settings.strictHeaders {
+		if err := validateHeaders(req); err != nil {
+			return nil, err
+		}
+	}
+
+	cached := lookupCache(req, settings, readBody)
+	if cached != nil && cached.fresh {
+		return finishResponse(req, cached.entry.response(req, dst, settings.clock().Now()), settings, tags, readBody, attempts)
+	}
+
+	httpClient := c.httpClientFor(req, settings)
+	if err := checkRobots(httpClient, req, settings); err != nil {
+		return nil, err
+	}
+
+	if settings.digestAlgorithm != "" {
+		if err := setContentDigest(req, settings.digestAlgorithm); err != nil {
+			return nil, err
+		}
+	}
 
 	var (
-		ctx        = req.Context()
 		resp       *Response
 		err        error
-		retryTime  = settings.retryDelay
+		retryTime  = capRetryDelay(settings.retryDelay, settings)
 		retryCount = settings.retryCount
+		mustRetry  bool
+		throttled  int
+		forced     int
+		waited     time.Duration
 	)
 
 	if retryCount < 1 {
@@ -62,26 +339,150 @@ func (c *Client) Do(req *http.Request, opts ...Option) (*Response, error) {
 	}
 
 	for r := 0; r < retryCount; r++ {
-		resp, err = doRequest(c.client, req, settings)
+		if attempts > 0 {
+			if err = rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = waitHostDelay(req, settings); err != nil {
+			return nil, err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		attempts++
+		if settings.preAttemptHookFn != nil {
+			if err = settings.preAttemptHookFn(ctx, req, attemptInfo(settings, attempts, retryCount-r-1, start)); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptStart := settings.clock().Now()
+		if settings.events != nil {
+			settings.events.attempt = attempts
+			settings.events.emit(settings, RequestEvent{Type: EventAttemptStarted})
+		}
+		withPprofLabels(req, settings, func(req *http.Request) {
+			if settings.timeout <= 0 {
+				resp, err = doRequest(httpClient, req, settings, readBody, dst)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(req.Context(), settings.timeout)
+			resp, err = doRequest(httpClient, req.WithContext(ctx), settings, readBody, dst)
+			releaseContext(resp, err, readBody, cancel)
+		})
 		settings.postRequestHookFn(req, resp)
+		attemptEnd := settings.clock().Now()
+		c.hostStats.record(req, resp, err, attemptEnd.Sub(attemptStart), attemptEnd)
+		handleStats(ctx, settings, ResponseReceived{
+			Request:    req,
+			Tags:       tags,
+			Attempt:    attempts,
+			StatusCode: resp.StatusCode(),
+			BodySize:   resp.bodySize(),
+			Duration:   attemptEnd.Sub(attemptStart),
+			Err:        err,
+		})
+		if settings.postAttemptHookFn != nil {
+			directive := settings.postAttemptHookFn(ctx, req, resp, err, attemptInfo(settings, attempts, retryCount-r-1, start))
+			resp, err = directive.apply(resp, err, readBody)
+
+			if directive.Action == AttemptRetry && forced < _maxForcedRetries {
+				handleStats(ctx, settings, RetryScheduled{
+					Request: req, Tags: tags, Attempt: attempts, Reason: RetryForced, StatusCode: resp.StatusCode(), Err: err,
+				})
+				settings.events.emit(settings, RequestEvent{Type: EventRetried, Reason: RetryForced, StatusCode: resp.StatusCode(), Err: err})
+				discardBody(resp, readBody)
+				forced++
+				r--
+				continue
+			}
+			if directive.Action == AttemptStop {
+				mustRetry = false
+				break
+			}
+		}
 
-		mustRetry := settings.retryConditionFn(resp, err)
-		if !mustRetry {
-			break
+		// Throttled requests are retried regardless of retry count and condition.
+		if wait, ok := throttleDelay(settings, resp, err, throttled, waited); ok {
+			if settings.throttledFn != nil {
+				settings.throttledFn(req, resp, wait)
+			}
+			handleStats(ctx, settings, RetryScheduled{
+				Request: req, Tags: tags, Attempt: attempts, Reason: RetryThrottled, Delay: wait, StatusCode: resp.StatusCode(),
+			})
+			settings.events.emit(settings, RequestEvent{Type: EventRetried, Reason: RetryThrottled, Delay: wait, StatusCode: resp.StatusCode()})
+			discardBody(resp, readBody)
+
+			if err = sleepContext(ctx, settings.clock(), wait); err != nil {
+				return nil, err
+			}
+
+			throttled++
+			waited += wait
+			r--
+			continue
 		}
-		if err == nil && r <= retryCount && !mustRetry {
-			return resp, err
+
+		if settings.attemptRetryConditionFn != nil {
+			mustRetry = settings.attemptRetryConditionFn(resp, err, attemptInfo(settings, attempts, retryCount-r-1, start))
+		} else {
+			mustRetry = settings.retryConditionFn(resp, err)
+		}
+		if !mustRetry || r == retryCount-1 {
+			break
 		}
 
-		select {
-		case <-time.After(settings.retryDelay):
-			retryTime += settings.retryDelayDelta
-		case <-ctx.Done():
-			return nil, ctx.Err()
+		delay := retryDelayWithJitter(retryTime, settings.retryJitterFn)
+		handleStats(ctx, settings, RetryScheduled{
+			Request: req, Tags: tags, Attempt: attempts, Reason: RetryByCondition, Delay: delay, StatusCode: resp.StatusCode(), Err: err,
+		})
+		settings.events.emit(settings, RequestEvent{
+			Type: EventRetried, Reason: RetryByCondition, Delay: delay, StatusCode: resp.StatusCode(), Err: err,
+		})
+		discardBody(resp, readBody)
+
+		if err = sleepContext(ctx, settings.clock(), delay); err != nil {
+			return nil, err
 		}
+		retryTime = nextRetryDelay(retryTime, settings)
+	}
+	if stale, ok := cached.staleResponse(req, resp, err, settings, dst); ok {
+		resp, err = stale, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request after %d attempt(s): %w", settings.retryCount, err)
+		if mustRetry && attempts > 1 {
+			err = withSentinel(ErrRetriesExhausted, err)
+		}
+		return nil, fmt.Errorf("failed to send request after %d attempt(s): %w", attempts, err)
+	}
+	resp = cached.store(req, resp, settings, dst)
+
+	return finishResponse(req, resp, settings, tags, readBody, attempts)
+}
+
+// finishResponse records received response and decodes its error, if response is unsuccessful.
+func finishResponse(req *http.Request, resp *Response, settings clientSettings, tags map[string]string, readBody bool, attempts int) (*Response, error) {
+	resp.tags = tags
+	if settings.openAPIRecorder != nil {
+		settings.openAPIRecorder.Record(req, resp)
+	}
+
+	if settings.refererTracker != nil && resp.IsSuccess() && resp.rawResp.Request != nil {
+		settings.refererTracker.record(resp.rawResp.Request.URL)
+	}
+
+	if readBody {
+		if err := decodeErrorTarget(settings, resp); err != nil {
+			return resp, err
+		}
+		if err := decodeResponseError(settings, resp, attempts); err != nil {
+			return resp, err
+		}
 	}
 
 	return resp, nil
@@ -192,47 +593,346 @@ func (c *Client) SetCookies(cookieOrigin *url.URL, cookies []*http.Cookie) {
 	c.client.Jar.SetCookies(cookieOrigin, cookies)
 }
 
+// Cookies returns cookies, which are sent with requests to provided URL.
+func (c *Client) Cookies(u *url.URL) []*http.Cookie {
+	if c.client.Jar == nil {
+		return nil
+	}
+
+	return c.client.Jar.Cookies(u)
+}
+
+// ClearCookies removes cookies of provided domain and its subdomains from client cookie jar.
+// Empty domain removes all cookies. Cookie jar must be *CookieJar (or implement its Clear method).
+func (c *Client) ClearCookies(domain string) error {
+	store, err := c.cookieStore()
+	if err != nil {
+		return err
+	}
+
+	store.Clear(domain)
+	return nil
+}
+
+// ExportCookies returns all cookies stored in client cookie jar. Cookie jar must be *CookieJar
+// (or implement its Export method).
+func (c *Client) ExportCookies() ([]CookieRecord, error) {
+	store, err := c.cookieStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Export(), nil
+}
+
+// ImportCookies adds provided cookies to client cookie jar, which can be any http.CookieJar.
+func (c *Client) ImportCookies(records []CookieRecord) error {
+	if c.client.Jar == nil {
+		return errors.New("client has no cookie jar")
+	}
+	if store, ok := c.client.Jar.(*CookieJar); ok {
+		return store.Import(records)
+	}
+
+	for _, record := range records {
+		if record.Expires != nil && !record.Expires.After(time.Now()) {
+			continue
+		}
+
+		u, err := url.Parse(record.URL)
+		if err != nil {
+			return fmt.Errorf("invalid cookie URL: %w", err)
+		}
+		c.client.Jar.SetCookies(u, []*http.Cookie{record.cookie()})
+	}
+
+	return nil
+}
+
+// cookieStore is cookie jar, which can list and remove stored cookies.
+type cookieStore interface {
+	Export() []CookieRecord
+	Clear(domain string)
+}
+
+func (c *Client) cookieStore() (cookieStore, error) {
+	if c.client.Jar == nil {
+		return nil, errors.New("client has no cookie jar")
+	}
+
+	store, ok := c.client.Jar.(cookieStore)
+	if !ok {
+		return nil, fmt.Errorf("cookie jar of type %T doesn't support listing cookies, use httpr.CookieJar", c.client.Jar)
+	}
+
+	return store, nil
+}
+
 // SetTransport sets transport for underlying http.Client instance.
+// It must not be called concurrently with request execution.
 func (c *Client) SetTransport(transport http.RoundTripper) {
 	c.client.Transport = transport
 }
 
-func doRequest(httpClient *http.Client, req *http.Request, settings clientSettings) (*Response, error) {
+func doRequest(httpClient *http.Client, req *http.Request, settings clientSettings, readBody bool, dst *Response) (*Response, error) {
 	var (
-		r   = new(Response)
+		r   = dst
+		buf []byte
 		err error
 	)
+	if r == nil {
+		r = new(Response)
+	} else {
+		buf = r.reset()
+	}
 
+	throttleRequestBody(req, settings.bandwidth)
+	trackRequestBody(req, settings.transferStatsFn)
 	r.rawResp, err = httpClient.Do(req)
 	if err != nil {
 		return r, err
 	}
-	defer func(body io.Closer) {
-		closeErr := body.Close()
-		if closeErr != nil {
-			err = closeErr
-		}
-	}(r.rawResp.Body)
+	settings.events.emit(settings, RequestEvent{Type: EventHeadersReceived, StatusCode: r.rawResp.StatusCode})
+	throttleResponseBody(r.rawResp, settings.bandwidth)
+	trackResponseBody(r.rawResp, settings.transferStatsFn)
+
+	if settings.responseTee != nil {
+		body := r.rawResp.Body
+		r.rawResp.Body = &multiCloseBody{Reader: io.TeeReader(body, settings.responseTee), closers: []io.Closer{body}}
+	}
 
 	reader := r.rawResp.Body
 	if settings.decompressionEnabled {
-		reader, err = wrapWithCompressionReader(r.rawResp, req)
+		reader, err = wrapWithCompressionReader(r.rawResp, settings)
 		if err != nil {
+			_ = r.rawResp.Body.Close()
 			return r, fmt.Errorf("unable to wrap response in compression reader: %w", err)
 		}
 	}
 
-	defer func(body io.Closer) {
-		closeErr := body.Close()
-		if closeErr != nil {
-			err = closeErr
+	if !readBody {
+		r.rawResp.Body = &multiCloseBody{Reader: reader, closers: []io.Closer{reader, r.rawResp.Body}}
+		return r, nil
+	}
+
+	defer func(closers ...io.Closer) {
+		for _, closer := range closers {
+			_ = closer.Close()
+		}
+	}(reader, r.rawResp.Body)
+
+	// Content-Length describes encoded body, so it's useless as size hint for decompressed one.
+	sizeHint := r.rawResp.ContentLength
+	if reader != r.rawResp.Body {
+		sizeHint = -1
+	}
+
+	var src io.Reader = reader
+	if settings.maxResponseSize > 0 {
+		if r.rawResp.ContentLength > settings.maxResponseSize {
+			return r, fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, settings.maxResponseSize)
 		}
-	}(reader)
+		src = io.LimitReader(reader, settings.maxResponseSize+1)
+	}
 
-	r.body, err = io.ReadAll(reader)
+	if settings.bodySpillThreshold > 0 {
+		var file *os.File
+		r.body, file, err = readSpilled(src, sizeHint, settings.bodySpillThreshold)
+		if file != nil {
+			r.attachSpilled(file)
+		}
+	} else if cap(buf) > 0 && int64(cap(buf)) >= sizeHint {
+		r.body, err = readInto(buf, src)
+	} else {
+		r.body, err = readSized(src, sizeHint)
+	}
 	if err != nil {
 		return r, fmt.Errorf("failed to read response bytes: %w", err)
 	}
+	if settings.maxResponseSize > 0 {
+		size := int64(len(r.body))
+		if r.bodyFile != nil {
+			size = r.spilledSize()
+		}
+		if size > settings.maxResponseSize {
+			r.body = nil
+			_ = r.Close()
+			return r, fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, settings.maxResponseSize)
+		}
+	}
+
+	if !settings.charsetDecodingDisabled && r.bodyFile == nil {
+		r.body, err = decodeCharset(r.rawResp.Header.Get("Content-Type"), r.body)
+		if err != nil {
+			return r, fmt.Errorf("failed to decode response charset: %w", withSentinel(ErrDecodeBody, err))
+		}
+	}
+	settings.events.emit(settings, RequestEvent{Type: EventBodyRead, StatusCode: r.rawResp.StatusCode, BodySize: r.bodySize()})
 
 	return r, nil
 }
+
+// applyDefaults adds client-scoped default headers, query parameters, cookies and host override
+// to request, unless request already has ones with the same keys.
+func applyDefaults(req *http.Request, settings clientSettings) {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if settings.userAgentFn != nil && req.Header.Get("User-Agent") == "" {
+		if userAgent := settings.userAgentFn(req); userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+	}
+	for key, values := range settings.defaultHeaders {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+
+	if len(settings.acceptEncodings) > 0 && req.Header.Get("Accept-Encoding") == "" {
+		if acceptEncoding := negotiatedEncodings(settings); acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+	}
+
+	if settings.expectContinueTimeout > 0 && req.Body != nil && req.Body != http.NoBody {
+		req.Header.Set("Expect", "100-continue")
+	}
+
+	if settings.hostOverride != "" && (req.Host == "" || req.Host == req.URL.Host) {
+		req.Host = settings.hostOverride
+	}
+
+	for _, cookie := range settings.defaultCookies {
+		if _, err := req.Cookie(cookie.Name); err != nil {
+			req.AddCookie(cookie)
+		}
+	}
+
+	if len(settings.defaultQueryParams) > 0 {
+		var (
+			query   = req.URL.Query()
+			missing = make(url.Values)
+		)
+		for key, values := range settings.defaultQueryParams {
+			if !query.Has(key) {
+				missing[key] = values
+			}
+		}
+
+		if encodedQuery := missing.Encode(); encodedQuery != "" {
+			if req.URL.RawQuery == "" {
+				req.URL.RawQuery = encodedQuery
+			} else {
+				req.URL.RawQuery += "&" + encodedQuery
+			}
+		}
+	}
+}
+
+// httpClientFor returns http.Client used for execution of provided request with settings.
+// If settings contain options, which are configured at http.Client level, or request URL has
+// custom scheme, its shallow copy is returned, so underlying client is never modified.
+func (c *Client) httpClientFor(req *http.Request, settings clientSettings) *http.Client {
+	ssrfProtection := settings.ssrfProtection && !c.settings.ssrfProtection
+	hostFilter := len(settings.allowedHosts) > 0 || len(settings.blockedHosts) > 0
+	_, customScheme := lookupScheme(req.URL.Scheme)
+	if settings.redirectCheckFn == nil && !settings.ephemeralCookies && !ssrfProtection && !hostFilter && !customScheme {
+		return c.client
+	}
+
+	httpClient := *c.client
+	if ssrfProtection {
+		httpClient.Transport = c.protectedTransport()
+	}
+	if customScheme {
+		httpClient.Transport = &schemeTransport{next: httpClient.Transport}
+	}
+	if settings.redirectCheckFn != nil {
+		httpClient.CheckRedirect = settings.redirectCheckFn
+	}
+	if settings.ephemeralCookies {
+		httpClient.Jar, _ = cookiejar.New(nil)
+	}
+	if hostFilter {
+		httpClient.CheckRedirect = hostCheckRedirect(settings, httpClient.CheckRedirect)
+	}
+	return &httpClient
+}
+
+// releaseContext cancels context, which response was received with. Cancellation of unread
+// response body context is deferred until body is closed.
+func releaseContext(resp *Response, err error, readBody bool, cancel context.CancelFunc) {
+	if err != nil || readBody || resp == nil || resp.rawResp == nil || resp.rawResp.Body == nil {
+		cancel()
+		return
+	}
+
+	body := resp.rawResp.Body
+	resp.rawResp.Body = &multiCloseBody{Reader: body, closers: []io.Closer{body, closerFunc(cancel)}}
+}
+
+// closerFunc is adapter, which allows usage of ordinary function as io.Closer.
+type closerFunc func()
+
+func (fn closerFunc) Close() error {
+	fn()
+	return nil
+}
+
+// rewindBody resets request body for subsequent attempt, if request allows it.
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	req.Body = body
+
+	return nil
+}
+
+// discardBody drains and closes unread body of response, so underlying connection can be reused.
+// For responses with already read body, temporary body file is removed, if body was spilled.
+func discardBody(resp *Response, read bool) {
+	if read {
+		_ = resp.Close()
+		return
+	}
+	if resp == nil || resp.rawResp == nil || resp.rawResp.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, resp.rawResp.Body)
+	_ = resp.rawResp.Body.Close()
+}
+
+// multiCloseBody is io.ReadCloser, which reads from wrapping reader and closes
+// all provided closers on Close call.
+type multiCloseBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *multiCloseBody) Close() error {
+	var firstErr error
+	for _, closer := range b.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// protectedTransport returns client transport with SSRF protection, which is used for requests
+// with request-scoped WithSSRFProtection option. It's created once, so such requests share connection pool.
+func (c *Client) protectedTransport() http.RoundTripper {
+	c.ssrfOnce.Do(func() {
+		c.ssrfTransport = tuneTransport(c.client.Transport, clientSettings{ssrfProtection: true})
+	})
+	return c.ssrfTransport
+}
@@ -1,12 +1,18 @@
 package httpr
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -121,7 +127,7 @@ func TestMethods(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := tt.clientMethodCall(&c, tt.testURL)
+			resp, err := tt.clientMethodCall(c, tt.testURL)
 			if err != nil {
 				t.Errorf("expected nil error, got instead %v", err)
 			}
@@ -190,6 +196,73 @@ func TestRequestTimeout(t *testing.T) {
 	}
 }
 
+func TestTimeoutSemantics(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay := 200 * time.Millisecond
+		if atomic.AddInt32(&hits, 1) > 1 && r.URL.Path == "/slow-once" {
+			delay = 0
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer ts.Close()
+
+	t.Run("AttemptTimeout", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		c := New(WithTimeout(50*time.Millisecond), WithRetryCount(2), WithRetryDelay(time.Millisecond))
+
+		resp, err := c.Get(context.Background(), ts.URL+"/slow-once", nil)
+		if err != nil {
+			t.Fatalf("expected timed out attempt to be retried, got error: %v", err)
+		}
+		if resp.String() != "done" || atomic.LoadInt32(&hits) != 2 {
+			t.Fatalf("expected response of second attempt, got %q after %d attempt(s)", resp.String(), atomic.LoadInt32(&hits))
+		}
+	})
+
+	t.Run("TotalTimeout", func(t *testing.T) {
+		c := New(WithTotalTimeout(100*time.Millisecond), WithRetryCount(5), WithRetryDelay(time.Millisecond))
+
+		start := time.Now()
+		_, err := c.Get(context.Background(), ts.URL+"/slow", nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded error, got: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("expected total timeout to bound retries, took %s", elapsed)
+		}
+	})
+
+	t.Run("ResponseHeaderTimeout", func(t *testing.T) {
+		c := New(WithResponseHeaderTimeout(50*time.Millisecond), WithRetryCount(1))
+
+		if _, err := c.Get(context.Background(), ts.URL+"/slow", nil); err == nil {
+			t.Fatal("expected response header timeout error")
+		}
+	})
+
+	t.Run("DoRawBody", func(t *testing.T) {
+		c := New(WithTimeout(time.Second), WithTotalTimeout(time.Second))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/slow", nil)
+		resp, err := c.DoRaw(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil || string(body) != "done" {
+			t.Fatalf("expected body to be readable after DoRaw returns, got %q, %v", body, err)
+		}
+	})
+}
+
 func TestGzipAutoUncompression(t *testing.T) {
 	ts := createTestServer()
 	defer ts.Close()
@@ -199,7 +272,7 @@ func TestGzipAutoUncompression(t *testing.T) {
 	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/gzip-compressed", nil)
 	req.Header.Set("Accept", "application/gzip")
 
-	resp, err := c.Do(req, WithAutoDecompression(true))
+	resp, err := c.Do(req, WithAutoDecompression(true), WithGzipSniffing(true))
 	if err != nil {
 		t.Fatalf("expected no error but got error '%+v'", err)
 	}
@@ -273,3 +346,413 @@ func TestPostRequestHookOption(t *testing.T) {
 		t.Error("post hook must have been called")
 	}
 }
+
+func TestDoRaw(t *testing.T) {
+	ts := createTestServer()
+	defer ts.Close()
+
+	c := New()
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/test", nil)
+	resp, err := c.DoRaw(req)
+	if err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+	defer resp.Body.Close()
+
+	var testResp struct {
+		Msg string `json:"msg"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&testResp); err != nil {
+		t.Fatalf("unexpected error during response body decoding: %v", err)
+	}
+
+	if testResp.Msg != _testMsg {
+		t.Fatalf("response string was malformed: expected '%s' but got '%s'", _testMsg, testResp.Msg)
+	}
+}
+
+func TestRequestOptionsMergeWithClientSettings(t *testing.T) {
+	var (
+		attempts       int
+		clientHeaders  []string
+		requestHeaders []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		clientHeaders = append(clientHeaders, req.Header.Get("X-Client"))
+		requestHeaders = append(requestHeaders, req.Header.Get("X-Request"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := New(WithRetryCount(2), WithDefaultHeader("X-Client", "client"))
+	if _, err := c.Get(context.Background(), ts.URL, nil, WithDefaultHeader("X-Request", "request")); err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	// Request-scoped options are applied over client settings instead of replacing them,
+	// so client retry count and default headers are kept.
+	if attempts != 2 {
+		t.Fatalf("expected client retry count to be kept, got %d attempt(s)", attempts)
+	}
+	if clientHeaders[0] != "client" || requestHeaders[0] != "request" {
+		t.Fatalf("expected both client and request default headers, got %q and %q", clientHeaders, requestHeaders)
+	}
+}
+
+func TestClientWith(t *testing.T) {
+	const headerKey = "X-Derived"
+
+	var receivedHeaders []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedHeaders = append(receivedHeaders, req.Header.Get(headerKey))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	parent := New(WithTransport(DefaultTransport()))
+	derived := parent.With(WithDefaultHeader(headerKey, "true"))
+
+	if derived.Client().Transport != parent.Client().Transport {
+		t.Fatal("derived client must share transport with its parent")
+	}
+
+	if _, err := derived.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+	if _, err := parent.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	if len(receivedHeaders) != 2 || receivedHeaders[0] != "true" || receivedHeaders[1] != "" {
+		t.Fatalf("expected header to be sent by derived client only, got %q", receivedHeaders)
+	}
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	ts := createTestServer()
+	defer ts.Close()
+
+	c := New(WithRetryCount(1))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := c.Get(context.Background(), ts.URL+"/test", nil, WithRetryCount(i), WithDefaultHeader("X-Index", fmt.Sprint(i)))
+			if err != nil {
+				t.Errorf("expected no error, but got error '%v'", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if c.settings.retryCount != 1 || c.settings.defaultHeaders != nil {
+		t.Fatal("request-scoped options must not modify client settings")
+	}
+}
+
+func TestRoutePolicy(t *testing.T) {
+	const headerKey = "X-Route"
+
+	var receivedHeaders []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedHeaders = append(receivedHeaders, req.Header.Get(headerKey))
+	}))
+	defer ts.Close()
+
+	c := New(
+		WithRoutePolicy("GET /v1/reports/*", WithDefaultHeader(headerKey, "reports")),
+		WithRoutePolicy("/v1/users/?", WithDefaultHeader(headerKey, "users")),
+	)
+
+	requests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{method: http.MethodGet, path: "/v1/reports/daily/1", expected: "reports"},
+		{method: http.MethodPost, path: "/v1/reports/daily", expected: ""},
+		{method: http.MethodDelete, path: "/v1/users/1", expected: "users"},
+		{method: http.MethodGet, path: "/v1/users/10", expected: ""},
+	}
+
+	for _, r := range requests {
+		req, _ := http.NewRequestWithContext(context.Background(), r.method, ts.URL+r.path, nil)
+		if _, err := c.Do(req); err != nil {
+			t.Fatalf("expected no error, but got error '%v'", err)
+		}
+	}
+
+	for i, r := range requests {
+		if receivedHeaders[i] != r.expected {
+			t.Errorf("%s %s: expected route header %q, got %q", r.method, r.path, r.expected, receivedHeaders[i])
+		}
+	}
+}
+
+func TestRequestRetryRewindsBody(t *testing.T) {
+	var receivedBodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		receivedBodies = append(receivedBodies, string(body))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := New(
+		WithRetryCount(2),
+		WithRetryCondition(func(resp *Response, _ error) bool {
+			return resp.StatusCode() == http.StatusServiceUnavailable
+		}),
+	)
+
+	req, err := NewRequest().Post(ts.URL, nil).SetJSONBody(map[string]any{"id": 1}).Build()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err = c.Do(req); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(receivedBodies) != 2 || receivedBodies[0] != receivedBodies[1] {
+		t.Fatalf("expected the same body to be sent on each attempt, got %q", receivedBodies)
+	}
+}
+
+func TestRequestBuilderSend(t *testing.T) {
+	ts := createTestServer()
+	defer ts.Close()
+
+	c := New()
+
+	resp, err := NewRequest().
+		Get(ts.URL+"/test", nil).
+		Send(context.Background(), c)
+	if err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, resp.StatusCode())
+	}
+
+	if _, err = c.DoBuilder(NewRequest().SetURL("invalid")); err == nil {
+		t.Fatal("expected build error to be returned")
+	}
+}
+
+func TestDefaultQueryParams(t *testing.T) {
+	var receivedQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedQuery = req.URL.RawQuery
+	}))
+	defer ts.Close()
+
+	c := New(
+		WithDefaultQueryParam("api_key", "secret"),
+		WithDefaultQueryParam("version", "1"),
+	)
+
+	if _, err := c.Get(context.Background(), ts.URL+"/?version=2&page=1", nil); err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	expectedQuery := "version=2&page=1&api_key=secret"
+	if receivedQuery != expectedQuery {
+		t.Fatalf("expected query %q, got %q", expectedQuery, receivedQuery)
+	}
+}
+
+func TestDefaultCookies(t *testing.T) {
+	var receivedCookies []*http.Cookie
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedCookies = req.Cookies()
+	}))
+	defer ts.Close()
+
+	c := New(WithDefaultCookies([]*http.Cookie{
+		{Name: "consent", Value: "yes"},
+		{Name: "session", Value: "default"},
+	}))
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "explicit"})
+
+	if _, err := c.Do(req); err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	received := make(map[string]string)
+	for _, cookie := range receivedCookies {
+		received[cookie.Name] = cookie.Value
+	}
+
+	if len(received) != 2 || received["consent"] != "yes" || received["session"] != "explicit" {
+		t.Fatalf("unexpected cookies received: %v", received)
+	}
+}
+
+func TestHostOverride(t *testing.T) {
+	var receivedHosts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedHosts = append(receivedHosts, req.Host)
+	}))
+	defer ts.Close()
+
+	c := New(WithHostOverride("example.com"))
+
+	if _, err := c.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+	if _, err := NewRequest().Get(ts.URL, nil).SetHostHeader("api.example.com").Send(context.Background(), c); err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	if len(receivedHosts) != 2 || receivedHosts[0] != "example.com" || receivedHosts[1] != "api.example.com" {
+		t.Fatalf("unexpected hosts received: %q", receivedHosts)
+	}
+}
+
+func TestExpectContinue(t *testing.T) {
+	var receivedExpect string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedExpect = req.Header.Get("Expect")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	c := New(WithTransport(DefaultTransport()), WithExpectContinue(time.Second))
+
+	tr, ok := c.Client().Transport.(*http.Transport)
+	if !ok || tr.ExpectContinueTimeout != time.Second {
+		t.Fatal("expected transport to be configured with expect continue timeout")
+	}
+
+	resp, err := c.Post(context.Background(), ts.URL, "large body")
+	if err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	if resp.StatusCode() != http.StatusUnauthorized || receivedExpect != "100-continue" {
+		t.Fatalf("unexpected response status %d with received Expect header %q", resp.StatusCode(), receivedExpect)
+	}
+}
+
+func TestContentDigest(t *testing.T) {
+	var receivedDigests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		receivedDigests = append(receivedDigests, string(body)+"|"+req.Header.Get("Content-Digest")+req.Header.Get("Content-MD5"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		algo     DigestAlgorithm
+		expected string
+	}{
+		{
+			name:     "SHA256",
+			algo:     DigestSHA256,
+			expected: "content|sha-256=:7XACtDnprIRfIjV9giusFERzD722AW0+yUMil7nsn3M=:",
+		},
+		{
+			name:     "MD5",
+			algo:     DigestMD5,
+			expected: "content|mgNkuembtIDdJeHwKEyFVQ==",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receivedDigests = nil
+
+			c := New(
+				WithContentDigest(tt.algo),
+				WithRetryCount(2),
+				WithRetryCondition(func(resp *Response, _ error) bool {
+					return resp.StatusCode() == http.StatusServiceUnavailable
+				}),
+			)
+
+			body := io.MultiReader(strings.NewReader("content"))
+			if _, err := c.Post(context.Background(), ts.URL, body); err != nil {
+				t.Fatalf("expected no error, but got error '%v'", err)
+			}
+
+			if len(receivedDigests) != 2 || receivedDigests[0] != tt.expected || receivedDigests[1] != tt.expected {
+				t.Fatalf("expected body with digest %q on each attempt, got %q", tt.expected, receivedDigests)
+			}
+		})
+	}
+}
+
+func TestRedirectOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/first":
+			http.Redirect(w, req, "/second", http.StatusFound)
+		case "/second":
+			http.Redirect(w, req, "/final", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer ts.Close()
+
+	c := New(WithMaxRedirects(2))
+
+	resp, err := c.Get(context.Background(), ts.URL+"/first", nil)
+	if err != nil || resp.StatusCode() != http.StatusOK {
+		t.Fatalf("expected redirects to be followed, got status %d and error %v", resp.StatusCode(), err)
+	}
+
+	_, err = c.Get(context.Background(), ts.URL+"/first", nil, WithMaxRedirects(1))
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("expected ErrTooManyRedirects, got %v", err)
+	}
+
+	resp, err = c.Get(context.Background(), ts.URL+"/first", nil, WithNoRedirects())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if location, _ := resp.Location(); resp.StatusCode() != http.StatusFound || location.Path != "/second" {
+		t.Fatalf("expected redirect response to be returned, got status %d", resp.StatusCode())
+	}
+}
+
+func TestResponseTee(t *testing.T) {
+	ts := createTestServer()
+	defer ts.Close()
+
+	var tee bytes.Buffer
+	c := New(WithResponseTee(&tee))
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/gzip-compressed", nil)
+	resp, err := c.Do(req, WithAutoDecompression(true), WithGzipSniffing(true))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.Contains(resp.String(), _testMsg) {
+		t.Fatalf("expected decompressed body to contain %q, got %q", _testMsg, resp.String())
+	}
+
+	gr, err := gzip.NewReader(&tee)
+	if err != nil {
+		t.Fatalf("expected teed body to be gzip-compressed, got error %v", err)
+	}
+	teed, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(teed) != resp.String() {
+		t.Errorf("expected teed body %q, got %q", resp.String(), teed)
+	}
+}
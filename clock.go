@@ -0,0 +1,92 @@
+package httpr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock abstracts time, so retries, backoff, rate limiting and per-host delays can be tested
+// deterministically without sleeping real time, see WithClock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// SystemClock returns Clock backed by time package. It's used by default.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+// FakeClock is Clock for tests, which never blocks: every wait instantly advances its time
+// by requested duration. Total waited time can be asserted with Now. FakeClock is safe
+// for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock creates FakeClock, which starts at provided time.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After advances fake time by d and returns channel, which has already received new time.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(d)
+	return ch
+}
+
+// Sleep advances fake time by d without blocking.
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Advance moves fake time forward by d and returns new time.
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+	return c.now
+}
+
+// clock returns clock set with WithClock or system clock.
+func (settings clientSettings) clock() Clock {
+	if settings.clockSource == nil {
+		return systemClock{}
+	}
+	return settings.clockSource
+}
+
+// sleepContext blocks for provided duration according to clock or until context is done.
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	select {
+	case <-clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithClock(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/throttled":
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		path     string
+		opts     []Option
+		requests int
+		waited   time.Duration
+	}{
+		{
+			name: "retry delay",
+			path: "/unavailable",
+			opts: []Option{
+				WithRetryCount(3),
+				WithRetryDelay(time.Minute),
+				WithRetryCondition(func(resp *Response, err error) bool { return err != nil || resp.IsServerError() }),
+			},
+			requests: 1,
+			waited:   2 * time.Minute,
+		},
+		{
+			name:     "rate limit wait",
+			path:     "/throttled",
+			opts:     []Option{WithAutoRateLimitRetry(time.Hour)},
+			requests: 1,
+			waited:   30 * time.Second,
+		},
+		{
+			name:     "per-host delay",
+			path:     "/",
+			opts:     []Option{WithPerHostDelay(10*time.Second, nil)},
+			requests: 3,
+			waited:   20 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := NewFakeClock(start)
+			client := New(append(tc.opts, WithClock(clock))...)
+
+			began := time.Now()
+			for i := 0; i < tc.requests; i++ {
+				if _, err := client.Get(context.Background(), ts.URL+tc.path, nil); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+
+			if elapsed := time.Since(began); elapsed > 5*time.Second {
+				t.Errorf("expected fake clock not to block, took %s", elapsed)
+			}
+			if waited := clock.Now().Sub(start); waited != tc.waited {
+				t.Errorf("expected fake clock to advance by %s, got %s instead", tc.waited, waited)
+			}
+		})
+	}
+}
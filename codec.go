@@ -0,0 +1,119 @@
+package httpr
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Media types of binary body formats, which codecs can be registered for with RegisterCodec.
+const (
+	MediaTypeProtobuf = "application/x-protobuf"
+	MediaTypeMsgPack  = "application/msgpack"
+	MediaTypeCBOR     = "application/cbor"
+)
+
+// Format is media type of body format, which response can be decoded as with Response.DecodeAs.
+// Any media type, which has codec registered with RegisterCodec, can be used as Format.
+type Format string
+
+// Formats with built-in codecs.
+const (
+	FormatJSON Format = "application/json"
+	FormatXML  Format = "application/xml"
+	FormatCSV  Format = "text/csv"
+)
+
+// Codec marshals and unmarshals bodies of some media type.
+type Codec struct {
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"application/json": {Marshal: json.Marshal, Unmarshal: json.Unmarshal},
+		"application/xml":  {Marshal: xml.Marshal, Unmarshal: xml.Unmarshal},
+		"text/xml":         {Marshal: xml.Marshal, Unmarshal: xml.Unmarshal},
+		"text/csv":         {Marshal: marshalCSV, Unmarshal: unmarshalCSV},
+	}
+)
+
+// RegisterCodec registers codec for provided media type globally, replacing previously
+// registered one. Built-in codecs are "application/json", "application/xml", "text/xml" and "text/csv".
+// Protocol Buffers, MessagePack and CBOR codecs are registered by importing optional
+// github.com/hickar/httpr/protocodec, github.com/hickar/httpr/msgpackcodec and
+// github.com/hickar/httpr/cborcodec modules respectively.
+func RegisterCodec(mediaType string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[strings.ToLower(mediaType)] = codec
+}
+
+// lookupCodec returns codec registered for provided media type or Content-Type header value.
+// Media types with structured syntax suffix like "application/problem+json" fall back
+// to codec of suffix format.
+func lookupCodec(contentType string) (Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Codec{}, fmt.Errorf("%w: %q", ErrCodecNotRegistered, contentType)
+	}
+
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	if codec, ok := codecs[mediaType]; ok {
+		return codec, nil
+	}
+	if idx := strings.LastIndexByte(mediaType, '+'); idx >= 0 {
+		if codec, ok := codecs["application/"+mediaType[idx+1:]]; ok {
+			return codec, nil
+		}
+	}
+
+	return Codec{}, fmt.Errorf("%w: %q", ErrCodecNotRegistered, mediaType)
+}
+
+// marshalBody marshals value with codec registered for provided media type.
+func marshalBody(mediaType string, v any) ([]byte, error) {
+	codec, err := lookupCodec(mediaType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	body, err := codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s body: %w", mediaType, err)
+	}
+
+	return body, nil
+}
+
+// isRawBody reports whether body is passed to request as is, without marshalling with codec
+// registered for Content-Type. Maps are encoded as JSON by request itself, so they are raw
+// only unless Content-Type of other format is set.
+func isRawBody(body any, contentType string) bool {
+	switch body.(type) {
+	case nil, string, []byte, io.Reader, bodyProvider:
+		return true
+	case map[string]any:
+		return contentType == "" || isJSONContentType(contentType)
+	default:
+		return false
+	}
+}
+
+// isJSONContentType reports whether Content-Type header value is JSON media type.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
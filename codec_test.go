@@ -0,0 +1,188 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testProtoMessage imitates generated Protocol Buffers message.
+type testProtoMessage struct {
+	Name string
+}
+
+func registerTestCodec(t *testing.T, mediaType string, codec Codec) {
+	t.Helper()
+
+	codecsMu.RLock()
+	previous, ok := codecs[mediaType]
+	codecsMu.RUnlock()
+
+	RegisterCodec(mediaType, codec)
+	t.Cleanup(func() {
+		codecsMu.Lock()
+		defer codecsMu.Unlock()
+
+		if ok {
+			codecs[mediaType] = previous
+		} else {
+			delete(codecs, mediaType)
+		}
+	})
+}
+
+func TestProtoBody(t *testing.T) {
+	if _, err := NewRequest().SetProtoBody(&testProtoMessage{}).Build(); !errors.Is(err, ErrCodecNotRegistered) {
+		t.Fatalf("expected ErrCodecNotRegistered, got %v", err)
+	}
+
+	registerTestCodec(t, MediaTypeProtobuf, Codec{
+		Marshal: func(v any) ([]byte, error) {
+			msg, ok := v.(*testProtoMessage)
+			if !ok {
+				return nil, fmt.Errorf("unexpected message type %T", v)
+			}
+			return []byte(msg.Name), nil
+		},
+		Unmarshal: func(data []byte, v any) error {
+			msg, ok := v.(*testProtoMessage)
+			if !ok {
+				return fmt.Errorf("unexpected message type %T", v)
+			}
+			msg.Name = string(data)
+			return nil
+		},
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if contentType := req.Header.Get("Content-Type"); contentType != MediaTypeProtobuf {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", MediaTypeProtobuf)
+		_, _ = w.Write(append(body, " reply"...))
+	}))
+	defer ts.Close()
+
+	resp, err := NewRequest().
+		Post(ts.URL, nil).
+		SetProtoBody(&testProtoMessage{Name: "request"}).
+		Send(context.Background(), New())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d instead", http.StatusOK, resp.StatusCode())
+	}
+
+	var reply testProtoMessage
+	if err = resp.Proto(&reply); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reply.Name != "request reply" {
+		t.Errorf("expected message name %q, got %q instead", "request reply", reply.Name)
+	}
+}
+
+func TestCodecBody(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	// Fake binary codec, which prefixes JSON with format marker.
+	marker := []byte{0xC1}
+	registerTestCodec(t, MediaTypeMsgPack, Codec{
+		Marshal: func(v any) ([]byte, error) {
+			data, err := json.Marshal(v)
+			return append(append([]byte(nil), marker...), data...), err
+		},
+		Unmarshal: func(data []byte, v any) error {
+			if !bytes.HasPrefix(data, marker) {
+				return errors.New("missing format marker")
+			}
+			return json.Unmarshal(data[len(marker):], v)
+		},
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", req.Header.Get("Content-Type"))
+		_, _ = w.Write(body)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		builder     func(rb *RequestBuilder) *RequestBuilder
+	}{
+		{
+			name:        "SetBody_MsgPack",
+			contentType: MediaTypeMsgPack,
+			builder: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.SetHeader("content-type", MediaTypeMsgPack).SetBody(payload{Name: _testMsg})
+			},
+		},
+		{
+			name:        "SetBody_MsgPackMap",
+			contentType: MediaTypeMsgPack,
+			builder: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.SetHeader("Content-Type", MediaTypeMsgPack).SetBody(map[string]any{"name": _testMsg})
+			},
+		},
+		{
+			name:        "SetCodecBody_MsgPack",
+			contentType: MediaTypeMsgPack,
+			builder: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.SetCodecBody(MediaTypeMsgPack, payload{Name: _testMsg})
+			},
+		},
+		{
+			name:        "SetBody_JSONSuffix",
+			contentType: "application/vnd.api+json; charset=utf-8",
+			builder: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.SetHeader("Content-Type", "application/vnd.api+json; charset=utf-8").SetBody(&payload{Name: _testMsg})
+			},
+		},
+		{
+			name:        "SetBody_XML",
+			contentType: "application/xml",
+			builder: func(rb *RequestBuilder) *RequestBuilder {
+				return rb.SetHeader("Content-Type", "application/xml").SetBody(payload{Name: _testMsg})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.builder(NewRequest().SetMethod(http.MethodPost).SetURL(ts.URL)).Send(context.Background(), New())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if contentType := resp.Header().Get("Content-Type"); contentType != tt.contentType {
+				t.Fatalf("expected Content-Type %q, got %q instead", tt.contentType, contentType)
+			}
+
+			var decoded payload
+			if err = resp.Decode(&decoded); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if decoded.Name != _testMsg {
+				t.Errorf("expected name %q, got %q instead", _testMsg, decoded.Name)
+			}
+		})
+	}
+
+	_, err := NewRequest().SetURL(ts.URL).SetHeader("Content-Type", MediaTypeCBOR).SetBody(payload{}).Build()
+	if !errors.Is(err, ErrCodecNotRegistered) {
+		t.Errorf("expected ErrCodecNotRegistered, got %v", err)
+	}
+}
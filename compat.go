@@ -0,0 +1,166 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+)
+
+// ChainRequest is a chained request object bound to Client, which mimics request API
+// of libraries like resty. It can be used to migrate existing call sites incrementally:
+//
+//	resp, err := client.R().
+//		SetHeader("Accept", "application/json").
+//		SetQueryParam("page", "1").
+//		SetResult(&result).
+//		Get("https://mysite.com/items")
+type ChainRequest struct {
+	client  *Client
+	builder *RequestBuilder
+	opts    []Option
+	result  any
+	errResp any
+}
+
+// R creates new ChainRequest, which is executed with current client.
+func (c *Client) R() *ChainRequest {
+	return &ChainRequest{
+		client:  c,
+		builder: NewRequest(),
+	}
+}
+
+// SetContext sets context for current request.
+func (r *ChainRequest) SetContext(ctx context.Context) *ChainRequest {
+	r.builder.SetContext(ctx)
+	return r
+}
+
+// SetHeader sets header with provided key and value.
+func (r *ChainRequest) SetHeader(key, value string) *ChainRequest {
+	r.builder.SetHeader(key, value)
+	return r
+}
+
+// SetHeaders sets headers for each key/value pair in provided map.
+func (r *ChainRequest) SetHeaders(headers map[string]string) *ChainRequest {
+	r.builder.SetHeaders(headers)
+	return r
+}
+
+// SetQueryParam sets query parameter with following key and value.
+func (r *ChainRequest) SetQueryParam(key, value string) *ChainRequest {
+	r.builder.SetQueryParam(key, value)
+	return r
+}
+
+// SetQueryParams sets multiple query parameters.
+func (r *ChainRequest) SetQueryParams(params map[string]string) *ChainRequest {
+	r.builder.SetQueryParams(params)
+	return r
+}
+
+// SetQueryString sets query string parameters by passing raw string.
+func (r *ChainRequest) SetQueryString(query string) *ChainRequest {
+	r.builder.SetQueryString(query)
+	return r
+}
+
+// SetBody sets request body. Supported types are the same as for RequestBuilder.SetBody.
+func (r *ChainRequest) SetBody(body any) *ChainRequest {
+	r.builder.SetBody(body)
+	return r
+}
+
+// SetCookies sets cookies for current request.
+func (r *ChainRequest) SetCookies(cookies []*http.Cookie) *ChainRequest {
+	r.builder.SetCookies(cookies)
+	return r
+}
+
+// SetBasicAuth sets basic HTTP authentication credentials.
+func (r *ChainRequest) SetBasicAuth(user, pass string) *ChainRequest {
+	r.builder.SetBasicAuth(user, pass)
+	return r
+}
+
+// SetAuthToken sets bearer token to 'Authorization' header.
+func (r *ChainRequest) SetAuthToken(token string) *ChainRequest {
+	r.builder.SetBearerToken(token)
+	return r
+}
+
+// SetResult sets value, in which response JSON body is unmarshalled in case of
+// successful (2xx) response.
+func (r *ChainRequest) SetResult(result any) *ChainRequest {
+	r.result = result
+	return r
+}
+
+// SetError sets value, in which response JSON body is unmarshalled in case of
+// unsuccessful (non 2xx) response.
+func (r *ChainRequest) SetError(errResp any) *ChainRequest {
+	r.errResp = errResp
+	return r
+}
+
+// SetOptions sets request-scoped options, which are passed to Client.Do.
+func (r *ChainRequest) SetOptions(opts ...Option) *ChainRequest {
+	r.opts = append(r.opts, opts...)
+	return r
+}
+
+// Get executes "get" HTTP request.
+func (r *ChainRequest) Get(requestURL string) (*Response, error) {
+	return r.Execute(http.MethodGet, requestURL)
+}
+
+// Post executes "post" HTTP request.
+func (r *ChainRequest) Post(requestURL string) (*Response, error) {
+	return r.Execute(http.MethodPost, requestURL)
+}
+
+// Put executes "put" HTTP request.
+func (r *ChainRequest) Put(requestURL string) (*Response, error) {
+	return r.Execute(http.MethodPut, requestURL)
+}
+
+// Patch executes "patch" HTTP request.
+func (r *ChainRequest) Patch(requestURL string) (*Response, error) {
+	return r.Execute(http.MethodPatch, requestURL)
+}
+
+// Delete executes "delete" HTTP request.
+func (r *ChainRequest) Delete(requestURL string) (*Response, error) {
+	return r.Execute(http.MethodDelete, requestURL)
+}
+
+// Head executes "head" HTTP request.
+func (r *ChainRequest) Head(requestURL string) (*Response, error) {
+	return r.Execute(http.MethodHead, requestURL)
+}
+
+// Options executes "options" HTTP request.
+func (r *ChainRequest) Options(requestURL string) (*Response, error) {
+	return r.Execute(http.MethodOptions, requestURL)
+}
+
+// Execute builds and executes request with provided method and URL.
+func (r *ChainRequest) Execute(method, requestURL string) (*Response, error) {
+	resp, err := r.client.DoBuilder(r.builder.SetMethod(method).SetURL(requestURL), r.opts...)
+	if err != nil {
+		return resp, err
+	}
+
+	target := r.errResp
+	if Is2xx(resp.StatusCode()) {
+		target = r.result
+	}
+
+	if target != nil && len(resp.Bytes()) > 0 {
+		if err = resp.JSON(target); err != nil {
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
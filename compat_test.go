@@ -0,0 +1,33 @@
+package httpr
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestChainRequest(t *testing.T) {
+	ts := createTestServer()
+	defer ts.Close()
+
+	c := New()
+
+	var result struct {
+		Msg string `json:"msg"`
+	}
+
+	resp, err := c.R().
+		SetHeader("Accept", "application/json").
+		SetResult(&result).
+		Get(ts.URL + "/test")
+	if err != nil {
+		t.Fatalf("expected no error, but got error '%v'", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, resp.StatusCode())
+	}
+
+	if result.Msg != _testMsg {
+		t.Fatalf("expected result message '%s', but got '%s'", _testMsg, result.Msg)
+	}
+}
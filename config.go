@@ -0,0 +1,269 @@
+package httpr
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEnvPrefix is prefix of environment variables used by NewFromEnv, when empty prefix is passed.
+const DefaultEnvPrefix = "HTTPR"
+
+// Config is a declarative client configuration, which can be stored in files.
+// LoadConfig reads configuration in JSON format, YAML files are read with optional
+// github.com/hickar/httpr/yamlconfig module.
+//
+// Retry delays start with RetryDelay and grow after each unsuccessful attempt: delay is multiplied
+// by RetryBackoff (if greater than 1), then RetryDelayDelta is added, and result is limited
+// with RetryMaxDelay (if set).
+type Config struct {
+	Timeout              Duration          `json:"timeout" yaml:"timeout"`
+	RetryCount           int               `json:"retryCount" yaml:"retryCount"`
+	RetryDelay           Duration          `json:"retryDelay" yaml:"retryDelay"`
+	RetryDelayDelta      Duration          `json:"retryDelayDelta" yaml:"retryDelayDelta"`
+	RetryBackoff         float64           `json:"retryBackoff" yaml:"retryBackoff"`
+	RetryMaxDelay        Duration          `json:"retryMaxDelay" yaml:"retryMaxDelay"`
+	Proxy                string            `json:"proxy" yaml:"proxy"`
+	TLS                  TLSConfig         `json:"tls" yaml:"tls"`
+	Auth                 AuthConfig        `json:"auth" yaml:"auth"`
+	DefaultHeaders       map[string]string `json:"defaultHeaders" yaml:"defaultHeaders"`
+	DecompressionEnabled bool              `json:"decompressionEnabled" yaml:"decompressionEnabled"`
+}
+
+// TLSConfig describes TLS settings of client transport.
+type TLSConfig struct {
+	CAFile             string `json:"caFile" yaml:"caFile"`
+	CertFile           string `json:"certFile" yaml:"certFile"`
+	KeyFile            string `json:"keyFile" yaml:"keyFile"`
+	ServerName         string `json:"serverName" yaml:"serverName"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+}
+
+// AuthConfig describes authentication credentials added to each request.
+// If Token is set, bearer authentication is used, otherwise basic authentication
+// is used if Username is set.
+type AuthConfig struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	Token    string `json:"token" yaml:"token"`
+}
+
+// Duration is time.Duration, which is represented in configuration files
+// as string accepted by time.ParseDuration, e.g. "1m30s".
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler interface.
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler interface.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadConfig reads configuration from JSON file located at path.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// FromConfig creates new client with settings described by provided configuration.
+// Passed options are applied after configuration ones, so they take precedence.
+func FromConfig(cfg Config, opts ...Option) (*Client, error) {
+	tr := DefaultTransport()
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := cfg.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		tr.TLSClientConfig = tlsConfig
+	}
+
+	var transport http.RoundTripper = tr
+	switch {
+	case cfg.Auth.Token != "":
+		transport = NewBearerAuthTransport(transport, cfg.Auth.Token)
+	case cfg.Auth.Username != "":
+		transport = NewBasicAuthTransport(transport, cfg.Auth.Username, cfg.Auth.Password)
+	}
+
+	cfgOpts := []Option{
+		WithTransport(transport),
+		WithTimeout(time.Duration(cfg.Timeout)),
+		WithRetryCount(cfg.RetryCount),
+		WithRetryDelay(time.Duration(cfg.RetryDelay)),
+		WithRetryDelayDelta(time.Duration(cfg.RetryDelayDelta)),
+		WithRetryBackoff(cfg.RetryBackoff),
+		WithRetryMaxDelay(time.Duration(cfg.RetryMaxDelay)),
+		WithAutoDecompression(cfg.DecompressionEnabled),
+	}
+	for key, value := range cfg.DefaultHeaders {
+		cfgOpts = append(cfgOpts, WithDefaultHeader(key, value))
+	}
+
+	return New(append(cfgOpts, opts...)...), nil
+}
+
+// ConfigFromEnv reads configuration from environment variables with provided prefix.
+// Following variables are recognized (shown with default "HTTPR" prefix):
+//
+//	HTTPR_TIMEOUT, HTTPR_RETRY_COUNT, HTTPR_RETRY_DELAY, HTTPR_RETRY_DELAY_DELTA,
+//	HTTPR_RETRY_BACKOFF, HTTPR_RETRY_MAX_DELAY,
+//	HTTPR_PROXY, HTTPR_CA_FILE, HTTPR_CERT_FILE, HTTPR_KEY_FILE, HTTPR_SERVER_NAME,
+//	HTTPR_INSECURE_SKIP_VERIFY, HTTPR_USERNAME, HTTPR_PASSWORD, HTTPR_TOKEN,
+//	HTTPR_DECOMPRESSION_ENABLED.
+//
+// Durations must be in format accepted by time.ParseDuration, booleans - by strconv.ParseBool,
+// numbers - by strconv.ParseFloat.
+func ConfigFromEnv(prefix string) (Config, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+
+	var (
+		cfg    Config
+		errs   []string
+		lookup = func(name string) (string, bool) {
+			return os.LookupEnv(prefix + "_" + name)
+		}
+	)
+
+	parseDuration := func(name string, dst *Duration) {
+		if value, ok := lookup(name); ok {
+			if err := dst.UnmarshalText([]byte(value)); err != nil {
+				errs = append(errs, fmt.Sprintf("%s_%s: %v", prefix, name, err))
+			}
+		}
+	}
+	parseBool := func(name string, dst *bool) {
+		if value, ok := lookup(name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s_%s: %v", prefix, name, err))
+			}
+			*dst = parsed
+		}
+	}
+	parseString := func(name string, dst *string) {
+		if value, ok := lookup(name); ok {
+			*dst = value
+		}
+	}
+
+	parseDuration("TIMEOUT", &cfg.Timeout)
+	parseDuration("RETRY_DELAY", &cfg.RetryDelay)
+	parseDuration("RETRY_DELAY_DELTA", &cfg.RetryDelayDelta)
+	parseDuration("RETRY_MAX_DELAY", &cfg.RetryMaxDelay)
+	parseString("PROXY", &cfg.Proxy)
+	parseString("CA_FILE", &cfg.TLS.CAFile)
+	parseString("CERT_FILE", &cfg.TLS.CertFile)
+	parseString("KEY_FILE", &cfg.TLS.KeyFile)
+	parseString("SERVER_NAME", &cfg.TLS.ServerName)
+	parseBool("INSECURE_SKIP_VERIFY", &cfg.TLS.InsecureSkipVerify)
+	parseString("USERNAME", &cfg.Auth.Username)
+	parseString("PASSWORD", &cfg.Auth.Password)
+	parseString("TOKEN", &cfg.Auth.Token)
+	parseBool("DECOMPRESSION_ENABLED", &cfg.DecompressionEnabled)
+
+	if value, ok := lookup("RETRY_COUNT"); ok {
+		retryCount, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s_RETRY_COUNT: %v", prefix, err))
+		}
+		cfg.RetryCount = retryCount
+	}
+
+	if value, ok := lookup("RETRY_BACKOFF"); ok {
+		backoff, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s_RETRY_BACKOFF: %v", prefix, err))
+		}
+		cfg.RetryBackoff = backoff
+	}
+
+	if len(errs) > 0 {
+		return cfg, fmt.Errorf("invalid environment configuration: %s", strings.Join(errs, "; "))
+	}
+
+	return cfg, nil
+}
+
+// NewFromEnv creates new client configured with environment variables with provided prefix.
+// See ConfigFromEnv for list of recognized variables.
+func NewFromEnv(prefix string, opts ...Option) (*Client, error) {
+	cfg, err := ConfigFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromConfig(cfg, opts...)
+}
+
+func (c TLSConfig) build() (*tls.Config, error) {
+	if c == (TLSConfig{}) {
+		return nil, nil //nolint:nilnil
+	}
+
+	//nolint:gosec
+	tlsConfig := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		caCert, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("failed to parse CA certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,110 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configContent := `{
+		"timeout": "30s",
+		"retryCount": 3,
+		"retryDelay": "10ms",
+		"retryBackoff": 2.5,
+		"retryMaxDelay": "1s",
+		"auth": {"token": "secret"},
+		"defaultHeaders": {"X-Service": "test"}
+	}`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if time.Duration(cfg.Timeout) != 30*time.Second {
+		t.Errorf("expected timeout %v, got %v", 30*time.Second, time.Duration(cfg.Timeout))
+	}
+	if cfg.RetryCount != 3 {
+		t.Errorf("expected retry count 3, got %d", cfg.RetryCount)
+	}
+	if cfg.RetryBackoff != 2.5 || time.Duration(cfg.RetryMaxDelay) != time.Second {
+		t.Errorf("expected retry backoff 2.5 with max delay 1s, got %v and %v", cfg.RetryBackoff, time.Duration(cfg.RetryMaxDelay))
+	}
+
+	var authHeader, serviceHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authHeader = req.Header.Get("Authorization")
+		serviceHeader = req.Header.Get("X-Service")
+	}))
+	defer ts.Close()
+
+	c, err := FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err = c.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if authHeader != "Bearer secret" {
+		t.Errorf("expected authorization header %q, got %q", "Bearer secret", authHeader)
+	}
+	if serviceHeader != "test" {
+		t.Errorf("expected default header value %q, got %q", "test", serviceHeader)
+	}
+}
+
+func TestFromConfigErrors(t *testing.T) {
+	if _, err := FromConfig(Config{TLS: TLSConfig{CAFile: "/nonexistent/ca.pem"}}); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+
+	if _, err := FromConfig(Config{Proxy: "://bad"}); err == nil {
+		t.Error("expected error for invalid proxy URL")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("HTTPR_TIMEOUT", "5s")
+	t.Setenv("HTTPR_RETRY_COUNT", "2")
+	t.Setenv("HTTPR_RETRY_BACKOFF", "1.5")
+	t.Setenv("HTTPR_RETRY_MAX_DELAY", "2s")
+	t.Setenv("HTTPR_PROXY", "http://proxy.local:3128")
+	t.Setenv("HTTPR_INSECURE_SKIP_VERIFY", "true")
+
+	cfg, err := ConfigFromEnv("")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if time.Duration(cfg.Timeout) != 5*time.Second {
+		t.Errorf("expected timeout %v, got %v", 5*time.Second, time.Duration(cfg.Timeout))
+	}
+	if cfg.RetryCount != 2 {
+		t.Errorf("expected retry count 2, got %d", cfg.RetryCount)
+	}
+	if cfg.RetryBackoff != 1.5 || time.Duration(cfg.RetryMaxDelay) != 2*time.Second {
+		t.Errorf("expected retry backoff 1.5 with max delay 2s, got %v and %v", cfg.RetryBackoff, time.Duration(cfg.RetryMaxDelay))
+	}
+	if cfg.Proxy != "http://proxy.local:3128" {
+		t.Errorf("expected proxy %q, got %q", "http://proxy.local:3128", cfg.Proxy)
+	}
+	if !cfg.TLS.InsecureSkipVerify {
+		t.Error("expected insecure skip verify to be enabled")
+	}
+
+	t.Setenv("CUSTOM_RETRY_COUNT", "many")
+	if _, err = NewFromEnv("CUSTOM"); err == nil {
+		t.Error("expected error for malformed retry count")
+	}
+}
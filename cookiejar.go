@@ -0,0 +1,268 @@
+package httpr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CookieJar is http.CookieJar, which can list, export, import and remove stored cookies.
+// Jar created with NewPersistentCookieJar can also be saved to and loaded from JSON file, so sessions
+// survive restarts of CLI tools and scrapers. Cookie matching is delegated to net/http/cookiejar.
+// Session cookies (without expiration time) are saved as well, expired ones are dropped.
+type CookieJar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	cookies map[string]CookieRecord
+	path    string
+}
+
+// CookieRecord is cookie along with URL, which set it. It is used for exporting,
+// importing and persisting cookies.
+type CookieRecord struct {
+	URL      string     `json:"url"`
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain,omitempty"`
+	Path     string     `json:"path,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	SameSite int        `json:"sameSite,omitempty"`
+}
+
+// NewCookieJar creates in-memory cookie jar.
+func NewCookieJar() *CookieJar {
+	jar, _ := cookiejar.New(nil)
+	return &CookieJar{
+		jar:     jar,
+		cookies: make(map[string]CookieRecord),
+	}
+}
+
+// NewPersistentCookieJar creates cookie jar, which is stored in JSON file located at path. Cookies are loaded
+// from file, if it exists. Jar isn't saved automatically, Save must be called to persist current cookies.
+func NewPersistentCookieJar(path string) (*CookieJar, error) {
+	j := NewCookieJar()
+	j.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie jar file: %w", err)
+	}
+
+	var stored []CookieRecord
+	if err = json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse cookie jar file: %w", err)
+	}
+
+	if err = j.Import(stored); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// SetCookies implements http.CookieJar interface.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.setCookies(u, cookies, time.Now())
+}
+
+func (j *CookieJar) setCookies(u *url.URL, cookies []*http.Cookie, now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+
+	origin := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	for _, cookie := range cookies {
+		sc := newStoredCookie(origin.String(), cookie, now)
+		key := sc.key(u)
+		if sc.Expires != nil && !sc.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = sc
+	}
+}
+
+// Cookies implements http.CookieJar interface.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.jar.Cookies(u)
+}
+
+// Export returns all unexpired cookies stored in jar.
+func (j *CookieJar) Export() []CookieRecord {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	records := make([]CookieRecord, 0, len(j.cookies))
+	for _, key := range sortedKeys(j.cookies) {
+		if record := j.cookies[key]; record.Expires == nil || record.Expires.After(now) {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// Import adds provided cookies to jar, skipping expired ones.
+func (j *CookieJar) Import(records []CookieRecord) error {
+	now := time.Now()
+	for _, record := range records {
+		if record.Expires != nil && !record.Expires.After(now) {
+			continue
+		}
+
+		u, err := url.Parse(record.URL)
+		if err != nil {
+			return fmt.Errorf("invalid cookie URL: %w", err)
+		}
+		j.setCookies(u, []*http.Cookie{record.cookie()}, now)
+	}
+
+	return nil
+}
+
+// Clear removes cookies, which belong to provided domain or its subdomains.
+// Empty domain removes all cookies.
+func (j *CookieJar) Clear(domain string) {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for key := range j.cookies {
+		cookieDomain, _, _ := strings.Cut(key, ";")
+		if domain == "" || cookieDomain == domain || strings.HasSuffix(cookieDomain, "."+domain) {
+			delete(j.cookies, key)
+		}
+	}
+
+	// Underlying jar can't remove cookies, so it is rebuilt from remaining ones.
+	j.jar, _ = cookiejar.New(nil)
+	for _, record := range j.cookies {
+		if u, err := url.Parse(record.URL); err == nil {
+			j.jar.SetCookies(u, []*http.Cookie{record.cookie()})
+		}
+	}
+}
+
+// Save writes current cookies to jar file atomically. Jar must be created with NewPersistentCookieJar.
+func (j *CookieJar) Save() error {
+	if j.path == "" {
+		return errors.New("cookie jar has no file to save to")
+	}
+
+	data, err := json.MarshalIndent(j.Export(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cookies: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), "."+filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cookie jar file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cookie jar file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("failed to write cookie jar file: %w", err)
+	}
+
+	return nil
+}
+
+func newStoredCookie(origin string, cookie *http.Cookie, now time.Time) CookieRecord {
+	sc := CookieRecord{
+		URL:      origin,
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HTTPOnly: cookie.HttpOnly,
+		SameSite: int(cookie.SameSite),
+	}
+
+	switch {
+	case cookie.MaxAge < 0:
+		expires := time.Unix(0, 0)
+		sc.Expires = &expires
+	case cookie.MaxAge > 0:
+		expires := now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		sc.Expires = &expires
+	case !cookie.Expires.IsZero():
+		expires := cookie.Expires
+		sc.Expires = &expires
+	}
+
+	return sc
+}
+
+// key identifies cookie the same way cookie jar does: by domain, path and name.
+func (sc CookieRecord) key(u *url.URL) string {
+	domain := strings.TrimPrefix(strings.ToLower(sc.Domain), ".")
+	if domain == "" {
+		domain = strings.ToLower(u.Hostname())
+	}
+
+	path := sc.Path
+	if path == "" || path[0] != '/' {
+		path = defaultCookiePath(u.Path)
+	}
+
+	return domain + ";" + path + ";" + sc.Name
+}
+
+func (sc CookieRecord) cookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     sc.Name,
+		Value:    sc.Value,
+		Domain:   sc.Domain,
+		Path:     sc.Path,
+		Secure:   sc.Secure,
+		HttpOnly: sc.HTTPOnly,
+		SameSite: http.SameSite(sc.SameSite),
+	}
+	if sc.Expires != nil {
+		cookie.Expires = *sc.Expires
+	}
+
+	return cookie
+}
+
+// defaultCookiePath returns default cookie path for URL path as defined by RFC 6265.
+func defaultCookiePath(urlPath string) string {
+	if urlPath == "" || urlPath[0] != '/' {
+		return "/"
+	}
+
+	idx := strings.LastIndexByte(urlPath, '/')
+	if idx == 0 {
+		return "/"
+	}
+
+	return urlPath[:idx]
+}
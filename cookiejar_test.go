@@ -0,0 +1,187 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPersistentCookieJar(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", HttpOnly: true})
+			http.SetCookie(w, &http.Cookie{Name: "remember", Value: "yes", Path: "/", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "expired", Value: "old", Path: "/", Expires: time.Unix(1, 0)})
+			http.SetCookie(w, &http.Cookie{Name: "secure", Value: "s", Path: "/", Secure: true})
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: "remember", Path: "/", MaxAge: -1})
+		}
+
+		var names []string
+		for _, cookie := range req.Cookies() {
+			names = append(names, cookie.Name+"="+cookie.Value)
+		}
+		sort.Strings(names)
+		_, _ = w.Write([]byte(strings.Join(names, ",")))
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+
+	jar, err := NewPersistentCookieJar(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	c := New(WithCookieJar(jar))
+	if _, err = c.Get(context.Background(), ts.URL+"/login", nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = jar.Save(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected cookies to be saved")
+	}
+
+	restored, err := NewPersistentCookieJar(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	c = New(WithCookieJar(restored))
+
+	resp, err := c.Get(context.Background(), ts.URL+"/page", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expected := "remember=yes,secure=s,session=abc"; resp.String() != expected {
+		t.Errorf("expected cookies %q after restoring jar, got %q instead", expected, resp.String())
+	}
+
+	if _, err = c.Get(context.Background(), ts.URL+"/logout", nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	u, _ := url.Parse(ts.URL)
+	if cookies := restored.Cookies(u); len(cookies) != 2 {
+		t.Errorf("expected 2 cookies after logout, got %v", cookies)
+	}
+	if len(restored.cookies) != 2 {
+		t.Errorf("expected 2 stored cookies after logout, got %d", len(restored.cookies))
+	}
+
+	restored, err = NewPersistentCookieJar(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, sc := range restored.cookies {
+		if sc.Name == "session" && !sc.HTTPOnly || sc.Name == "secure" && !sc.Secure {
+			t.Errorf("expected cookie flags to be restored, got %+v", sc)
+		}
+	}
+}
+
+func TestEphemeralCookies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: req.URL.Query().Get("user"), Path: "/"})
+			http.Redirect(w, req, "/profile", http.StatusFound)
+		default:
+			if cookie, err := req.Cookie("session"); err == nil {
+				_, _ = w.Write([]byte(cookie.Value))
+			}
+		}
+	}))
+	defer ts.Close()
+
+	jar, _ := cookiejar.New(nil)
+	c := New(WithCookieJar(jar))
+
+	resp, err := c.Get(context.Background(), ts.URL+"/login?user=shared", nil)
+	if err != nil || resp.String() != "shared" {
+		t.Fatalf("expected shared session, got %q (error %v)", resp.String(), err)
+	}
+
+	resp, err = c.Get(context.Background(), ts.URL+"/login?user=probe", nil, WithEphemeralCookies())
+	if err != nil || resp.String() != "probe" {
+		t.Fatalf("expected cookies to be kept across redirects of ephemeral request, got %q (error %v)", resp.String(), err)
+	}
+
+	resp, err = c.Get(context.Background(), ts.URL+"/profile", nil, WithEphemeralCookies())
+	if err != nil || resp.String() != "" {
+		t.Errorf("expected no cookies to be sent with ephemeral jar, got %q (error %v)", resp.String(), err)
+	}
+
+	resp, err = c.Get(context.Background(), ts.URL+"/profile", nil)
+	if err != nil || resp.String() != "shared" {
+		t.Errorf("expected shared jar to be untouched, got %q (error %v)", resp.String(), err)
+	}
+}
+
+func TestClientCookies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/", MaxAge: 3600})
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	other, _ := url.Parse("http://other.test.com/")
+
+	c := New(WithCookieJar(NewCookieJar()))
+	if _, err := c.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	c.SetCookies(other, []*http.Cookie{{Name: "id", Value: "1"}})
+
+	if cookies := c.Cookies(u); len(cookies) != 2 {
+		t.Errorf("expected 2 cookies, got %v", cookies)
+	}
+
+	records, err := c.ExportCookies()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 exported cookies, got %d", len(records))
+	}
+
+	if err = c.ClearCookies("test.com"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cookies := c.Cookies(other); len(cookies) != 0 {
+		t.Errorf("expected cookies of cleared domain to be removed, got %v", cookies)
+	}
+	if cookies := c.Cookies(u); len(cookies) != 2 {
+		t.Errorf("expected cookies of other domains to be kept, got %v", cookies)
+	}
+
+	stdJar, _ := cookiejar.New(nil)
+	imported := New(WithCookieJar(stdJar))
+	if err = imported.ImportCookies(records); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cookies := imported.Cookies(other); len(cookies) != 1 || cookies[0].Value != "1" {
+		t.Errorf("expected imported cookie, got %v", cookies)
+	}
+	if _, err = imported.ExportCookies(); err == nil {
+		t.Error("expected error exporting cookies from standard jar, got nil")
+	}
+
+	if err = c.ClearCookies(""); err != nil || len(c.Cookies(u)) != 0 {
+		t.Errorf("expected all cookies to be removed, got %v (error %v)", c.Cookies(u), err)
+	}
+}
@@ -0,0 +1,233 @@
+package httpr
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// unmarshalCSV decodes CSV data into value pointed by v, which must be one of:
+//   - *[][]string, receiving all records including header;
+//   - *[]map[string]string, receiving records keyed by header columns;
+//   - pointer to slice of structs (or struct pointers), which fields are matched with header
+//     columns by "csv" struct tags, falling back to field names. Tag "-" skips field.
+func unmarshalCSV(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("CSV can only be decoded into pointer to slice, got %T", v)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	if elemType == reflect.TypeOf([]string(nil)) {
+		slice.Set(reflect.ValueOf(records))
+		return nil
+	}
+	if len(records) == 0 {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+		return nil
+	}
+
+	header, rows := records[0], records[1:]
+	result := reflect.MakeSlice(slice.Type(), 0, len(rows))
+
+	switch {
+	case elemType == reflect.TypeOf(map[string]string(nil)):
+		for _, row := range rows {
+			record := make(map[string]string, len(header))
+			for i, column := range header {
+				if i < len(row) {
+					record[column] = row[i]
+				}
+			}
+			result = reflect.Append(result, reflect.ValueOf(record))
+		}
+	case elemType.Kind() == reflect.Struct || (elemType.Kind() == reflect.Pointer && elemType.Elem().Kind() == reflect.Struct):
+		structType := elemType
+		if structType.Kind() == reflect.Pointer {
+			structType = structType.Elem()
+		}
+		fields := csvFieldIndexes(structType, header)
+
+		for n, row := range rows {
+			elem := reflect.New(structType).Elem()
+			for i, value := range row {
+				if i >= len(fields) || fields[i] < 0 {
+					continue
+				}
+				field := structType.Field(fields[i])
+				if err = parseCSVValue(elem.Field(fields[i]), value); err != nil {
+					return fmt.Errorf("record %d, column %q (field %s): %w", n+1, header[i], field.Name, err)
+				}
+			}
+			if elemType.Kind() == reflect.Pointer {
+				elem = elem.Addr()
+			}
+			result = reflect.Append(result, elem)
+		}
+	default:
+		return fmt.Errorf("CSV can't be decoded into slice of %s", elemType)
+	}
+
+	slice.Set(result)
+	return nil
+}
+
+// marshalCSV encodes [][]string or slice of structs (see unmarshalCSV) into CSV.
+// For structs, header row is composed of field names.
+func marshalCSV(v any) ([]byte, error) {
+	if records, ok := v.([][]string); ok {
+		return writeCSV(records)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("CSV value is nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("CSV can only be encoded from slice, got %T", v)
+	}
+
+	structType := rv.Type().Elem()
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("CSV can't be encoded from slice of %s", rv.Type().Elem())
+	}
+
+	var (
+		header []string
+		fields []int
+	)
+	for i := 0; i < structType.NumField(); i++ {
+		if name, ok := csvFieldName(structType.Field(i)); ok {
+			header = append(header, name)
+			fields = append(fields, i)
+		}
+	}
+
+	records := make([][]string, 0, rv.Len()+1)
+	records = append(records, header)
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i)
+		if elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+
+		record := make([]string, len(fields))
+		for j, idx := range fields {
+			value, err := formatFormValue(elem.Field(idx))
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", structType.Field(idx).Name, err)
+			}
+			record[j] = value
+		}
+		records = append(records, record)
+	}
+
+	return writeCSV(records)
+}
+
+func writeCSV(records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvFieldIndexes returns indexes of struct fields matching header columns, -1 for unmatched ones.
+func csvFieldIndexes(structType reflect.Type, header []string) []int {
+	byName := make(map[string]int, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		if name, ok := csvFieldName(structType.Field(i)); ok {
+			byName[name] = i
+		}
+	}
+
+	indexes := make([]int, len(header))
+	for i, column := range header {
+		idx, ok := byName[column]
+		if !ok {
+			idx = -1
+		}
+		indexes[i] = idx
+	}
+	return indexes
+}
+
+func csvFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("csv"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return name, true
+	}
+}
+
+func parseCSVValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		if value == "" {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported CSV value type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testCSVRecord struct {
+	Name   string   `csv:"name"`
+	Age    int      `csv:"age"`
+	Score  *float64 `csv:"score"`
+	Active bool
+	Secret string `csv:"-"`
+}
+
+func TestUnmarshalCSV(t *testing.T) {
+	data := []byte("name,age,score,Active,Secret\nalice,30,1.5,true,x\nbob,25,,false,y\n")
+	score := 1.5
+
+	t.Run("records", func(t *testing.T) {
+		var records [][]string
+		if err := unmarshalCSV(data, &records); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(records) != 3 || records[1][0] != "alice" {
+			t.Errorf("expected 3 records, got %v instead", records)
+		}
+	})
+
+	t.Run("maps", func(t *testing.T) {
+		var records []map[string]string
+		if err := unmarshalCSV(data, &records); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expected := []map[string]string{
+			{"name": "alice", "age": "30", "score": "1.5", "Active": "true", "Secret": "x"},
+			{"name": "bob", "age": "25", "score": "", "Active": "false", "Secret": "y"},
+		}
+		if !reflect.DeepEqual(records, expected) {
+			t.Errorf("expected %v, got %v instead", expected, records)
+		}
+	})
+
+	t.Run("structs", func(t *testing.T) {
+		var records []testCSVRecord
+		if err := unmarshalCSV(data, &records); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expected := []testCSVRecord{
+			{Name: "alice", Age: 30, Score: &score, Active: true},
+			{Name: "bob", Age: 25},
+		}
+		if !reflect.DeepEqual(records, expected) {
+			t.Errorf("expected %+v, got %+v instead", expected, records)
+		}
+	})
+
+	t.Run("struct pointers", func(t *testing.T) {
+		var records []*testCSVRecord
+		if err := unmarshalCSV(data, &records); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(records) != 2 || records[0].Name != "alice" || *records[0].Score != score {
+			t.Errorf("expected 2 records, got %+v instead", records)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var records []testCSVRecord
+		if err := unmarshalCSV([]byte("name,age\nalice,old\n"), &records); err == nil {
+			t.Error("expected error, got nil instead")
+		}
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		var record testCSVRecord
+		if err := unmarshalCSV(data, &record); err == nil {
+			t.Error("expected error, got nil instead")
+		}
+	})
+}
+
+func TestMarshalCSV(t *testing.T) {
+	score := 1.5
+	data, err := marshalCSV([]testCSVRecord{
+		{Name: "alice", Age: 30, Score: &score, Active: true, Secret: "x"},
+		{Name: "bob", Age: 25},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "name,age,score,Active\nalice,30,1.5,true\nbob,25,,false\n"
+	if string(data) != expected {
+		t.Errorf("expected %q, got %q instead", expected, data)
+	}
+}
+
+func TestResponseDecodeAs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		switch req.URL.Path {
+		case "/json":
+			_, _ = w.Write([]byte(`{"name": "alice"}`))
+		case "/xml":
+			_, _ = w.Write([]byte(`<user><name>alice</name></user>`))
+		case "/csv":
+			_, _ = w.Write([]byte("name\nalice\n"))
+		}
+	}))
+	defer ts.Close()
+
+	type user struct {
+		Name string `json:"name" xml:"name" csv:"name"`
+	}
+
+	client := New()
+	for _, format := range []Format{FormatJSON, FormatXML, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			path := map[Format]string{FormatJSON: "/json", FormatXML: "/xml", FormatCSV: "/csv"}[format]
+			resp, err := client.Get(context.Background(), ts.URL+path, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var got user
+			if format == FormatCSV {
+				var users []user
+				err = resp.DecodeAs(format, &users)
+				if len(users) == 1 {
+					got = users[0]
+				}
+			} else {
+				err = resp.DecodeAs(format, &got)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.Name != "alice" {
+				t.Errorf("expected name %q, got %q instead", "alice", got.Name)
+			}
+
+			if err = resp.Decode(&got); !errors.Is(err, ErrCodecNotRegistered) {
+				t.Errorf("expected Decode to fail with ErrCodecNotRegistered, got %v instead", err)
+			}
+		})
+	}
+
+	resp, err := client.Get(context.Background(), ts.URL+"/json", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var users []user
+	if err = resp.DecodeAs(FormatCSV, &users); !errors.Is(err, ErrDecodeBody) {
+		t.Errorf("expected ErrDecodeBody, got %v instead", err)
+	}
+	if err = resp.DecodeAs(Format("application/unknown"), &users); !errors.Is(err, ErrCodecNotRegistered) {
+		t.Errorf("expected ErrCodecNotRegistered, got %v instead", err)
+	}
+}
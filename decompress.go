@@ -0,0 +1,162 @@
+package httpr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Decompressor creates reader, which decompresses provided stream compressed with
+// some content encoding. Returned reader must not close underlying stream.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]Decompressor{
+		"gzip":    gzipDecompressor,
+		"x-gzip":  gzipDecompressor,
+		"deflate": deflateDecompressor,
+	}
+)
+
+func gzipDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func deflateDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// RegisterDecompressor registers decompressor for provided content encoding globally,
+// replacing previously registered one. Built-in decompressors are "gzip", "x-gzip" and "deflate".
+// "zstd" is registered by importing optional github.com/hickar/httpr/zstd module, other encodings
+// like "br" can be added with implementations of choice, e.g. with github.com/andybalholm/brotli:
+//
+//	httpr.RegisterDecompressor("br", func(r io.Reader) (io.ReadCloser, error) {
+//		return io.NopCloser(brotli.NewReader(r)), nil
+//	})
+func RegisterDecompressor(encoding string, decompressor Decompressor) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+
+	decompressors[strings.ToLower(encoding)] = decompressor
+}
+
+// lookupDecompressor returns decompressor for provided encoding. Client-scoped decompressors
+// take precedence over globally registered ones.
+func lookupDecompressor(settings clientSettings, encoding string) (Decompressor, bool) {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if decompressor, ok := settings.decompressors[encoding]; ok {
+		return decompressor, true
+	}
+
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+
+	decompressor, ok := decompressors[encoding]
+	return decompressor, ok
+}
+
+// negotiatedEncodings returns 'Accept-Encoding' header value composed of encodings set with
+// WithAcceptEncoding, which have decompressors registered.
+func negotiatedEncodings(settings clientSettings) string {
+	encodings := make([]string, 0, len(settings.acceptEncodings))
+	for _, encoding := range settings.acceptEncodings {
+		if _, ok := lookupDecompressor(settings, encoding); ok {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	return strings.Join(encodings, ", ")
+}
+
+// wrapWithCompressionReader wraps response body with decompressing reader. Encoding is determined
+// by 'Content-Encoding' response header. If it's absent and gzip sniffing is enabled (see WithGzipSniffing),
+// body is decoded as gzip if 'Content-Type' declares gzip payload or body starts with gzip magic number,
+// which happens when proxies strip 'Content-Encoding' header. Otherwise body is returned as is.
+func wrapWithCompressionReader(resp *http.Response, settings clientSettings) (io.ReadCloser, error) {
+	if encodings := contentEncodings(resp); len(encodings) > 0 {
+		return decodeContent(resp, encodings, settings)
+	}
+	if !settings.gzipSniffing {
+		return resp.Body, nil
+	}
+
+	if isGzipContentType(resp.Header.Get("Content-Type")) {
+		return decodeContent(resp, []string{"gzip"}, settings)
+	}
+
+	buffered := bufio.NewReader(resp.Body)
+	resp.Body = &multiCloseBody{Reader: buffered, closers: []io.Closer{resp.Body}}
+
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err == nil && bytes.Equal(magic, gzipMagic) {
+		return decodeContent(resp, []string{"gzip"}, settings)
+	}
+
+	return resp.Body, nil
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func isGzipContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/gzip" || mediaType == "application/x-gzip"
+}
+
+// contentEncodings returns list of encodings from 'Content-Encoding' header in order they were applied.
+func contentEncodings(resp *http.Response) []string {
+	var encodings []string
+	for _, value := range resp.Header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding != "" && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+
+	return encodings
+}
+
+// decodeContent wraps response body with decompressors for each encoding applied in reverse order.
+// If all encodings are decoded, corresponding response headers are removed.
+func decodeContent(resp *http.Response, encodings []string, settings clientSettings) (io.ReadCloser, error) {
+	var (
+		reader  io.Reader = resp.Body
+		closers []io.Closer
+	)
+
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decompressor, ok := lookupDecompressor(settings, encodings[i])
+		if !ok {
+			return nil, fmt.Errorf("unsupported content encoding %q", encodings[i])
+		}
+
+		decompressed, err := decompressor(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %q content: %w", encodings[i], err)
+		}
+
+		reader = decompressed
+		closers = append([]io.Closer{decompressed}, closers...)
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return &multiCloseBody{Reader: reader, closers: closers}, nil
+}
@@ -0,0 +1,205 @@
+package httpr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func compressTestBody(t *testing.T, encoding string, content []byte) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch encoding {
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	default:
+		w = gzip.NewWriter(&buf)
+	}
+
+	if _, err := w.Write(content); err != nil {
+		t.Fatalf("failed to compress test body: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to compress test body: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecompressors(t *testing.T) {
+	content := []byte("decompressed content")
+
+	tests := []struct {
+		name     string
+		encoding string
+		opts     []Option
+	}{
+		{
+			name:     "Gzip",
+			encoding: "gzip",
+		},
+		{
+			name:     "Deflate",
+			encoding: "deflate",
+		},
+		{
+			name:     "ClientDecompressor",
+			encoding: "zstd",
+			opts: []Option{WithDecompressor("zstd", func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed := compressTestBody(t, tt.encoding, content)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Encoding", tt.encoding)
+				_, _ = w.Write(compressed)
+			}))
+			defer ts.Close()
+
+			c := New(append(tt.opts, WithAutoDecompression(true))...)
+
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, nil)
+			req.Header.Set("Accept-Encoding", tt.encoding)
+
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !bytes.Equal(resp.Bytes(), content) {
+				t.Errorf("expected body %q, got %q", content, resp.Bytes())
+			}
+			if resp.Raw().Header.Get("Content-Encoding") != "" {
+				t.Error("expected Content-Encoding header to be removed after decoding")
+			}
+		})
+	}
+}
+
+func TestUnsupportedContentEncoding(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Encoding", "unknown")
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer ts.Close()
+
+	c := New(WithAutoDecompression(true))
+	if _, err := c.Get(context.Background(), ts.URL, nil); err == nil {
+		t.Fatal("expected error for unsupported content encoding")
+	}
+}
+
+func TestDecompressionWithoutContentEncoding(t *testing.T) {
+	content := []byte("decompressed content")
+	compressed := compressTestBody(t, "gzip", content)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{
+			name:        "GzipContentType",
+			contentType: "application/x-gzip",
+			body:        compressed,
+		},
+		{
+			name:        "StrippedContentEncoding",
+			contentType: "application/octet-stream",
+			body:        compressed,
+		},
+		{
+			name:        "NotCompressed",
+			contentType: "text/plain",
+			body:        content,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write(tt.body)
+			}))
+			defer ts.Close()
+
+			c := New(WithAutoDecompression(true), WithGzipSniffing(true))
+
+			resp, err := c.Get(context.Background(), ts.URL, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !bytes.Equal(resp.Bytes(), content) {
+				t.Errorf("expected body %q, got %q", content, resp.Bytes())
+			}
+		})
+	}
+}
+
+func TestGzipPayloadKeptWithoutSniffing(t *testing.T) {
+	compressed := compressTestBody(t, "gzip", []byte("archive content"))
+
+	for _, contentType := range []string{"application/gzip", "application/octet-stream"} {
+		t.Run(contentType, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", contentType)
+				_, _ = w.Write(compressed)
+			}))
+			defer ts.Close()
+
+			resp, err := New(WithAutoDecompression(true)).Get(context.Background(), ts.URL, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !bytes.Equal(resp.Bytes(), compressed) {
+				t.Errorf("expected .gz payload to be kept intact, got %q", resp.Bytes())
+			}
+		})
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	content := []byte("decompressed content")
+	compressed := compressTestBody(t, "deflate", content)
+
+	var receivedAcceptEncoding string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedAcceptEncoding = req.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "deflate")
+		_, _ = w.Write(compressed)
+	}))
+	defer ts.Close()
+
+	c := New(WithTransport(DefaultTransport()), WithAcceptEncoding("deflate", "unknown", "gzip"))
+
+	if tr, ok := c.Client().Transport.(*http.Transport); !ok || !tr.DisableCompression {
+		t.Fatal("expected transport implicit compression to be disabled")
+	}
+
+	resp, err := c.Get(context.Background(), ts.URL, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if receivedAcceptEncoding != "deflate, gzip" {
+		t.Errorf("expected only supported encodings to be advertised, got %q", receivedAcceptEncoding)
+	}
+	if !bytes.Equal(resp.Bytes(), content) {
+		t.Errorf("expected body %q, got %q", content, resp.Bytes())
+	}
+}
@@ -0,0 +1,218 @@
+package httpr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// IPPreference specifies, which IP address families client connects over, see WithIPPreference.
+type IPPreference int
+
+const (
+	// IPAny makes client connect over any address family, racing families as transport dialer does.
+	IPAny IPPreference = iota
+	// IPv4Only makes client connect over IPv4 only; hosts without IPv4 addresses can't be reached.
+	IPv4Only
+	// IPv6Only makes client connect over IPv6 only; hosts without IPv6 addresses can't be reached.
+	IPv6Only
+	// PreferIPv4 makes client try IPv4 addresses first, falling back to IPv6 ones.
+	PreferIPv4
+	// PreferIPv6 makes client try IPv6 addresses first, falling back to IPv4 ones.
+	PreferIPv6
+)
+
+// _defaultDialFallbackDelay is delay before fallback address family is raced, matching net.Dialer default.
+const _defaultDialFallbackDelay = 300 * time.Millisecond
+
+// WithIPPreference sets address families, which client connects over. Dual-stack hosts with broken
+// IPv6 connectivity or misconfigured AAAA records are common source of connection failures and
+// latency spikes, which can be avoided with IPv4Only or PreferIPv4:
+//
+//	client := httpr.New(httpr.WithIPPreference(httpr.PreferIPv4))
+//
+// With preference set, host is resolved at dialer and addresses of preferred family are dialed first,
+// while other family is raced after fallback delay (see WithDialFallbackDelay). Option has effect only
+// when passed to client constructor or Client.With and only for *http.Transport.
+func WithIPPreference(preference IPPreference) Option {
+	return func(settings *clientSettings) {
+		settings.ipPreference = preference
+	}
+}
+
+// WithDialFallbackDelay sets delay before connection over fallback address family is started, while
+// connection over primary family is still pending ("Happy Eyeballs", RFC 6555). Zero delay means
+// default of 300ms, negative delay disables racing, so fallback addresses are dialed only after
+// all primary ones failed. Option has effect only when passed to client constructor or Client.With
+// and only for *http.Transport.
+func WithDialFallbackDelay(delay time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.dialFallbackDelay = delay
+	}
+}
+
+// dialFunc is signature of http.Transport dial functions.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// ipDialer resolves target host and dials its addresses according to IP preference.
+type ipDialer struct {
+	dial       dialFunc
+	lookup     func(ctx context.Context, host string) ([]net.IPAddr, error)
+	preference IPPreference
+	delay      time.Duration
+}
+
+// ipDialContext wraps dial function, so connections are made according to IP preference and fallback delay.
+func ipDialContext(dial dialFunc, preference IPPreference, delay time.Duration) dialFunc {
+	if dial == nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		dial = dialer.DialContext
+	}
+
+	d := &ipDialer{
+		dial:       dial,
+		lookup:     net.DefaultResolver.LookupIPAddr,
+		preference: preference,
+		delay:      delay,
+	}
+	return d.DialContext
+}
+
+func (d *ipDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := d.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	primaries, fallbacks := d.partition(ips)
+	if len(primaries) == 0 {
+		return nil, fmt.Errorf("host '%s' has no addresses of required family", host)
+	}
+
+	if len(fallbacks) == 0 || d.delay < 0 {
+		return d.dialSerial(ctx, network, port, append(primaries, fallbacks...))
+	}
+	return d.dialParallel(ctx, network, port, primaries, fallbacks)
+}
+
+// partition splits addresses into primary and fallback ones according to preference. Without
+// explicit preference, family of first address is primary, as it's done by net.Dialer.
+func (d *ipDialer) partition(ips []net.IP) (primaries, fallbacks []net.IP) {
+	if len(ips) == 0 {
+		return nil, nil
+	}
+
+	preferIPv4 := ips[0].To4() != nil
+	switch d.preference {
+	case IPv4Only, PreferIPv4:
+		preferIPv4 = true
+	case IPv6Only, PreferIPv6:
+		preferIPv4 = false
+	}
+
+	for _, ip := range ips {
+		if (ip.To4() != nil) == preferIPv4 {
+			primaries = append(primaries, ip)
+		} else if d.preference != IPv4Only && d.preference != IPv6Only {
+			fallbacks = append(fallbacks, ip)
+		}
+	}
+	if len(primaries) == 0 {
+		return fallbacks, nil
+	}
+	return primaries, fallbacks
+}
+
+// dialSerial dials provided addresses one by one until connection is established.
+func (d *ipDialer) dialSerial(ctx context.Context, network, port string, ips []net.IP) (net.Conn, error) {
+	var dialErr error
+	for _, ip := range ips {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		conn, err := d.dial(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+	return nil, dialErr
+}
+
+// dialParallel dials primary addresses, starting race with fallback ones after fallback delay
+// or as soon as primary addresses failed. First established connection is returned.
+func (d *ipDialer) dialParallel(ctx context.Context, network, port string, primaries, fallbacks []net.IP) (net.Conn, error) {
+	type dialResult struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult)
+	race := func(ips []net.IP, primary bool) {
+		conn, err := d.dialSerial(ctx, network, port, ips)
+		select {
+		case results <- dialResult{conn: conn, err: err, primary: primary}:
+		case <-ctx.Done():
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+	}
+
+	delay := d.delay
+	if delay == 0 {
+		delay = _defaultDialFallbackDelay
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	go race(primaries, true)
+	fallbackStarted := false
+	var primaryErr, fallbackErr error
+	for {
+		select {
+		case <-timer.C:
+			if !fallbackStarted {
+				fallbackStarted = true
+				go race(fallbacks, false)
+			}
+		case res := <-results:
+			if res.err == nil {
+				return res.conn, nil
+			}
+			if res.primary {
+				primaryErr = res.err
+			} else {
+				fallbackErr = res.err
+			}
+
+			if primaryErr != nil && fallbackErr != nil {
+				return nil, primaryErr
+			}
+			if !fallbackStarted {
+				fallbackStarted = true
+				timer.Stop()
+				go race(fallbacks, false)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIPDialer(t *testing.T) {
+	dualStack := func(context.Context, string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("2001:db8::1")}, {IP: net.ParseIP("192.0.2.1")}}, nil
+	}
+	errRefused := errors.New("refused")
+
+	testCases := []struct {
+		name        string
+		preference  IPPreference
+		delay       time.Duration
+		lookup      func(context.Context, string) ([]net.IPAddr, error)
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:       "IPv4Only",
+			preference: IPv4Only,
+			lookup:     dualStack,
+			expected:   []string{"192.0.2.1:80"},
+		},
+		{
+			name:       "IPv6Only",
+			preference: IPv6Only,
+			lookup:     dualStack,
+			expected:   []string{"[2001:db8::1]:80"},
+		},
+		{
+			name:       "PreferIPv4",
+			preference: PreferIPv4,
+			delay:      -1,
+			lookup:     dualStack,
+			expected:   []string{"192.0.2.1:80", "[2001:db8::1]:80"},
+		},
+		{
+			name:       "NoRequiredFamily",
+			preference: IPv6Only,
+			lookup: func(context.Context, string) ([]net.IPAddr, error) {
+				return []net.IPAddr{{IP: net.ParseIP("192.0.2.1")}}, nil
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var dialed []string
+			d := &ipDialer{
+				dial: func(_ context.Context, _, addr string) (net.Conn, error) {
+					dialed = append(dialed, addr)
+					return nil, errRefused
+				},
+				lookup:     tc.lookup,
+				preference: tc.preference,
+				delay:      tc.delay,
+			}
+
+			_, err := d.DialContext(context.Background(), "tcp", "example.com:80")
+			if tc.expectedErr {
+				if err == nil || errors.Is(err, errRefused) {
+					t.Fatalf("expected address family error, got %v instead", err)
+				}
+				return
+			}
+			if !errors.Is(err, errRefused) {
+				t.Fatalf("expected dial error, got %v instead", err)
+			}
+			if !reflect.DeepEqual(dialed, tc.expected) {
+				t.Fatalf("expected dialed addresses %v, got %v instead", tc.expected, dialed)
+			}
+		})
+	}
+}
+
+func TestIPDialerFallbackRace(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		dialed []string
+	)
+	d := &ipDialer{
+		dial: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			mu.Lock()
+			dialed = append(dialed, addr)
+			mu.Unlock()
+
+			if addr == "[2001:db8::1]:80" {
+				// Blackholed IPv6 route: connection hangs until it's abandoned.
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			conn, peer := net.Pipe()
+			_ = peer.Close()
+			return conn, nil
+		},
+		lookup: func(context.Context, string) ([]net.IPAddr, error) {
+			return []net.IPAddr{{IP: net.ParseIP("2001:db8::1")}, {IP: net.ParseIP("192.0.2.1")}}, nil
+		},
+		preference: PreferIPv6,
+		delay:      10 * time.Millisecond,
+	}
+
+	start := time.Now()
+	conn, err := d.DialContext(context.Background(), "tcp", "example.com:80")
+	if err != nil {
+		t.Fatalf("expected fallback connection, got error: %v", err)
+	}
+	_ = conn.Close()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected fallback to be raced after delay, took %v", elapsed)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if expected := []string{"[2001:db8::1]:80", "192.0.2.1:80"}; !reflect.DeepEqual(dialed, expected) {
+		t.Fatalf("expected dialed addresses %v, got %v instead", expected, dialed)
+	}
+}
+
+func TestWithIPPreference(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		name      string
+		opts      []Option
+		wantError bool
+	}{
+		{name: "IPv4Only", opts: []Option{WithIPPreference(IPv4Only)}},
+		{name: "PreferIPv6", opts: []Option{WithIPPreference(PreferIPv6), WithDialFallbackDelay(50 * time.Millisecond)}},
+		{name: "IPv6Only", opts: []Option{WithIPPreference(IPv6Only)}, wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := New(append(tc.opts, WithTransport(DefaultTransport()))...)
+			resp, err := client.Get(context.Background(), ts.URL, nil)
+			if tc.wantError {
+				if err == nil {
+					t.Fatal("expected error for IPv4 server")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected request error: %v", err)
+			}
+			if resp.String() != "ok" {
+				t.Fatalf("expected body %q, got %q instead", "ok", resp.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package httpr
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+)
+
+// DigestAlgorithm is an algorithm used for request body digest calculation.
+type DigestAlgorithm string
+
+const (
+	// DigestSHA256 sets 'Content-Digest' header with SHA-256 body digest (RFC 9530).
+	DigestSHA256 DigestAlgorithm = "sha-256"
+	// DigestSHA512 sets 'Content-Digest' header with SHA-512 body digest (RFC 9530).
+	DigestSHA512 DigestAlgorithm = "sha-512"
+	// DigestMD5 sets legacy 'Content-MD5' header with MD5 body digest (RFC 1864).
+	DigestMD5 DigestAlgorithm = "md5"
+)
+
+func (a DigestAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case DigestSHA256:
+		return sha256.New(), nil
+	case DigestSHA512:
+		return sha512.New(), nil
+	case DigestMD5:
+		return md5.New(), nil //nolint:gosec
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm %q", string(a))
+	}
+}
+
+// setContentDigest calculates digest of request body and sets corresponding header.
+// Body is read with hashBody, so it can be rewound on retries.
+func setContentDigest(req *http.Request, algo DigestAlgorithm) error {
+	h, err := algo.newHash()
+	if err != nil {
+		return err
+	}
+
+	if err = hashBody(req, h); err != nil {
+		return err
+	}
+
+	digest := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	if algo == DigestMD5 {
+		req.Header.Set("Content-MD5", digest)
+	} else {
+		req.Header.Set("Content-Digest", string(algo)+"=:"+digest+":")
+	}
+
+	return nil
+}
+
+// hashBody writes request body into provided hash. Body is read from a copy obtained with GetBody,
+// if possible. Otherwise, body is buffered in memory and GetBody is set, so body can be rewound on retries.
+func hashBody(req *http.Request, h hash.Hash) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	if req.GetBody == nil {
+		if err := bufferBody(req); err != nil {
+			return err
+		}
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to get request body for digest: %w", err)
+	}
+	_, err = io.Copy(h, body)
+	_ = body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read request body for digest: %w", err)
+	}
+
+	return nil
+}
+
+// bufferBody reads request body into memory, so it can be read multiple times with GetBody.
+func bufferBody(req *http.Request) error {
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to buffer request body: %w", err)
+	}
+
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+
+	return nil
+}
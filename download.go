@@ -0,0 +1,443 @@
+package httpr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultDownloadConcurrency = 4
+	_defaultDownloadRetries     = 3
+	_defaultDownloadRetryDelay  = time.Second
+	_downloadProgressInterval   = 500 * time.Millisecond
+	_downloadPartSuffix         = ".part"
+	_downloadMetaSuffix         = ".part.json"
+)
+
+// DownloadManagerConfig describes behaviour of DownloadManager.
+type DownloadManagerConfig struct {
+	// Concurrency is maximum number of simultaneous downloads. Default is 4.
+	Concurrency int
+	// Retries is number of retries of each failed download. Default is 3, negative value disables retries.
+	Retries int
+	// RetryDelay is delay before retry of failed download. Default is one second.
+	RetryDelay time.Duration
+	// Progress receives aggregate progress of all downloads. It's called at most twice a second
+	// and whenever download finishes, possibly from different goroutines, but never concurrently.
+	Progress func(progress DownloadProgress)
+	// Options are applied to every download request.
+	Options []Option
+}
+
+// DownloadProgress is aggregate progress of downloads executed by DownloadManager.
+type DownloadProgress struct {
+	Files     int
+	Completed int
+	Failed    int
+	// Bytes is number of bytes downloaded so far, including ones resumed from previous runs.
+	Bytes int64
+	// Total is sum of sizes of files, which size is already known.
+	Total int64
+}
+
+// DownloadResult is outcome of single download.
+type DownloadResult struct {
+	URL  string
+	Path string
+	// Size is size of downloaded file.
+	Size int64
+	// Resumed is set, if download was continued from partial file left by previous attempt or run.
+	Resumed  bool
+	Attempts int
+	Err      error
+}
+
+// DownloadManager downloads queued URLs into files with limited concurrency. Data is written to
+// "<path>.part" file, along with "<path>.part.json" metadata, holding validators of downloaded
+// representation. Failed or interrupted downloads are resumed from partial file with Range request,
+// if server supports it and representation didn't change; otherwise download starts over. Complete
+// file is renamed to destination path, so it never contains partial data.
+//
+//	m := httpr.NewDownloadManager(client, httpr.DownloadManagerConfig{Concurrency: 8})
+//	for _, file := range files {
+//		m.Enqueue(file.URL, filepath.Join(dir, file.Name))
+//	}
+//	results, err := m.Run(ctx)
+type DownloadManager struct {
+	client *Client
+	cfg    DownloadManagerConfig
+
+	mu         sync.Mutex
+	queue      []*downloadTask
+	progress   DownloadProgress
+	reportedAt time.Time
+	reportMu   sync.Mutex
+}
+
+type downloadTask struct {
+	url    string
+	path   string
+	result DownloadResult
+
+	// downloaded and size are guarded by DownloadManager mutex.
+	downloaded int64
+	size       int64
+}
+
+// downloadMeta is persisted metadata of partial download.
+type downloadMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Size         int64  `json:"size"`
+}
+
+// NewDownloadManager creates DownloadManager, which executes downloads with provided client.
+func NewDownloadManager(client *Client, cfg DownloadManagerConfig) *DownloadManager {
+	if client == nil {
+		client = DefaultClient
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = _defaultDownloadConcurrency
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = _defaultDownloadRetries
+	} else if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = _defaultDownloadRetryDelay
+	}
+
+	return &DownloadManager{client: client, cfg: cfg}
+}
+
+// Enqueue adds download of URL into file at path to queue. Downloads are started with Run.
+func (m *DownloadManager) Enqueue(url, path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queue = append(m.queue, &downloadTask{url: url, path: path, size: -1})
+}
+
+// Run executes queued downloads and waits for their completion. Results are returned in order
+// of enqueuing; error is returned, if any download failed. Downloads enqueued during Run are
+// left for the next call. Canceling ctx interrupts downloads, keeping partial files for resumption.
+func (m *DownloadManager) Run(ctx context.Context) ([]DownloadResult, error) {
+	m.mu.Lock()
+	tasks := m.queue
+	m.queue = nil
+	m.progress = DownloadProgress{Files: len(tasks)}
+	m.mu.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		jobs = make(chan *downloadTask)
+	)
+	for i := 0; i < m.cfg.Concurrency && i < len(tasks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range jobs {
+				m.download(ctx, task)
+			}
+		}()
+	}
+	for _, task := range tasks {
+		jobs <- task
+	}
+	close(jobs)
+	wg.Wait()
+
+	var (
+		results = make([]DownloadResult, len(tasks))
+		failed  int
+	)
+	for i, task := range tasks {
+		results[i] = task.result
+		if task.result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d downloads failed", failed, len(tasks))
+	}
+
+	return results, nil
+}
+
+func (m *DownloadManager) download(ctx context.Context, task *downloadTask) {
+	task.result = DownloadResult{URL: task.url, Path: task.path}
+
+	var err error
+	for attempt := 0; attempt <= m.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			if err = sleepContext(ctx, m.client.settings.clock(), m.cfg.RetryDelay); err != nil {
+				break
+			}
+		}
+
+		task.result.Attempts++
+		if err = m.downloadOnce(ctx, task); err == nil || ctx.Err() != nil || !isRetryableDownloadError(err) {
+			break
+		}
+	}
+
+	m.mu.Lock()
+	task.result.Err = err
+	if err != nil {
+		m.progress.Failed++
+	} else {
+		m.progress.Completed++
+	}
+	m.mu.Unlock()
+	m.report(true)
+}
+
+func (m *DownloadManager) downloadOnce(ctx context.Context, task *downloadTask) error {
+	if err := os.MkdirAll(filepath.Dir(task.path), 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to create parent directories: %w", err)
+	}
+
+	partPath, metaPath := task.path+_downloadPartSuffix, task.path+_downloadMetaSuffix
+	meta, offset := loadDownloadMeta(task.url, partPath, metaPath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, task.url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		if validator := meta.validator(); validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+
+	resp, err := m.client.DoRaw(req, m.cfg.Options...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			// Server returned range other than requested, so download is started over.
+			_ = resp.Body.Close()
+			_ = os.Remove(metaPath)
+			if err = os.Remove(partPath); err != nil {
+				return fmt.Errorf("failed to remove partial file: %w", err)
+			}
+			return m.downloadOnce(ctx, task)
+		}
+		task.result.Resumed = true
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 && offset == meta.Size:
+		// Partial file is already complete.
+		m.setProgress(task, offset, meta.Size)
+		return m.finishDownload(task, partPath, metaPath, offset)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		offset = 0
+	default:
+		return &downloadStatusError{status: resp.Status, code: resp.StatusCode}
+	}
+
+	meta = downloadMeta{
+		URL:          task.url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Size:         -1,
+	}
+	if resp.ContentLength >= 0 {
+		meta.Size = offset + resp.ContentLength
+	}
+	if err = saveDownloadMeta(metaPath, meta); err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(partPath, flags, 0o644) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open partial file: %w", err)
+	}
+
+	m.setProgress(task, offset, meta.Size)
+	written, err := io.Copy(f, &downloadProgressReader{r: resp.Body, m: m, task: task})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+
+	size := offset + written
+	if meta.Size >= 0 && size != meta.Size {
+		return fmt.Errorf("%w: got %d of %d bytes", errDownloadIncomplete, size, meta.Size)
+	}
+	return m.finishDownload(task, partPath, metaPath, size)
+}
+
+func (m *DownloadManager) finishDownload(task *downloadTask, partPath, metaPath string, size int64) error {
+	if err := os.Rename(partPath, task.path); err != nil {
+		return fmt.Errorf("failed to rename partial file: %w", err)
+	}
+	_ = os.Remove(metaPath)
+
+	task.result.Size = size
+	return nil
+}
+
+// setProgress resets progress of task to provided downloaded bytes and size.
+func (m *DownloadManager) setProgress(task *downloadTask, downloaded, size int64) {
+	m.mu.Lock()
+	m.progress.Bytes += downloaded - task.downloaded
+	if task.size >= 0 {
+		m.progress.Total -= task.size
+	}
+	if size >= 0 {
+		m.progress.Total += size
+	}
+	task.downloaded, task.size = downloaded, size
+	m.mu.Unlock()
+}
+
+// report passes aggregate progress to callback, at most twice a second unless forced.
+func (m *DownloadManager) report(force bool) {
+	if m.cfg.Progress == nil {
+		return
+	}
+
+	m.reportMu.Lock()
+	defer m.reportMu.Unlock()
+
+	now := m.client.settings.clock().Now()
+	m.mu.Lock()
+	if !force && now.Sub(m.reportedAt) < _downloadProgressInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.reportedAt = now
+	progress := m.progress
+	m.mu.Unlock()
+
+	m.cfg.Progress(progress)
+}
+
+// errDownloadIncomplete is returned when connection is closed before whole file is downloaded.
+var errDownloadIncomplete = errors.New("download is incomplete")
+
+// downloadStatusError is returned when download response has unexpected status.
+type downloadStatusError struct {
+	status string
+	code   int
+}
+
+func (e *downloadStatusError) Error() string {
+	return "unexpected response status: " + e.status
+}
+
+// isRetryableDownloadError reports whether failed download is retried. Network errors, incomplete
+// downloads, 5xx and 429 Too Many Requests responses are retried, while other statuses and
+// file system errors are permanent.
+func isRetryableDownloadError(err error) bool {
+	var statusErr *downloadStatusError
+	if errors.As(err, &statusErr) {
+		return Is5xx(statusErr.code) || statusErr.code == http.StatusTooManyRequests
+	}
+	return errors.Is(err, errDownloadIncomplete) || RetryOnNetworkError(nil, err)
+}
+
+// contentRangeStart returns first byte position of "bytes first-last/length" Content-Range value.
+func contentRangeStart(value string) (int64, bool) {
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, false
+	}
+	value = strings.TrimPrefix(value, "bytes ")
+
+	idx := strings.IndexByte(value, '-')
+	if idx < 0 {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(value[:idx], 10, 64)
+	return start, err == nil
+}
+
+// validator returns value of If-Range header, which ensures partial file is resumed
+// only if representation didn't change.
+func (meta downloadMeta) validator() string {
+	if meta.ETag != "" && !isWeakETag(meta.ETag) {
+		return meta.ETag
+	}
+	return meta.LastModified
+}
+
+func isWeakETag(etag string) bool {
+	return len(etag) > 2 && etag[:2] == "W/"
+}
+
+// loadDownloadMeta returns metadata and size of partial download. Zero offset is returned,
+// if there's no partial file or it belongs to another URL.
+func loadDownloadMeta(url, partPath, metaPath string) (downloadMeta, int64) {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return downloadMeta{}, 0
+	}
+
+	var meta downloadMeta
+	if err = json.Unmarshal(data, &meta); err != nil || meta.URL != url {
+		return downloadMeta{}, 0
+	}
+
+	info, err := os.Stat(partPath)
+	if err != nil || (meta.Size >= 0 && info.Size() > meta.Size) {
+		return downloadMeta{}, 0
+	}
+	if meta.validator() == "" {
+		// Without validators, partial file can't be safely combined with current representation.
+		return meta, 0
+	}
+
+	return meta, info.Size()
+}
+
+func saveDownloadMeta(path string, meta downloadMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to save download metadata: %w", err)
+	}
+	return nil
+}
+
+// downloadProgressReader accounts bytes read from download response body in aggregate progress.
+type downloadProgressReader struct {
+	r    io.Reader
+	m    *DownloadManager
+	task *downloadTask
+}
+
+func (r *downloadProgressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.m.mu.Lock()
+		r.task.downloaded += int64(n)
+		r.m.progress.Bytes += int64(n)
+		r.m.mu.Unlock()
+		r.m.report(false)
+	}
+	return n, err
+}
@@ -0,0 +1,247 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadManager(t *testing.T) {
+	var (
+		inFlight, maxInFlight int32
+		files                 = map[string]string{
+			"/a": strings.Repeat("a", 1000),
+			"/b": strings.Repeat("b", 2000),
+			"/c": strings.Repeat("c", 3000),
+			"/d": strings.Repeat("d", 4000),
+		}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		content, ok := files[req.URL.Path]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		http.ServeContent(w, req, "", time.Time{}, strings.NewReader(content))
+	}))
+	defer ts.Close()
+
+	var (
+		mu       sync.Mutex
+		progress []DownloadProgress
+		dir      = t.TempDir()
+	)
+	m := NewDownloadManager(New(WithClock(NewFakeClock(time.Unix(0, 0)))), DownloadManagerConfig{
+		Concurrency: 2,
+		Retries:     -1,
+		Progress: func(p DownloadProgress) {
+			mu.Lock()
+			progress = append(progress, p)
+			mu.Unlock()
+		},
+	})
+	for _, path := range []string{"/a", "/b", "/c", "/d", "/missing"} {
+		m.Enqueue(ts.URL+path, filepath.Join(dir, "nested", path))
+	}
+
+	results, err := m.Run(context.Background())
+	if err == nil || err.Error() != "1 of 5 downloads failed" {
+		t.Errorf("expected 1 failed download, got %v instead", err)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent downloads, got %d instead", maxInFlight)
+	}
+
+	for i, path := range []string{"/a", "/b", "/c", "/d"} {
+		if results[i].Err != nil || results[i].Size != int64(len(files[path])) || results[i].Attempts != 1 {
+			t.Errorf("expected successful download of %s, got %+v instead", path, results[i])
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "nested", path))
+		if err != nil || string(data) != files[path] {
+			t.Errorf("expected downloaded file %s to match, got error %v", path, err)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "nested", path) + _downloadPartSuffix); !os.IsNotExist(err) {
+			t.Errorf("expected partial file of %s to be removed", path)
+		}
+	}
+	if results[4].Err == nil {
+		t.Error("expected download of missing file to fail")
+	}
+
+	last := progress[len(progress)-1]
+	expected := DownloadProgress{Files: 5, Completed: 4, Failed: 1, Bytes: 10000, Total: 10000}
+	if last.Files != expected.Files || last.Completed != expected.Completed || last.Failed != expected.Failed ||
+		last.Bytes != expected.Bytes || last.Total < expected.Total {
+		t.Errorf("expected final progress %+v, got %+v instead", expected, last)
+	}
+}
+
+func TestDownloadManagerResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+
+	var (
+		mu     sync.Mutex
+		ranges []string
+		calls  int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		calls++
+		call := calls
+		ranges = append(ranges, req.Header.Get("Range"))
+		mu.Unlock()
+
+		w.Header().Set("ETag", `"v1"`)
+		if call == 1 {
+			// Connection breaks in the middle of body.
+			w.Header().Set("Content-Length", "1000")
+			_, _ = w.Write([]byte(content[:400]))
+			return
+		}
+		http.ServeContent(w, req, "", time.Time{}, strings.NewReader(content))
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	m := NewDownloadManager(New(WithClock(NewFakeClock(time.Unix(0, 0)))), DownloadManagerConfig{Retries: 2})
+	m.Enqueue(ts.URL, path)
+
+	results, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !results[0].Resumed || results[0].Attempts != 2 || results[0].Size != 1000 {
+		t.Errorf("expected download resumed on second attempt, got %+v instead", results[0])
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=400-" {
+		t.Errorf("expected second request to resume from byte 400, got ranges %q instead", ranges)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != content {
+		t.Errorf("expected downloaded file to match content, got error %v", err)
+	}
+}
+
+func TestDownloadManagerResumeChanged(t *testing.T) {
+	content := strings.Repeat("x", 100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, req, "", time.Time{}, strings.NewReader(content))
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	meta, _ := json.Marshal(downloadMeta{URL: ts.URL, ETag: `"v1"`, Size: 100})
+	if err := os.WriteFile(path+_downloadMetaSuffix, meta, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+_downloadPartSuffix, bytes.Repeat([]byte("y"), 50), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDownloadManager(nil, DownloadManagerConfig{})
+	m.Enqueue(ts.URL, path)
+	results, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if results[0].Resumed {
+		t.Error("expected download of changed representation to start over")
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("expected file content %q, got %q instead", content, data)
+	}
+}
+
+func TestDownloadManagerResumeRangeMismatch(t *testing.T) {
+	content := strings.Repeat("0123456789", 10)
+
+	var ranges []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ranges = append(ranges, req.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		if req.Header.Get("Range") != "" {
+			// Server ignores requested offset and returns range from other position.
+			w.Header().Set("Content-Range", "bytes 10-99/100")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(content[10:]))
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	meta, _ := json.Marshal(downloadMeta{URL: ts.URL, ETag: `"v1"`, Size: 100})
+	if err := os.WriteFile(path+_downloadMetaSuffix, meta, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+_downloadPartSuffix, []byte(content[:50]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDownloadManager(nil, DownloadManagerConfig{Retries: -1})
+	m.Enqueue(ts.URL, path)
+	results, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if results[0].Resumed || results[0].Size != 100 {
+		t.Errorf("expected download to start over, got %+v instead", results[0])
+	}
+	if len(ranges) != 2 || ranges[0] != "bytes=50-" || ranges[1] != "" {
+		t.Errorf("expected resumption followed by full request, got ranges %q instead", ranges)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("expected file content %q, got %q instead", content, data)
+	}
+}
+
+func TestDownloadManagerRetries(t *testing.T) {
+	testCases := []struct {
+		name             string
+		status           int
+		expectedAttempts int
+	}{
+		{name: "not found", status: http.StatusNotFound, expectedAttempts: 1},
+		{name: "forbidden", status: http.StatusForbidden, expectedAttempts: 1},
+		{name: "too many requests", status: http.StatusTooManyRequests, expectedAttempts: 3},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, expectedAttempts: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer ts.Close()
+
+			m := NewDownloadManager(New(), DownloadManagerConfig{Retries: 2, RetryDelay: time.Millisecond})
+			m.Enqueue(ts.URL, filepath.Join(t.TempDir(), "file"))
+			results, _ := m.Run(context.Background())
+			if results[0].Err == nil || results[0].Attempts != tc.expectedAttempts {
+				t.Errorf("expected failed download after %d attempts, got %+v instead", tc.expectedAttempts, results[0])
+			}
+		})
+	}
+}
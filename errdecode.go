@@ -0,0 +1,99 @@
+package httpr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ErrorDecoderFunc converts unsuccessful (4xx or 5xx) response into error returned by Client.Do.
+// If nil is returned, response is treated as successful one.
+type ErrorDecoderFunc func(resp *Response) error
+
+// decodeErrorTarget decodes body of unsuccessful response into value created by
+// factory set with WithErrorTarget.
+func decodeErrorTarget(settings clientSettings, resp *Response) error {
+	if settings.errorTargetFn == nil || resp.IsSuccess() || len(resp.Bytes()) == 0 {
+		return nil
+	}
+
+	target := settings.errorTargetFn()
+	if err := decodeBody(resp, target); err != nil {
+		return fmt.Errorf("failed to decode error response with status %d: %w", resp.StatusCode(), err)
+	}
+	resp.errorResult = target
+
+	return nil
+}
+
+// decodeResponseError returns error for unsuccessful response using error decoder and error
+// models set with WithErrorDecoder and WithErrorModel. Error decoder takes precedence.
+// If neither produced error, but response status is considered unsuccessful by WithFailOnStatus,
+// *ProblemError is returned for RFC 7807 responses and *ResponseError for others.
+func decodeResponseError(settings clientSettings, resp *Response, attempts int) error {
+	if resp.IsError() {
+		if settings.errorDecoderFn != nil {
+			if err := settings.errorDecoderFn(resp); err != nil {
+				return err
+			}
+		}
+
+		modelType, ok := settings.errorModels[resp.StatusCode()]
+		if !ok {
+			modelType, ok = settings.errorModels[0]
+		}
+		if ok {
+			return decodeErrorModel(resp, modelType)
+		}
+	}
+
+	if settings.failOnStatusFn == nil || !settings.failOnStatusFn(resp.StatusCode()) {
+		return nil
+	}
+
+	respErr := newResponseError(resp, attempts)
+	if resp.isProblem() {
+		if problem, err := resp.Problem(); err == nil {
+			return &ProblemError{Problem: problem, Response: resp, respErr: respErr}
+		}
+	}
+
+	return respErr
+}
+
+// decodeErrorModel unmarshalls response body into new value of provided type, which must
+// implement error interface.
+func decodeErrorModel(resp *Response, modelType reflect.Type) error {
+	isPointer := modelType.Kind() == reflect.Pointer
+	if isPointer {
+		modelType = modelType.Elem()
+	}
+
+	model := reflect.New(modelType)
+	if len(resp.Bytes()) > 0 {
+		if err := decodeBody(resp, model.Interface()); err != nil {
+			return fmt.Errorf("failed to decode error response with status %d: %w", resp.StatusCode(), err)
+		}
+	}
+
+	if !isPointer {
+		model = model.Elem()
+	}
+
+	modelErr, ok := model.Interface().(error)
+	if !ok {
+		return fmt.Errorf("error model %s doesn't implement error interface", model.Type())
+	}
+
+	return modelErr
+}
+
+// decodeBody unmarshalls response body with codec registered for its Content-Type,
+// falling back to JSON.
+func decodeBody(resp *Response, p any) error {
+	if codec, err := lookupCodec(resp.Header().Get("Content-Type")); err == nil {
+		return withSentinel(ErrDecodeBody, codec.Unmarshal(resp.Bytes(), p))
+	}
+
+	return withSentinel(ErrDecodeBody, json.Unmarshal(resp.Bytes(), p))
+}
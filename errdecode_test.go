@@ -0,0 +1,235 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAPIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *testAPIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+type testNotFoundError struct {
+	Resource string `json:"resource"`
+}
+
+func (e testNotFoundError) Error() string {
+	return e.Resource + " not found"
+}
+
+func TestErrorDecoding(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte(`{"result":"ok"}`))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"resource":"item"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"invalid_request","message":"bad input"}`))
+		}
+	}))
+	defer ts.Close()
+
+	c := New(
+		WithErrorModel(0, &testAPIError{}),
+		WithErrorModel(http.StatusNotFound, testNotFoundError{}),
+	)
+
+	resp, err := c.Get(context.Background(), ts.URL+"/ok", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("expected status %d, got %d instead", http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = c.Get(context.Background(), ts.URL+"/invalid", nil)
+	var apiErr *testAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *testAPIError, got %T (%v)", err, err)
+	}
+	if apiErr.Code != "invalid_request" || apiErr.Message != "bad input" {
+		t.Errorf("unexpected decoded error %+v", apiErr)
+	}
+	if resp == nil || resp.StatusCode() != http.StatusBadRequest {
+		t.Errorf("expected response with status %d to be returned along with error", http.StatusBadRequest)
+	}
+
+	_, err = c.Get(context.Background(), ts.URL+"/missing", nil)
+	var notFoundErr testNotFoundError
+	if !errors.As(err, &notFoundErr) || notFoundErr.Resource != "item" {
+		t.Fatalf("expected testNotFoundError for item, got %T (%v)", err, err)
+	}
+
+	errCustom := errors.New("custom error")
+	_, err = c.Get(context.Background(), ts.URL+"/missing", nil, WithErrorDecoder(func(resp *Response) error {
+		return errCustom
+	}))
+	if !errors.Is(err, errCustom) {
+		t.Fatalf("expected error decoder to take precedence, got %v", err)
+	}
+
+	_, err = c.Get(context.Background(), ts.URL+"/missing", nil, WithErrorModel(http.StatusNotFound, nil), WithErrorModel(0, nil))
+	if err != nil {
+		t.Fatalf("expected no error after removing error models, got %v", err)
+	}
+}
+
+func TestFailOnStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/problem":
+			w.Header().Set("Content-Type", MediaTypeProblemJSON)
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"title":"Conflict","detail":"item already exists"}`))
+		case "/large":
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(strings.Repeat("x", 2*_responseErrorBodyLimit)))
+		default:
+			w.Header().Set("X-Request-Id", "42")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(_testMsg))
+		}
+	}))
+	defer ts.Close()
+
+	resp, err := New().Get(context.Background(), ts.URL+"/missing", nil)
+	if err != nil {
+		t.Fatalf("expected no error without fail-on-status mode, got %v", err)
+	}
+	if resp.StatusCode() != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d instead", http.StatusNotFound, resp.StatusCode())
+	}
+
+	c := New(WithFailOnStatus())
+
+	_, err = c.Get(context.Background(), ts.URL+"/missing", nil)
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("expected *ResponseError, got %T (%v)", err, err)
+	}
+	if respErr.Code != http.StatusNotFound || respErr.Msg != "404 Not Found" {
+		t.Errorf("expected status 404 Not Found, got %d %q instead", respErr.Code, respErr.Msg)
+	}
+	if respErr.Header.Get("X-Request-Id") != "42" || string(respErr.Body) != _testMsg {
+		t.Errorf("expected headers and body to be kept, got %v and %q", respErr.Header, respErr.Body)
+	}
+
+	_, err = c.Get(context.Background(), ts.URL+"/large", nil)
+	if !errors.As(err, &respErr) || len(respErr.Body) != _responseErrorBodyLimit {
+		t.Errorf("expected body snippet of %d bytes, got %v", _responseErrorBodyLimit, err)
+	}
+
+	_, err = c.Get(context.Background(), ts.URL+"/problem", nil)
+	var problemErr *ProblemError
+	if !errors.As(err, &problemErr) {
+		t.Fatalf("expected *ProblemError, got %T (%v)", err, err)
+	}
+	if problemErr.Problem.Detail != "item already exists" {
+		t.Errorf("expected problem detail %q, got %q instead", "item already exists", problemErr.Problem.Detail)
+	}
+	if !errors.As(err, &respErr) || respErr.Code != http.StatusConflict {
+		t.Errorf("expected problem error to unwrap to *ResponseError, got %v", err)
+	}
+
+	if _, err = c.Get(context.Background(), ts.URL+"/missing", nil, WithFailOnStatus(Is5xx)); err != nil {
+		t.Errorf("expected no error for 404 with 5xx predicate, got %v", err)
+	}
+}
+
+func TestResponseErrorDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(strings.Repeat("x", _responseErrorBodyLimit+1)))
+	}))
+	defer ts.Close()
+
+	c := New(
+		WithFailOnStatus(),
+		WithRetryCount(3),
+		WithRetryDelay(time.Millisecond),
+		WithRetryCondition(func(resp *Response, err error) bool {
+			return err != nil || resp.IsServerError()
+		}),
+	)
+
+	_, err := c.Post(context.Background(), ts.URL+"/items?page=1", nil)
+	respErr, ok := AsResponseError(err)
+	if !ok {
+		t.Fatalf("expected *ResponseError, got %T (%v)", err, err)
+	}
+
+	if respErr.Method != http.MethodPost || respErr.URL != ts.URL+"/items?page=1" {
+		t.Errorf("expected request %s %s, got %s %s instead", http.MethodPost, ts.URL+"/items?page=1", respErr.Method, respErr.URL)
+	}
+	if respErr.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d instead", respErr.Attempts)
+	}
+	if !respErr.Truncated || len(respErr.Body) != _responseErrorBodyLimit {
+		t.Errorf("expected truncated body of %d bytes, got %d bytes (truncated %t)", _responseErrorBodyLimit, len(respErr.Body), respErr.Truncated)
+	}
+
+	expectedPrefix := "POST " + ts.URL + "/items?page=1: unexpected response status 503 Service Unavailable after 3 attempts: xxx"
+	if !strings.HasPrefix(err.Error(), expectedPrefix) || !strings.HasSuffix(err.Error(), "...") {
+		t.Errorf("unexpected error message %q", err.Error())
+	}
+
+	if _, ok = AsResponseError(errors.New(_testMsg)); ok {
+		t.Error("expected no *ResponseError in unrelated error")
+	}
+}
+
+func TestErrorTarget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/ok" {
+			_, _ = w.Write([]byte(`{"name":"item"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"code":"validation","message":"name is required"}`))
+	}))
+	defer ts.Close()
+
+	c := New(WithErrorTarget(func() any { return &testAPIError{} }))
+
+	resp, err := c.Get(context.Background(), ts.URL+"/ok", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.ErrorResult() != nil {
+		t.Errorf("expected no error result for successful response, got %v", resp.ErrorResult())
+	}
+
+	var apiErr testAPIError
+	if err = resp.ErrorJSON(&apiErr); err != nil || apiErr != (testAPIError{}) {
+		t.Errorf("expected ErrorJSON to be no-op for successful response, got %+v (error %v)", apiErr, err)
+	}
+
+	resp, err = c.Get(context.Background(), ts.URL+"/invalid", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	result, ok := resp.ErrorResult().(*testAPIError)
+	if !ok || result.Code != "validation" {
+		t.Errorf("expected decoded *testAPIError, got %#v", resp.ErrorResult())
+	}
+
+	if err = resp.ErrorJSON(&apiErr); err != nil || apiErr.Message != "name is required" {
+		t.Errorf("expected error body to be decoded, got %+v (error %v)", apiErr, err)
+	}
+}
@@ -0,0 +1,172 @@
+package httpr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrTooManyRedirects is returned when request exceeded redirects limit set with WithMaxRedirects.
+	ErrTooManyRedirects = errors.New("too many redirects")
+	// ErrRedirectNotAllowed is returned when redirect is forbidden by redirect policy.
+	ErrRedirectNotAllowed = errors.New("redirect is not allowed")
+	// ErrRetriesExhausted is returned when request failed on every attempt, which was allowed by retry count.
+	ErrRetriesExhausted = errors.New("retries exhausted")
+	// ErrResponseTooLarge is returned when response body exceeds limit set with WithMaxResponseSize.
+	ErrResponseTooLarge = errors.New("response body is too large")
+	// ErrRateLimited matches *ResponseError for responses with status 429 Too Many Requests.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrCircuitOpen is sentinel for user-provided circuit breakers, which return it via PreRequestHookFn
+	// or PreAttemptHookFn when circuit is open, so callers and fallbacks can handle them uniformly.
+	// httpr itself doesn't implement circuit breaker and never returns it.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrDecodeBody is returned when response body can't be decoded. Underlying decoding error
+	// is available with errors.As.
+	ErrDecodeBody = errors.New("failed to decode body")
+	// ErrDisallowedByRobots is returned when request is disallowed by robots.txt rules, see WithRobotsPolicy.
+	ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+	// ErrPathNotFound is returned by response query helpers, when value at provided path doesn't exist.
+	ErrPathNotFound = errors.New("path not found")
+	// ErrCodecNotRegistered is returned, when body must be encoded or decoded with media type,
+	// which has no registered codec.
+	ErrCodecNotRegistered = errors.New("codec is not registered")
+	// ErrSSRFBlocked is returned when request target resolves to internal address, see WithSSRFProtection.
+	ErrSSRFBlocked = errors.New("destination address is blocked")
+	// ErrHostNotAllowed is returned when request or redirect target is forbidden by
+	// WithAllowedHosts or WithBlockedHosts.
+	ErrHostNotAllowed = errors.New("host is not allowed")
+	// ErrInvalidHeader is returned by strict header validation, see WithStrictHeaderValidation.
+	ErrInvalidHeader = errors.New("invalid header")
+	// ErrUnsafeArchive is returned when extracted archive contains entry escaping destination
+	// directory or link, see Response.ExtractTar.
+	ErrUnsafeArchive = errors.New("unsafe archive entry")
+	// ErrArchiveTooLarge is returned when extracted archive exceeds ArchiveLimits.
+	ErrArchiveTooLarge = errors.New("archive is too large")
+)
+
+// sentinelError attaches sentinel error to underlying error, so both can be matched
+// with errors.Is and errors.As.
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+func withSentinel(sentinel, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &sentinelError{sentinel: sentinel, err: err}
+}
+
+// Error implements error interface.
+func (e *sentinelError) Error() string {
+	return e.sentinel.Error() + ": " + e.err.Error()
+}
+
+// Is reports whether target is attached sentinel error.
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel //nolint:errorlint
+}
+
+// Unwrap returns underlying error.
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
+
+// _responseErrorBodyLimit is maximum length of response body snippet kept by ResponseError.
+const _responseErrorBodyLimit = 512
+
+// ResponseError is returned by Client.Do for responses with statuses, which are considered
+// unsuccessful by WithFailOnStatus. Use AsResponseError to extract it from returned error.
+type ResponseError struct {
+	// Code is response status code.
+	Code int
+	// Msg is response status line, e.g. "404 Not Found".
+	Msg string
+	// Header contains response headers.
+	Header http.Header
+	// Body contains beginning of response body, which is truncated to 512 bytes.
+	Body []byte
+	// Truncated reports whether Body was truncated.
+	Truncated bool
+	// Method is method of request, which produced response.
+	Method string
+	// URL is URL of request, which produced response. If redirects were followed,
+	// it is URL of the last request.
+	URL string
+	// Attempts is number of attempts made to execute request including retries.
+	Attempts int
+}
+
+func newResponseError(resp *Response, attempts int) *ResponseError {
+	body := resp.Bytes()
+	truncated := len(body) > _responseErrorBodyLimit
+	if truncated {
+		body = body[:_responseErrorBodyLimit]
+	}
+
+	msg := resp.Raw().Status
+	if msg == "" {
+		msg = fmt.Sprintf("%d %s", resp.StatusCode(), http.StatusText(resp.StatusCode()))
+	}
+
+	respErr := &ResponseError{
+		Code:      resp.StatusCode(),
+		Msg:       msg,
+		Header:    resp.Header(),
+		Body:      append([]byte(nil), body...),
+		Truncated: truncated,
+		Attempts:  attempts,
+	}
+	if req := resp.Raw().Request; req != nil {
+		respErr.Method = req.Method
+		if req.URL != nil {
+			respErr.URL = req.URL.Redacted()
+		}
+	}
+
+	return respErr
+}
+
+// Error implements error interface.
+func (e *ResponseError) Error() string {
+	var sb strings.Builder
+	if e.Method != "" || e.URL != "" {
+		sb.WriteString(strings.TrimSpace(e.Method + " " + e.URL))
+		sb.WriteString(": ")
+	}
+
+	sb.WriteString("unexpected response status ")
+	sb.WriteString(e.Msg)
+	if e.Attempts > 1 {
+		fmt.Fprintf(&sb, " after %d attempts", e.Attempts)
+	}
+
+	if len(e.Body) > 0 {
+		sb.WriteString(": ")
+		sb.Write(e.Body)
+		if e.Truncated {
+			sb.WriteString("...")
+		}
+	}
+
+	return sb.String()
+}
+
+// Is reports whether target is ErrRateLimited and response status is 429 Too Many Requests.
+func (e *ResponseError) Is(target error) bool {
+	return target == ErrRateLimited && e.Code == http.StatusTooManyRequests //nolint:errorlint
+}
+
+// AsResponseError finds first *ResponseError in error chain. It is a shortcut to errors.As.
+func AsResponseError(err error) (*ResponseError, bool) {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr, true
+	}
+
+	return nil, false
+}
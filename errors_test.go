@@ -0,0 +1,83 @@
+package httpr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("x", 1024)))
+		case "/chunked":
+			_, _ = w.Write([]byte(strings.Repeat("x", 512)))
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(strings.Repeat("x", 512)))
+		default:
+			_, _ = w.Write([]byte("{invalid"))
+		}
+	}))
+	defer ts.Close()
+
+	t.Run("ErrRateLimited", func(t *testing.T) {
+		_, err := New(WithFailOnStatus()).Get(context.Background(), ts.URL+"/limited", nil)
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("expected ErrRateLimited, got %v", err)
+		}
+	})
+
+	t.Run("ErrResponseTooLarge", func(t *testing.T) {
+		for _, path := range []string{"/large", "/chunked"} {
+			_, err := New(WithMaxResponseSize(1000)).Get(context.Background(), ts.URL+path, nil, WithRetryCount(1))
+			if !errors.Is(err, ErrResponseTooLarge) {
+				t.Errorf("expected ErrResponseTooLarge for %s, got %v", path, err)
+			}
+		}
+
+		if _, err := New(WithMaxResponseSize(1024)).Get(context.Background(), ts.URL+"/chunked", nil); err != nil {
+			t.Errorf("expected no error for body within limit, got %v", err)
+		}
+	})
+
+	t.Run("ErrDecodeBody", func(t *testing.T) {
+		resp, err := New().Get(context.Background(), ts.URL+"/invalid", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var v map[string]any
+		err = resp.JSON(&v)
+		if !errors.Is(err, ErrDecodeBody) {
+			t.Errorf("expected ErrDecodeBody, got %v", err)
+		}
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("expected underlying *json.SyntaxError, got %v", err)
+		}
+	})
+
+	t.Run("ErrRetriesExhausted", func(t *testing.T) {
+		c := New(WithRetryCount(2), WithRetryDelay(time.Millisecond))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://127.0.0.1:1", nil)
+		_, err := c.Do(req)
+		if !errors.Is(err, ErrRetriesExhausted) {
+			t.Errorf("expected ErrRetriesExhausted, got %v", err)
+		}
+
+		req, _ = http.NewRequestWithContext(context.Background(), http.MethodGet, "http://127.0.0.1:1", nil)
+		_, err = c.Do(req, WithRetryCondition(func(_ *Response, _ error) bool { return false }))
+		if err == nil || errors.Is(err, ErrRetriesExhausted) {
+			t.Errorf("expected error without ErrRetriesExhausted, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package httpr
+
+import (
+	"net/http"
+	"time"
+)
+
+// _requestEventsBuffer is number of events, which channel returned by Client.DoWithEvents buffers
+// before events are dropped. Channel has one more slot reserved for EventDone.
+const _requestEventsBuffer = 64
+
+// RequestEventType is type of request lifecycle event, see Client.DoWithEvents.
+type RequestEventType int
+
+const (
+	// EventQueued is sent once request execution starts, before waiting for rate limiter.
+	EventQueued RequestEventType = iota
+	// EventAttemptStarted is sent before every attempt is sent.
+	EventAttemptStarted
+	// EventHeadersReceived is sent, when response headers of attempt are received.
+	EventHeadersReceived
+	// EventBodyRead is sent, when response body of attempt is read.
+	EventBodyRead
+	// EventRetried is sent, when request is going to be retried after delay.
+	EventRetried
+	// EventDone is the last event, sent once request execution is finished.
+	EventDone
+)
+
+var _requestEventTypeNames = [...]string{"queued", "attempt started", "headers received", "body read", "retried", "done"}
+
+// String returns human-readable name of event type.
+func (t RequestEventType) String() string {
+	if t < 0 || int(t) >= len(_requestEventTypeNames) {
+		return "unknown"
+	}
+	return _requestEventTypeNames[t]
+}
+
+// RequestEvent is request lifecycle event. Fields irrelevant for event type are zero.
+type RequestEvent struct {
+	Type RequestEventType
+	Time time.Time
+	// Attempt is number of attempt, starting with 1. For EventDone it's total number of attempts.
+	Attempt int
+	// StatusCode is status of attempt response or final response.
+	StatusCode int
+	// BodySize is number of response body bytes read.
+	BodySize int64
+	// Reason and Delay describe scheduled retry.
+	Reason RetryReason
+	Delay  time.Duration
+	// Err is error of failed attempt or request.
+	Err error
+}
+
+// DoWithEvents executes request in background, like Client.Do, and returns channel of its lifecycle
+// events along with function, which waits for request completion and returns its result.
+// It's intended for UIs and debuggers, which visualize request progress live:
+//
+//	events, wait := client.DoWithEvents(req)
+//	for event := range events {
+//		log.Printf("%s: attempt %d, status %d", event.Type, event.Attempt, event.StatusCode)
+//	}
+//	resp, err := wait()
+//
+// Channel is closed after EventDone, which is always delivered. Request is never blocked by slow consumer:
+// other events are dropped, if consumer falls behind by more than 64 events.
+func (c *Client) DoWithEvents(req *http.Request, opts ...Option) (<-chan RequestEvent, func() (*Response, error)) {
+	var (
+		stream = &eventStream{ch: make(chan RequestEvent, _requestEventsBuffer+1)}
+		done   = make(chan struct{})
+		resp   *Response
+		err    error
+	)
+
+	go func() {
+		defer close(done)
+		defer close(stream.ch)
+
+		settings := c.resolveSettings(req, opts)
+		settings.events = stream
+		resp, err = c.do(req, settings, true, nil)
+	}()
+
+	return stream.ch, func() (*Response, error) {
+		<-done
+		return resp, err
+	}
+}
+
+// eventStream delivers lifecycle events of single request.
+type eventStream struct {
+	ch chan RequestEvent
+	// attempt is number of current attempt, assigned to attempt events.
+	attempt int
+}
+
+// emit sends event without blocking. Events are dropped, when buffer is full, except EventDone,
+// which is sent to reserved slot: events are emitted by single goroutine and EventDone is the last
+// one, so the slot is always free. Nil stream ignores events.
+func (s *eventStream) emit(settings clientSettings, event RequestEvent) {
+	if s == nil {
+		return
+	}
+	if event.Attempt == 0 {
+		event.Attempt = s.attempt
+	}
+	event.Time = settings.clock().Now()
+
+	if event.Type != EventDone && len(s.ch) >= _requestEventsBuffer {
+		return
+	}
+	s.ch <- event
+}
@@ -0,0 +1,104 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientDoWithEvents(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := New(WithRetryCount(3), WithRetryDelay(time.Millisecond))
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	events, wait := client.DoWithEvents(req)
+
+	var collected []RequestEvent
+	for event := range events {
+		collected = append(collected, event)
+	}
+	resp, err := wait()
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+	if resp.String() != "ok" {
+		t.Fatalf("expected body %q, got %q instead", "ok", resp.String())
+	}
+
+	types := make([]RequestEventType, len(collected))
+	for i, event := range collected {
+		types[i] = event.Type
+	}
+	expected := []RequestEventType{
+		EventQueued,
+		EventAttemptStarted, EventHeadersReceived, EventBodyRead, EventRetried,
+		EventAttemptStarted, EventHeadersReceived, EventBodyRead,
+		EventDone,
+	}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("expected events %v, got %v instead", expected, types)
+	}
+
+	if retried := collected[4]; retried.Attempt != 1 || retried.StatusCode != http.StatusServiceUnavailable ||
+		retried.Reason != RetryByCondition || retried.Delay <= 0 {
+		t.Fatalf("unexpected retry event: %+v", retried)
+	}
+	if bodyRead := collected[7]; bodyRead.Attempt != 2 || bodyRead.BodySize != 2 {
+		t.Fatalf("unexpected body read event: %+v", bodyRead)
+	}
+	if done := collected[8]; done.Attempt != 2 || done.StatusCode != http.StatusOK || done.Err != nil {
+		t.Fatalf("unexpected done event: %+v", done)
+	}
+}
+
+func TestClientDoWithEventsError(t *testing.T) {
+	client := New(WithRetryCount(1))
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://127.0.0.1:1", nil)
+	events, wait := client.DoWithEvents(req)
+
+	var last RequestEvent
+	for event := range events {
+		last = event
+	}
+	if _, err := wait(); err == nil {
+		t.Fatal("expected request error")
+	}
+	if last.Type != EventDone || last.Err == nil {
+		t.Fatalf("expected done event with error, got %+v instead", last)
+	}
+	if last.Type.String() != "done" {
+		t.Fatalf("expected event name %q, got %q instead", "done", last.Type.String())
+	}
+}
+
+func TestEventStreamDeliversDone(t *testing.T) {
+	stream := &eventStream{ch: make(chan RequestEvent, _requestEventsBuffer+1)}
+	for i := 0; i < 2*_requestEventsBuffer; i++ {
+		stream.emit(clientSettings{}, RequestEvent{Type: EventAttemptStarted})
+	}
+	stream.emit(clientSettings{}, RequestEvent{Type: EventDone})
+	close(stream.ch)
+
+	var events []RequestEvent
+	for event := range stream.ch {
+		events = append(events, event)
+	}
+	if len(events) != _requestEventsBuffer+1 {
+		t.Fatalf("expected %d events, got %d instead", _requestEventsBuffer+1, len(events))
+	}
+	if last := events[len(events)-1]; last.Type != EventDone {
+		t.Fatalf("expected last event to be done, got %s instead", last.Type)
+	}
+}
@@ -0,0 +1,62 @@
+package httpr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// FallbackFunc returns response, which replaces error of failed request, see WithFallback.
+// Returning non-nil error makes request fail with it; original error can be returned as is.
+type FallbackFunc func(req *http.Request, err error) (*Response, error)
+
+// WithFallback sets function, which is called when request fails, i.e. all attempts failed, final
+// response is considered unsuccessful (see WithFailOnStatus) or request wasn't sent because of
+// hook error, e.g. ErrCircuitOpen returned by user-provided circuit breaker. Fallback can return
+// default payload, cached data or degraded-mode answer instead of error:
+//
+//	httpr.WithFallback(func(req *http.Request, err error) (*httpr.Response, error) {
+//		if errors.Is(err, context.Canceled) {
+//			return nil, err
+//		}
+//		return httpr.NewResponse(http.StatusOK, nil, []byte(`{"items":[]}`)), nil
+//	})
+//
+// Use Response.IsFallback to tell fallback responses apart.
+func WithFallback(fallbackFn FallbackFunc) Option {
+	return func(settings *clientSettings) {
+		settings.fallbackFn = fallbackFn
+	}
+}
+
+// applyFallback replaces error of failed request with fallback response. If dst is provided
+// (see Client.DoInto), fallback response is copied into it.
+func applyFallback(req *http.Request, settings clientSettings, resp *Response, err error, dst *Response) (*Response, error) {
+	if settings.fallbackFn == nil || err == nil {
+		return resp, err
+	}
+
+	fallback, fallbackErr := settings.fallbackFn(req, err)
+	if fallbackErr != nil {
+		return resp, fallbackErr
+	}
+	if fallback == nil || fallback.rawResp == nil {
+		return resp, errors.New("fallback returned no response")
+	}
+
+	result := dst
+	if result == nil {
+		result = new(Response)
+	}
+	body := append(result.reset(), fallback.body...)
+	*result = *fallback
+	result.body = body
+	if fallback.rawResp.Request == nil {
+		// Fallback response may be shared between requests, so original request is set on its copy.
+		rawResp := *fallback.rawResp
+		rawResp.Request = req
+		result.rawResp = &rawResp
+	}
+	result.fallback = true
+
+	return result, nil
+}
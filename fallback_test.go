@@ -0,0 +1,112 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	defaultBody := []byte(`{"items":[]}`)
+	var fallbackErr error
+	fallback := WithFallback(func(_ *http.Request, err error) (*Response, error) {
+		fallbackErr = err
+		return NewResponse(http.StatusOK, http.Header{"Content-Type": {"application/json"}}, defaultBody), nil
+	})
+
+	tests := []struct {
+		name        string
+		url         string
+		opts        []Option
+		expectedErr error
+	}{
+		{
+			name: "TransportError",
+			url:  "http://127.0.0.1:1",
+			opts: []Option{WithRetryCount(2), WithRetryDelay(0)},
+		},
+		{
+			name: "FailOnStatus",
+			url:  server.URL,
+			opts: []Option{WithFailOnStatus(Is5xx)},
+		},
+		{
+			name: "CircuitOpen",
+			url:  server.URL,
+			opts: []Option{WithPreRequestHook(func(*http.Request) error {
+				return ErrCircuitOpen
+			})},
+			expectedErr: ErrCircuitOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallbackErr = nil
+			client := New(append(tt.opts, fallback)...)
+
+			resp, err := client.Get(context.Background(), tt.url, nil)
+			if err != nil {
+				t.Fatalf("expected fallback response, got error: %v", err)
+			}
+			if !resp.IsFallback() || resp.StatusCode() != http.StatusOK || resp.String() != string(defaultBody) {
+				t.Fatalf("expected fallback response, got %d %q", resp.StatusCode(), resp.String())
+			}
+			if resp.RequestURL() != tt.url {
+				t.Fatalf("expected request URL %q, got %q instead", tt.url, resp.RequestURL())
+			}
+			if fallbackErr == nil || (tt.expectedErr != nil && !errors.Is(fallbackErr, tt.expectedErr)) {
+				t.Fatalf("expected fallback to receive request error, got %v", fallbackErr)
+			}
+		})
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		resp, err := New(fallback).Get(context.Background(), server.URL, nil)
+		if err != nil || resp.IsFallback() || resp.StatusCode() != http.StatusBadGateway {
+			t.Fatalf("expected fallback not to replace response without error, got %d, %v", resp.StatusCode(), err)
+		}
+	})
+
+	t.Run("DoInto", func(t *testing.T) {
+		client := New(WithFailOnStatus(Is5xx), fallback)
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+
+		resp := AcquireResponse()
+		defer resp.Release()
+		if err := client.DoInto(req, resp); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.IsFallback() || resp.String() != string(defaultBody) {
+			t.Fatalf("expected fallback response to be copied, got %q", resp.String())
+		}
+	})
+
+	t.Run("FallbackError", func(t *testing.T) {
+		errDegraded := errors.New("degraded")
+		client := New(WithFailOnStatus(Is5xx), WithFallback(func(*http.Request, error) (*Response, error) {
+			return nil, errDegraded
+		}))
+
+		if _, err := client.Get(context.Background(), server.URL, nil); !errors.Is(err, errDegraded) {
+			t.Fatalf("expected fallback error, got %v instead", err)
+		}
+	})
+
+	t.Run("NoResponse", func(t *testing.T) {
+		client := New(WithFailOnStatus(Is5xx), WithFallback(func(*http.Request, error) (*Response, error) {
+			return nil, nil
+		}))
+
+		if _, err := client.Get(context.Background(), server.URL, nil); err == nil {
+			t.Fatal("expected error, when fallback returns no response")
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package httpr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// bodyProvider is implemented by request bodies, which are opened lazily on request
+// execution and can be reopened on retries.
+type bodyProvider interface {
+	// prepare returns body length and its content type.
+	prepare() (int64, string, error)
+	// open returns new body reader.
+	open() io.ReadCloser
+}
+
+// attachBody sets body created by provider to request. Content-Type header is set
+// only if detection is enabled.
+func attachBody(req *http.Request, provider bodyProvider, setContentType bool) error {
+	length, contentType, err := provider.prepare()
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = length
+	req.Body = provider.open()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return provider.open(), nil
+	}
+
+	if setContentType && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return nil
+}
+
+// fileBody is request body read from file.
+type fileBody struct {
+	path string
+}
+
+func (b fileBody) prepare() (int64, string, error) {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to stat body file: %w", err)
+	}
+
+	contentType, err := detectFileContentType(b.path)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return info.Size(), contentType, nil
+}
+
+func (b fileBody) open() io.ReadCloser {
+	return &lazyFile{path: b.path}
+}
+
+// lazyFile opens file on first Read call, so file descriptors aren't held by
+// requests, which are built but not executed yet.
+type lazyFile struct {
+	path string
+	file *os.File
+	err  error
+}
+
+func (f *lazyFile) Read(p []byte) (int, error) {
+	if f.file == nil && f.err == nil {
+		f.file, f.err = os.Open(f.path)
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	return f.file.Read(p)
+}
+
+func (f *lazyFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	return f.file.Close()
+}
+
+// detectFileContentType detects content type of file by its extension, falling back
+// to content sniffing with http.DetectContentType.
+func detectFileContentType(path string) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open body file: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read body file: %w", err)
+	}
+
+	return http.DetectContentType(header[:n]), nil
+}
+
+// detectBodyContentType detects content type of raw []byte and *os.File bodies.
+// Empty string is returned for other body types or if detection failed.
+func detectBodyContentType(body any) string {
+	switch b := body.(type) {
+	case []byte:
+		if len(b) == 0 {
+			return ""
+		}
+		return http.DetectContentType(b)
+	case *os.File:
+		if contentType := mime.TypeByExtension(filepath.Ext(b.Name())); contentType != "" {
+			return contentType
+		}
+
+		header := make([]byte, 512)
+		n, err := b.ReadAt(header, 0)
+		if n == 0 || (err != nil && !errors.Is(err, io.EOF)) {
+			return ""
+		}
+		return http.DetectContentType(header[:n])
+	default:
+		return ""
+	}
+}
+
+// multipartBody is "multipart/form-data" request body consisting of form fields and files.
+// Files are streamed from disk, so whole body is never held in memory.
+type multipartBody struct {
+	fields   map[string]string
+	files    map[string]string
+	segments []func() io.ReadCloser
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (b *multipartBody) prepare() (int64, string, error) {
+	var (
+		buf    bytes.Buffer
+		w      = multipart.NewWriter(&buf)
+		length int64
+	)
+
+	b.segments = nil
+	flush := func() {
+		data := append([]byte(nil), buf.Bytes()...)
+		length += int64(len(data))
+		b.segments = append(b.segments, func() io.ReadCloser {
+			return io.NopCloser(bytes.NewReader(data))
+		})
+		buf.Reset()
+	}
+
+	for _, key := range sortedKeys(b.fields) {
+		if err := w.WriteField(key, b.fields[key]); err != nil {
+			return 0, "", err
+		}
+	}
+
+	for _, field := range sortedKeys(b.files) {
+		path := b.files[field]
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to stat multipart file: %w", err)
+		}
+		contentType, err := detectFileContentType(path)
+		if err != nil {
+			return 0, "", err
+		}
+
+		partHeader := make(textproto.MIMEHeader)
+		partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(field), quoteEscaper.Replace(filepath.Base(path))))
+		partHeader.Set("Content-Type", contentType)
+		if _, err = w.CreatePart(partHeader); err != nil {
+			return 0, "", err
+		}
+
+		flush()
+		length += info.Size()
+		b.segments = append(b.segments, fileBody{path: path}.open)
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, "", err
+	}
+	flush()
+
+	return length, w.FormDataContentType(), nil
+}
+
+func (b *multipartBody) open() io.ReadCloser {
+	readers := make([]io.Reader, 0, len(b.segments))
+	closers := make([]io.Closer, 0, len(b.segments))
+	for _, segment := range b.segments {
+		rc := segment()
+		readers = append(readers, rc)
+		closers = append(closers, rc)
+	}
+
+	return &multiCloseBody{Reader: io.MultiReader(readers...), closers: closers}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
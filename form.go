@@ -0,0 +1,96 @@
+package httpr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// encodeFormStruct converts struct fields to form values. Field names are taken from "form"
+// struct tags, falling back to field names. Tag option "omitempty" skips zero values,
+// tag "-" skips field entirely. Slice fields produce multiple values with the same key.
+func encodeFormStruct(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("form data struct is nil")
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("form data must be a struct, got %s", rv.Kind())
+	}
+
+	values := make(url.Values)
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldValue := rv.Field(i)
+		if opts == "omitempty" && fieldValue.IsZero() {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Slice || fieldValue.Kind() == reflect.Array {
+			for j := 0; j < fieldValue.Len(); j++ {
+				formValue, err := formatFormValue(fieldValue.Index(j))
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", field.Name, err)
+				}
+				values.Add(name, formValue)
+			}
+			continue
+		}
+
+		formValue, err := formatFormValue(fieldValue)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		values.Add(name, formValue)
+	}
+
+	return values, nil
+}
+
+func formatFormValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported form value type %s", v.Type())
+	}
+}
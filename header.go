@@ -0,0 +1,79 @@
+package httpr
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// _hopByHopHeaders are headers, which describe single connection rather than request itself.
+// They are managed by transport and must not be set by user code in strict header validation mode.
+var _hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// validateHeaders checks that request header names are valid tokens, values (including Host) don't contain
+// CR, LF or other control characters except horizontal tab and no hop-by-hop headers are set.
+// Returned error wraps ErrInvalidHeader.
+func validateHeaders(req *http.Request) error {
+	if !isValidHeaderValue(req.Host) {
+		return fmt.Errorf("%w: invalid host %q", ErrInvalidHeader, req.Host)
+	}
+
+	header := req.Header
+	for _, key := range sortedKeys(header) {
+		if !isValidHeaderName(key) {
+			return fmt.Errorf("%w: invalid header name %q", ErrInvalidHeader, key)
+		}
+
+		canonicalKey := http.CanonicalHeaderKey(key)
+		for _, hopByHop := range _hopByHopHeaders {
+			if canonicalKey == hopByHop {
+				return fmt.Errorf("%w: hop-by-hop header %q must not be set", ErrInvalidHeader, canonicalKey)
+			}
+		}
+
+		for _, value := range header[key] {
+			if !isValidHeaderValue(value) {
+				return fmt.Errorf("%w: invalid value %q of header %q", ErrInvalidHeader, value, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isValidHeaderName reports whether name is non-empty token as defined by RFC 9110.
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c < 0x7f && c > ' ' && strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) == -1:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isValidHeaderValue reports whether value contains only visible characters, spaces, horizontal tabs
+// and obs-text bytes, so it can't break request framing.
+func isValidHeaderValue(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; (c < ' ' && c != '\t') || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
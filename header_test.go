@@ -0,0 +1,88 @@
+package httpr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateHeaders(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "valid", key: "X-Request-ID", value: "abc\t123"},
+		{name: "obs-text", key: "X-Name", value: "caf\xc3\xa9"},
+		{name: "token characters", key: "X-Custom_Header.v1!", value: "1"},
+		{name: "CRLF in value", key: "X-Injected", value: "a\r\nSet-Cookie: b", wantErr: true},
+		{name: "LF in value", key: "X-Injected", value: "a\nb", wantErr: true},
+		{name: "NUL in value", key: "X-Injected", value: "a\x00b", wantErr: true},
+		{name: "space in name", key: "X Bad", value: "1", wantErr: true},
+		{name: "colon in name", key: "X-Bad:", value: "1", wantErr: true},
+		{name: "empty name", key: "", value: "1", wantErr: true},
+		{name: "connection", key: "Connection", value: "close", wantErr: true},
+		{name: "transfer encoding", key: "transfer-encoding", value: "chunked", wantErr: true},
+		{name: "upgrade", key: "Upgrade", value: "websocket", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+			req.Header[tc.key] = []string{tc.value}
+
+			err := validateHeaders(req)
+			if tc.wantErr && !errors.Is(err, ErrInvalidHeader) {
+				t.Errorf("expected ErrInvalidHeader, got %v instead", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestStrictHeaderValidation(t *testing.T) {
+	t.Run("Build", func(t *testing.T) {
+		_, err := NewRequest().
+			SetURL("https://example.com").
+			SetHeader("X-Injected", "a\r\nHost: evil.com").
+			SetStrictHeaderValidation(true).
+			Build()
+		if !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("expected ErrInvalidHeader, got %v instead", err)
+		}
+
+		_, err = NewRequest().
+			SetURL("https://example.com").
+			SetHostHeader("example.com\r\nX: y").
+			SetStrictHeaderValidation(true).
+			Build()
+		if !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("expected ErrInvalidHeader for host, got %v instead", err)
+		}
+	})
+
+	t.Run("Do", func(t *testing.T) {
+		var called bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = true
+		}))
+		defer ts.Close()
+
+		client := New(WithStrictHeaderValidation(), WithPreRequestHook(func(req *http.Request) error {
+			req.Header.Set("Keep-Alive", "timeout=5")
+			return nil
+		}))
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		if _, err := client.Do(req); !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("expected ErrInvalidHeader, got %v instead", err)
+		}
+		if called {
+			t.Error("expected request not to be sent")
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AttemptInfo describes current attempt of request execution, see WithPreAttemptHook, WithPostAttemptHook
+// and WithAttemptRetryCondition.
+type AttemptInfo struct {
+	// Attempt is number of current attempt, starting with 1. Attempts retried because of
+	// throttling (see WithAutoRateLimitRetry) are counted as well.
+	Attempt int
+	// Remaining is number of attempts left within retry count. Retries forced by hooks
+	// and throttling aren't limited by it.
+	Remaining int
+	// Elapsed is time passed since request execution started, including delays between attempts.
+	Elapsed time.Duration
+	// Settings is snapshot of settings effective for request.
+	Settings SettingsSnapshot
+}
+
+// SettingsSnapshot is read-only view of settings effective for request, i.e. client settings
+// with route policies and request-scoped options applied. Modifying it has no effect.
+type SettingsSnapshot struct {
+	Timeout               time.Duration
+	TotalTimeout          time.Duration
+	ResponseHeaderTimeout time.Duration
+	RetryCount            int
+	RetryDelay            time.Duration
+	RetryDelayDelta       time.Duration
+	RetryBackoff          float64
+	RetryMaxDelay         time.Duration
+	RateLimitMaxWait      time.Duration
+	ExpectContinueTimeout time.Duration
+	MaxResponseSize       int64
+	BodySpillThreshold    int64
+	DecompressionEnabled  bool
+	RobotsPolicy          RobotsPolicy
+	SSRFProtection        bool
+	IPPreference          IPPreference
+	DialFallbackDelay     time.Duration
+	StrictHeaders         bool
+	HostOverride          string
+}
+
+// AttemptRetryConditionFunc is function, used for specifying whether request execution must be
+// attempted again. Unlike RetryConditionFunc, it receives info of current attempt, including
+// remaining attempts and effective settings, so adaptive retry logic can be implemented.
+type AttemptRetryConditionFunc func(resp *Response, err error, info AttemptInfo) bool
+
+// snapshot returns read-only view of settings.
+func (settings clientSettings) snapshot() SettingsSnapshot {
+	return SettingsSnapshot{
+		Timeout:               settings.timeout,
+		TotalTimeout:          settings.totalTimeout,
+		ResponseHeaderTimeout: settings.responseHeaderTimeout,
+		RetryCount:            settings.retryCount,
+		RetryDelay:            settings.retryDelay,
+		RetryDelayDelta:       settings.retryDelayDelta,
+		RetryBackoff:          settings.retryBackoffMultiplier,
+		RetryMaxDelay:         settings.retryMaxDelay,
+		RateLimitMaxWait:      settings.rateLimitMaxWait,
+		ExpectContinueTimeout: settings.expectContinueTimeout,
+		MaxResponseSize:       settings.maxResponseSize,
+		BodySpillThreshold:    settings.bodySpillThreshold,
+		DecompressionEnabled:  settings.decompressionEnabled,
+		RobotsPolicy:          settings.robotsPolicy,
+		SSRFProtection:        settings.ssrfProtection,
+		IPPreference:          settings.ipPreference,
+		DialFallbackDelay:     settings.dialFallbackDelay,
+		StrictHeaders:         settings.strictHeaders,
+		HostOverride:          settings.hostOverride,
+	}
+}
+
+// PreAttemptHookFn is function, which is called before every attempt of request execution, including retries.
+// Request can be modified, e.g. to refresh authorization header. If attempt must not take place,
+// PreAttemptHookFn must return non-nil error, which is then returned by Client.Do.
+type PreAttemptHookFn func(ctx context.Context, req *http.Request, info AttemptInfo) error
+
+// PostAttemptHookFn is function, which is called after every attempt of request execution with its result.
+// Returned directive can replace attempt result and override retry decision, e.g. to refresh
+// expired token and retry once:
+//
+//	httpr.WithPostAttemptHook(func(ctx context.Context, req *http.Request, resp *httpr.Response, err error, info httpr.AttemptInfo) httpr.AttemptDirective {
+//		if err != nil || resp.StatusCode() != http.StatusUnauthorized || info.Attempt > 1 {
+//			return httpr.AttemptDirective{}
+//		}
+//		token, err := refreshToken(ctx)
+//		if err != nil {
+//			return httpr.AttemptDirective{Action: httpr.AttemptStop, Err: err}
+//		}
+//		req.Header.Set("Authorization", "Bearer "+token)
+//		return httpr.AttemptDirective{Action: httpr.AttemptRetry}
+//	})
+type PostAttemptHookFn func(ctx context.Context, req *http.Request, resp *Response, err error, info AttemptInfo) AttemptDirective
+
+// _maxForcedRetries limits number of retries forced by post-attempt hooks beyond retry count,
+// so misbehaving hook can't retry request forever.
+const _maxForcedRetries = 5
+
+// AttemptAction tells client, how to proceed after attempt, see AttemptDirective.
+type AttemptAction int
+
+const (
+	// AttemptContinue leaves retry decision to retry condition and throttling settings.
+	AttemptContinue AttemptAction = iota
+	// AttemptRetry makes client retry request immediately, regardless of retry condition and retry
+	// count. Number of such forced retries is limited.
+	AttemptRetry
+	// AttemptStop makes client return attempt result without further retries.
+	AttemptStop
+)
+
+// AttemptDirective is returned by PostAttemptHookFn to influence request execution. Zero value
+// keeps attempt result and leaves retry decision to client.
+type AttemptDirective struct {
+	Action AttemptAction
+	// Response replaces attempt response, if set. Attempt error is replaced with Err in this case,
+	// so failed attempt can be turned into successful one, e.g. with cached response.
+	Response *Response
+	// Err replaces attempt error, if set. Replaced error is passed to retry condition.
+	Err error
+}
+
+// apply replaces attempt result according to directive.
+func (d AttemptDirective) apply(resp *Response, err error, readBody bool) (*Response, error) {
+	if d.Response != nil {
+		if d.Response != resp {
+			discardBody(resp, readBody)
+		}
+		return d.Response, d.Err
+	}
+	if d.Err != nil {
+		return resp, d.Err
+	}
+	return resp, err
+}
+
+// attemptInfo returns info of current attempt.
+func attemptInfo(settings clientSettings, attempt, remaining int, start time.Time) AttemptInfo {
+	return AttemptInfo{
+		Attempt:   attempt,
+		Remaining: remaining,
+		Elapsed:   settings.clock().Now().Sub(start),
+		Settings:  settings.snapshot(),
+	}
+}
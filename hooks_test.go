@@ -0,0 +1,244 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAttemptHooks(t *testing.T) {
+	var tokens []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tokens = append(tokens, req.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	var (
+		clock = NewFakeClock(time.Unix(0, 0))
+		pre   []AttemptInfo
+		post  []AttemptInfo
+	)
+	client := New(
+		WithClock(clock),
+		WithRetryCount(3),
+		WithRetryDelay(time.Second),
+		WithPreAttemptHook(func(ctx context.Context, req *http.Request, info AttemptInfo) error {
+			if ctx != req.Context() {
+				t.Error("expected request context to be passed to hook")
+			}
+			pre = append(pre, info)
+			req.Header.Set("Authorization", "Bearer token-"+strconv.Itoa(info.Attempt))
+			return nil
+		}),
+		WithPostAttemptHook(func(_ context.Context, _ *http.Request, resp *Response, err error, info AttemptInfo) AttemptDirective {
+			if err != nil || resp.StatusCode() != http.StatusServiceUnavailable {
+				t.Errorf("expected 503 response, got %v (%v) instead", resp.StatusCode(), err)
+			}
+			post = append(post, info)
+			return AttemptDirective{}
+		}),
+	)
+
+	if _, err := client.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	snapshot := SettingsSnapshot{RetryCount: 3, RetryDelay: time.Second}
+	expected := []AttemptInfo{
+		{Attempt: 1, Remaining: 2, Elapsed: 0, Settings: snapshot},
+		{Attempt: 2, Remaining: 1, Elapsed: time.Second, Settings: snapshot},
+		{Attempt: 3, Remaining: 0, Elapsed: 2 * time.Second, Settings: snapshot},
+	}
+	for _, infos := range [][]AttemptInfo{pre, post} {
+		if len(infos) != len(expected) {
+			t.Fatalf("expected %d hook calls, got %d instead", len(expected), len(infos))
+		}
+		for i := range expected {
+			if infos[i] != expected[i] {
+				t.Errorf("expected attempt info %+v, got %+v instead", expected[i], infos[i])
+			}
+		}
+	}
+
+	for i, token := range tokens {
+		if expected := "Bearer token-" + strconv.Itoa(i+1); token != expected {
+			t.Errorf("expected refreshed header '%s', got '%s' instead", expected, token)
+		}
+	}
+}
+
+func TestPreAttemptHookError(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	errDeadline := errors.New("not enough time left")
+	client := New(
+		WithClock(NewFakeClock(time.Unix(0, 0))),
+		WithRetryCount(5),
+		WithRetryDelay(time.Second),
+		WithPreAttemptHook(func(_ context.Context, _ *http.Request, info AttemptInfo) error {
+			if info.Elapsed >= time.Second {
+				return errDeadline
+			}
+			return nil
+		}),
+	)
+
+	if _, err := client.Get(context.Background(), ts.URL, nil); !errors.Is(err, errDeadline) {
+		t.Errorf("expected hook error, got %v instead", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 request, got %d instead", calls)
+	}
+}
+
+func TestPostAttemptHookDirectives(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/protected" && req.Header.Get("Authorization") != "Bearer fresh":
+			w.WriteHeader(http.StatusUnauthorized)
+		case req.URL.Path == "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	errRefresh := errors.New("refresh failed")
+	cached := &Response{rawResp: &http.Response{StatusCode: http.StatusOK}, body: []byte("cached")}
+
+	testCases := []struct {
+		name          string
+		path          string
+		opts          []Option
+		hook          PostAttemptHookFn
+		expectedCalls int
+		expectedCode  int
+		expectedBody  string
+		expectedErr   error
+	}{
+		{
+			name: "retry after token refresh",
+			path: "/protected",
+			hook: func(_ context.Context, req *http.Request, resp *Response, err error, info AttemptInfo) AttemptDirective {
+				if err == nil && resp.StatusCode() == http.StatusUnauthorized && info.Attempt == 1 {
+					req.Header.Set("Authorization", "Bearer fresh")
+					return AttemptDirective{Action: AttemptRetry}
+				}
+				return AttemptDirective{}
+			},
+			expectedCalls: 2,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "forced retries are limited",
+			path: "/protected",
+			hook: func(_ context.Context, _ *http.Request, _ *Response, _ error, _ AttemptInfo) AttemptDirective {
+				return AttemptDirective{Action: AttemptRetry}
+			},
+			expectedCalls: _maxForcedRetries + 1,
+			expectedCode:  http.StatusUnauthorized,
+		},
+		{
+			name: "stop with error",
+			path: "/unavailable",
+			opts: []Option{WithRetryCount(5)},
+			hook: func(_ context.Context, _ *http.Request, _ *Response, _ error, _ AttemptInfo) AttemptDirective {
+				return AttemptDirective{Action: AttemptStop, Err: errRefresh}
+			},
+			expectedCalls: 1,
+			expectedErr:   errRefresh,
+		},
+		{
+			name: "replace response",
+			path: "/unavailable",
+			opts: []Option{WithRetryCount(5), WithRetryCondition(func(resp *Response, err error) bool {
+				return err != nil || resp.IsServerError()
+			})},
+			hook: func(_ context.Context, _ *http.Request, resp *Response, _ error, _ AttemptInfo) AttemptDirective {
+				if resp.IsServerError() {
+					return AttemptDirective{Response: cached}
+				}
+				return AttemptDirective{}
+			},
+			expectedCalls: 1,
+			expectedCode:  http.StatusOK,
+			expectedBody:  "cached",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			client := New(append(tc.opts,
+				WithRetryDelay(0),
+				WithPreAttemptHook(func(context.Context, *http.Request, AttemptInfo) error {
+					calls++
+					return nil
+				}),
+				WithPostAttemptHook(tc.hook),
+			)...)
+
+			resp, err := client.Get(context.Background(), ts.URL+tc.path, nil)
+			if calls != tc.expectedCalls {
+				t.Errorf("expected %d attempts, got %d instead", tc.expectedCalls, calls)
+			}
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Errorf("expected error %v, got %v instead", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if resp.StatusCode() != tc.expectedCode {
+				t.Errorf("expected status %d, got %d instead", tc.expectedCode, resp.StatusCode())
+			}
+			if resp.String() != tc.expectedBody {
+				t.Errorf("expected body '%s', got '%s' instead", tc.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestAttemptRetryCondition(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	var infos []AttemptInfo
+	client := New(
+		WithRetryCount(5),
+		WithRetryDelay(0),
+		WithAttemptRetryCondition(func(resp *Response, err error, info AttemptInfo) bool {
+			infos = append(infos, info)
+			// Give up early, when less than half of retry budget is left.
+			return resp.IsServerError() && info.Remaining*2 >= info.Settings.RetryCount
+		}),
+	)
+
+	if _, err := client.Get(context.Background(), ts.URL, nil, WithMaxResponseSize(1024)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d instead", calls)
+	}
+	for _, info := range infos {
+		if info.Settings.RetryCount != 5 || info.Settings.MaxResponseSize != 1024 {
+			t.Errorf("expected effective settings in snapshot, got %+v instead", info.Settings)
+		}
+	}
+}
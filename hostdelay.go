@@ -0,0 +1,93 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostDelayer enforces minimal interval between requests to the same host.
+type hostDelayer struct {
+	defaultDelay time.Duration
+	overrides    map[string]time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostDelayer(defaultDelay time.Duration, overrides map[string]time.Duration) *hostDelayer {
+	d := &hostDelayer{
+		defaultDelay: defaultDelay,
+		overrides:    make(map[string]time.Duration, len(overrides)),
+		next:         make(map[string]time.Time),
+	}
+	for host, delay := range overrides {
+		d.overrides[strings.ToLower(host)] = delay
+	}
+
+	return d
+}
+
+// delay returns configured interval for host. Crawl-delay takes precedence, if it is longer.
+func (d *hostDelayer) delay(host string, crawlDelay time.Duration) time.Duration {
+	delay, ok := d.overrides[host]
+	if !ok {
+		delay = d.defaultDelay
+	}
+	if crawlDelay > delay {
+		delay = crawlDelay
+	}
+
+	return delay
+}
+
+// wait reserves time slot for request to host and blocks until it comes according to clock
+// or context is done.
+func (d *hostDelayer) wait(ctx context.Context, clock Clock, host string, crawlDelay time.Duration) error {
+	delay := d.delay(host, crawlDelay)
+	if delay <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	now := clock.Now()
+	slot := d.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	d.next[host] = slot.Add(delay)
+	d.mu.Unlock()
+
+	wait := slot.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	return sleepContext(ctx, clock, wait)
+}
+
+// waitHostDelay blocks until request to its host is allowed by per-host delay settings
+// and Crawl-delay of robots.txt.
+func waitHostDelay(req *http.Request, settings clientSettings) error {
+	var (
+		delayer    = settings.hostDelayer
+		crawlDelay time.Duration
+	)
+
+	if settings.robotsPolicy == RobotsRespect && settings.robotsCache != nil {
+		if robots, ok := settings.robotsCache.lookup(req.URL); ok {
+			crawlDelay = robots.crawlDelay(robotsUserAgent(req))
+		}
+		if delayer == nil {
+			delayer = settings.robotsCache.delayer
+		}
+	}
+
+	if delayer == nil {
+		return nil
+	}
+
+	return delayer.wait(req.Context(), settings.clock(), strings.ToLower(req.URL.Hostname()), crawlDelay)
+}
@@ -0,0 +1,72 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPerHostDelay(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	const delay = 50 * time.Millisecond
+	c := New(WithPerHostDelay(time.Hour, map[string]time.Duration{"127.0.0.1": delay}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Get(context.Background(), ts.URL, nil); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(times) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(times))
+	}
+	if elapsed := times[2].Sub(times[0]); elapsed < 2*delay-5*time.Millisecond {
+		t.Errorf("expected requests to be spread by %v, got %v between first and last", delay, elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Get(ctx, ts.URL, nil); err == nil {
+		t.Error("expected error for canceled context, got nil")
+	}
+}
+
+func TestHostDelayerCrawlDelay(t *testing.T) {
+	d := newHostDelayer(time.Second, map[string]time.Duration{"fast.test.com": 0})
+
+	if delay := d.delay("fast.test.com", 2*time.Second); delay != 2*time.Second {
+		t.Errorf("expected crawl delay to take precedence, got %v", delay)
+	}
+	if delay := d.delay("slow.test.com", 500*time.Millisecond); delay != time.Second {
+		t.Errorf("expected default delay, got %v", delay)
+	}
+
+	cache := newRobotsCache()
+	u, _ := url.Parse("http://robots.test.com/page")
+	cache.entries["http://robots.test.com"] = &robotsEntry{robots: parseRobots(strings.NewReader("User-agent: *\nCrawl-delay: 10\n"))}
+
+	if robots, ok := cache.lookup(u); !ok || robots.crawlDelay("bot") != 10*time.Second {
+		t.Errorf("expected cached robots.txt with crawl delay 10s")
+	}
+}
@@ -0,0 +1,114 @@
+package httpr
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// hostPattern is parsed host pattern in "[scheme://]host[:port]" form. Host may be "*",
+// matching any host, or start with "*.", matching any subdomain.
+type hostPattern struct {
+	scheme string
+	host   string
+	port   string
+}
+
+func parseHostPattern(pattern string) hostPattern {
+	var p hostPattern
+
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if scheme, rest, ok := strings.Cut(pattern, "://"); ok {
+		p.scheme, pattern = scheme, rest
+	}
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	p.host = pattern
+	if i := strings.LastIndexByte(pattern, ':'); i != -1 && !strings.HasSuffix(pattern, "]") {
+		if strings.Count(pattern, ":") == 1 || strings.HasPrefix(pattern, "[") {
+			p.host, p.port = pattern[:i], pattern[i+1:]
+		}
+	}
+	p.host = strings.TrimSuffix(strings.TrimPrefix(p.host, "["), "]")
+
+	return p
+}
+
+func (p hostPattern) match(u *url.URL) bool {
+	if p.scheme != "" && !strings.EqualFold(p.scheme, u.Scheme) {
+		return false
+	}
+	if p.port != "" && p.port != urlPort(u) {
+		return false
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	switch {
+	case p.host == "*":
+		return true
+	case strings.HasPrefix(p.host, "*."):
+		return strings.HasSuffix(host, p.host[1:])
+	default:
+		return host == p.host
+	}
+}
+
+// urlPort returns URL port, falling back to default port of URL scheme.
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "ws":
+		return "80"
+	case "https", "wss":
+		return "443"
+	default:
+		return ""
+	}
+}
+
+func parseHostPatterns(dst []hostPattern, patterns []string) []hostPattern {
+	result := make([]hostPattern, 0, len(dst)+len(patterns))
+	result = append(result, dst...)
+	for _, pattern := range patterns {
+		result = append(result, parseHostPattern(pattern))
+	}
+	return result
+}
+
+// checkHost returns ErrHostNotAllowed, if URL matches any of blocked host patterns
+// or allowed host patterns are set and URL matches none of them.
+func checkHost(u *url.URL, settings clientSettings) error {
+	for _, p := range settings.blockedHosts {
+		if p.match(u) {
+			return fmt.Errorf("%w: host %q is blocked", ErrHostNotAllowed, u.Host)
+		}
+	}
+
+	if len(settings.allowedHosts) == 0 {
+		return nil
+	}
+	for _, p := range settings.allowedHosts {
+		if p.match(u) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %q is not in allowlist", ErrHostNotAllowed, u.Host)
+}
+
+// hostCheckRedirect wraps redirect policy, so every redirect hop is checked against host filters first.
+func hostCheckRedirect(settings clientSettings, next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if err := checkHost(req.URL, settings); err != nil {
+			return err
+		}
+
+		if next == nil {
+			return checkRedirectsLimit(req, via)
+		}
+		return next(req, via)
+	}
+}
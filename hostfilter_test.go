@@ -0,0 +1,124 @@
+package httpr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestHostPatternMatch(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		url     string
+		match   bool
+	}{
+		{"example.com", "https://example.com/path", true},
+		{"example.com", "http://EXAMPLE.com.", true},
+		{"example.com", "https://api.example.com", false},
+		{"*.example.com", "https://api.example.com", true},
+		{"*.example.com", "https://a.b.example.com", true},
+		{"*.example.com", "https://example.com", false},
+		{"*.example.com", "https://notexample.com", false},
+		{"https://example.com", "http://example.com", false},
+		{"https://example.com", "https://example.com", true},
+		{"example.com:8080", "http://example.com:8080", true},
+		{"example.com:443", "https://example.com", true},
+		{"example.com:8080", "http://example.com", false},
+		{"[::1]:8080", "http://[::1]:8080", true},
+		{"::1", "http://[::1]:8080", true},
+		{"*", "http://anything.test", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.url, func(t *testing.T) {
+			u, _ := url.Parse(tc.url)
+			if got := parseHostPattern(tc.pattern).match(u); got != tc.match {
+				t.Errorf("expected match %t, got %t instead", tc.match, got)
+			}
+		})
+	}
+}
+
+func TestHostFilters(t *testing.T) {
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/redirect":
+			http.Redirect(w, req, req.URL.Query().Get("to"), http.StatusFound)
+		}
+	}))
+	defer allowed.Close()
+
+	allowedURL, _ := url.Parse(allowed.URL)
+	// Loopback server is reachable by both "127.0.0.1" and "localhost" hosts, which are filtered separately.
+	foreignURL := "http://localhost:" + allowedURL.Port() + "/"
+
+	testCases := []struct {
+		name      string
+		opts      []Option
+		url       string
+		wantError bool
+	}{
+		{
+			name: "allowed host",
+			opts: []Option{WithAllowedHosts(allowedURL.Host)},
+			url:  allowed.URL,
+		},
+		{
+			name:      "host outside allowlist",
+			opts:      []Option{WithAllowedHosts("example.com")},
+			url:       allowed.URL,
+			wantError: true,
+		},
+		{
+			name:      "blocked host",
+			opts:      []Option{WithBlockedHosts("127.0.0.1")},
+			url:       allowed.URL,
+			wantError: true,
+		},
+		{
+			name:      "blocked takes precedence",
+			opts:      []Option{WithAllowedHosts("*"), WithBlockedHosts("127.0.0.1")},
+			url:       allowed.URL,
+			wantError: true,
+		},
+		{
+			name:      "redirect outside allowlist",
+			opts:      []Option{WithAllowedHosts("127.0.0.1")},
+			url:       allowed.URL + "/redirect?to=" + url.QueryEscape(foreignURL),
+			wantError: true,
+		},
+		{
+			name:      "redirect to blocked host",
+			opts:      []Option{WithBlockedHosts("localhost"), WithMaxRedirects(5)},
+			url:       allowed.URL + "/redirect?to=" + url.QueryEscape(foreignURL),
+			wantError: true,
+		},
+		{
+			name: "redirect within allowlist",
+			opts: []Option{WithAllowedHosts("127.0.0.1", "localhost")},
+			url:  allowed.URL + "/redirect?to=" + url.QueryEscape(foreignURL),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, client := range []*Client{New(tc.opts...), New()} {
+				var opts []Option
+				if len(client.settings.allowedHosts) == 0 && len(client.settings.blockedHosts) == 0 {
+					opts = tc.opts
+				}
+
+				req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+				_, err := client.Do(req, opts...)
+				if tc.wantError && !errors.Is(err, ErrHostNotAllowed) {
+					t.Errorf("expected ErrHostNotAllowed, got %v instead", err)
+				}
+				if !tc.wantError && err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package httpr
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// _hostStatsWindow is number of latest attempts per host, which latency percentiles
+	// and error rate are calculated over.
+	_hostStatsWindow = 256
+	// _hostStatsMaxHosts limits number of tracked hosts; least recently requested host is evicted.
+	_hostStatsMaxHosts = 1024
+)
+
+// HostStats describes latency and errors of request attempts to single host, see Client.HostStats.
+// Percentiles and error rate are calculated over the latest 256 attempts; totals cover client lifetime.
+type HostStats struct {
+	Host string
+	// Attempts and Errors are total numbers of attempts and failed attempts. Attempt is failed,
+	// if it ended with transport error or response with 5xx status.
+	Attempts int64
+	Errors   int64
+	// Samples is number of attempts in window.
+	Samples int
+	// ErrorRate is fraction of failed attempts in window.
+	ErrorRate float64
+	// Latency percentiles of successful attempts in window, measured from sending request
+	// until response body is read. They are zero, if there are no successful attempts.
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+	Mean time.Duration
+	// LastAttempt is time of the latest attempt.
+	LastAttempt time.Time
+}
+
+// HostStats returns latency and error statistics of request attempts, keyed by host (with port,
+// if it's present in request URL). Statistics are collected for every attempt executed by client and
+// clients derived from it with Client.With, and can be used for adaptive timeouts or load balancing:
+//
+//	if stats, ok := client.HostStats()["api.example.com"]; ok && stats.Samples >= 100 {
+//		timeout = 2 * stats.P99
+//	}
+func (c *Client) HostStats() map[string]HostStats {
+	if c.hostStats == nil {
+		return map[string]HostStats{}
+	}
+	return c.hostStats.snapshot()
+}
+
+// hostStatsTracker keeps ring buffers of recent attempts per host.
+type hostStatsTracker struct {
+	mu    sync.Mutex
+	hosts map[string]*hostSamples
+}
+
+type hostSamples struct {
+	// samples is ring buffer of attempts, next is position of the next sample.
+	samples     [_hostStatsWindow]hostSample
+	next        int
+	count       int
+	attempts    int64
+	errors      int64
+	lastAttempt time.Time
+}
+
+type hostSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+func newHostStatsTracker() *hostStatsTracker {
+	return &hostStatsTracker{hosts: make(map[string]*hostSamples)}
+}
+
+// record adds attempt to statistics of request host.
+func (t *hostStatsTracker) record(req *http.Request, resp *Response, err error, latency time.Duration, now time.Time) {
+	if t == nil {
+		return
+	}
+	host := req.URL.Host
+	failed := err != nil || Is5xx(resp.StatusCode())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples, ok := t.hosts[host]
+	if !ok {
+		if len(t.hosts) >= _hostStatsMaxHosts {
+			t.evictOldest()
+		}
+		samples = new(hostSamples)
+		t.hosts[host] = samples
+	}
+
+	samples.samples[samples.next] = hostSample{latency: latency, failed: failed}
+	samples.next = (samples.next + 1) % _hostStatsWindow
+	if samples.count < _hostStatsWindow {
+		samples.count++
+	}
+	samples.attempts++
+	if failed {
+		samples.errors++
+	}
+	samples.lastAttempt = now
+}
+
+func (t *hostStatsTracker) evictOldest() {
+	var (
+		oldestHost string
+		oldest     time.Time
+	)
+	for host, samples := range t.hosts {
+		if oldestHost == "" || samples.lastAttempt.Before(oldest) {
+			oldestHost, oldest = host, samples.lastAttempt
+		}
+	}
+	delete(t.hosts, oldestHost)
+}
+
+func (t *hostStatsTracker) snapshot() map[string]HostStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make(map[string]HostStats, len(t.hosts))
+	for host, samples := range t.hosts {
+		stats[host] = samples.stats(host)
+	}
+	return stats
+}
+
+func (s *hostSamples) stats(host string) HostStats {
+	stats := HostStats{
+		Host:        host,
+		Attempts:    s.attempts,
+		Errors:      s.errors,
+		Samples:     s.count,
+		LastAttempt: s.lastAttempt,
+	}
+
+	var (
+		latencies = make([]time.Duration, 0, s.count)
+		failed    int
+		total     time.Duration
+	)
+	for _, sample := range s.samples[:s.count] {
+		if sample.failed {
+			failed++
+			continue
+		}
+		latencies = append(latencies, sample.latency)
+		total += sample.latency
+	}
+	if s.count > 0 {
+		stats.ErrorRate = float64(failed) / float64(s.count)
+	}
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.P50 = percentile(latencies, 50)
+	stats.P90 = percentile(latencies, 90)
+	stats.P99 = percentile(latencies, 99)
+	stats.Max = latencies[len(latencies)-1]
+	stats.Mean = total / time.Duration(len(latencies))
+
+	return stats
+}
+
+// percentile returns nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,95 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestClientHostStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := New(WithRetryCount(2), WithRetryDelay(time.Millisecond))
+	if _, err := client.Get(context.Background(), server.URL+"/ok", nil); err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+	if _, err := client.With().Get(context.Background(), server.URL+"/fail", nil); err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+
+	u, _ := url.Parse(server.URL)
+	stats, ok := client.HostStats()[u.Host]
+	if !ok {
+		t.Fatalf("expected stats of host %q", u.Host)
+	}
+	if stats.Attempts != 3 || stats.Errors != 2 || stats.Samples != 3 {
+		t.Fatalf("expected 3 attempts with 2 errors, got %d attempts with %d errors instead", stats.Attempts, stats.Errors)
+	}
+	if stats.ErrorRate < 0.66 || stats.ErrorRate > 0.67 {
+		t.Fatalf("expected error rate 2/3, got %f instead", stats.ErrorRate)
+	}
+	if stats.P50 <= 0 || stats.P50 != stats.Max {
+		t.Fatalf("expected latency of single successful attempt, got p50 %s and max %s", stats.P50, stats.Max)
+	}
+}
+
+func TestHostSamplesStats(t *testing.T) {
+	tracker := newHostStatsTracker()
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.test.com", nil)
+	now := time.Now()
+
+	for i := 1; i <= _hostStatsWindow+100; i++ {
+		tracker.record(req, nil, nil, time.Duration(i)*time.Millisecond, now)
+	}
+
+	stats := tracker.snapshot()["api.test.com"]
+	if stats.Attempts != _hostStatsWindow+100 || stats.Samples != _hostStatsWindow {
+		t.Fatalf("expected %d attempts and %d samples, got %d and %d instead",
+			_hostStatsWindow+100, _hostStatsWindow, stats.Attempts, stats.Samples)
+	}
+
+	// Window holds latencies from 101ms to 356ms.
+	testCases := []struct {
+		name     string
+		got      time.Duration
+		expected time.Duration
+	}{
+		{"P50", stats.P50, 228 * time.Millisecond},
+		{"P90", stats.P90, 331 * time.Millisecond},
+		{"P99", stats.P99, 354 * time.Millisecond},
+		{"Max", stats.Max, 356 * time.Millisecond},
+		{"Mean", stats.Mean, 228500 * time.Microsecond},
+	}
+	for _, tc := range testCases {
+		if tc.got != tc.expected {
+			t.Errorf("expected %s %s, got %s instead", tc.name, tc.expected, tc.got)
+		}
+	}
+}
+
+func TestHostStatsEviction(t *testing.T) {
+	tracker := newHostStatsTracker()
+	now := time.Now()
+
+	for i := 0; i <= _hostStatsMaxHosts; i++ {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://host"+strconv.Itoa(i)+".test", nil)
+		tracker.record(req, nil, nil, time.Millisecond, now.Add(time.Duration(i)*time.Second))
+	}
+
+	stats := tracker.snapshot()
+	if len(stats) != _hostStatsMaxHosts {
+		t.Fatalf("expected %d hosts, got %d instead", _hostStatsMaxHosts, len(stats))
+	}
+	if _, ok := stats["host0.test"]; ok {
+		t.Fatal("expected least recently requested host to be evicted")
+	}
+}
@@ -0,0 +1,356 @@
+package httpr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// HTMLNode is an element of HTML document returned by Response.HTML.
+type HTMLNode struct {
+	// Tag is lowercase element name. It is empty for document node.
+	Tag string
+	// Attrs contains element attributes with lowercase names.
+	Attrs    map[string]string
+	Parent   *HTMLNode
+	Children []*HTMLNode
+
+	content []any // string and *HTMLNode items in document order
+}
+
+// _rawTextElements are elements, which content isn't parsed as markup.
+var _rawTextElements = []string{"script", "style"}
+
+// HTML parses response body as HTML document and returns its document node, which can be queried
+// with CSS selectors. Parser is built upon encoding/xml in non-strict mode with HTML entities and
+// void elements support, so it is tolerant to most real-world markup, but doesn't implement
+// HTML5 tree construction rules, e.g. unclosed paragraphs are nested into each other. Contents of
+// "script" and "style" elements are dropped. For full-fidelity parsing pass Response.Reader
+// to golang.org/x/net/html.
+func (r *Response) HTML() (*HTMLNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(dropRawText(r.Bytes())))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	var (
+		doc     = &HTMLNode{}
+		current = doc
+	)
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse response HTML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &HTMLNode{
+				Tag:    strings.ToLower(t.Name.Local),
+				Attrs:  make(map[string]string, len(t.Attr)),
+				Parent: current,
+			}
+			for _, attr := range t.Attr {
+				node.Attrs[strings.ToLower(attr.Name.Local)] = attr.Value
+			}
+			current.Children = append(current.Children, node)
+			current.content = append(current.content, node)
+			current = node
+		case xml.EndElement:
+			if current.Parent != nil {
+				current = current.Parent
+			}
+		case xml.CharData:
+			current.content = append(current.content, string(t))
+		}
+	}
+
+	return doc, nil
+}
+
+// dropRawText removes contents of raw text elements, which can't be tokenized as XML.
+func dropRawText(data []byte) []byte {
+	lower := bytes.ToLower(data)
+	for _, tag := range _rawTextElements {
+		var (
+			result []byte
+			offset int
+		)
+		for {
+			start := bytes.Index(lower[offset:], []byte("<"+tag))
+			if start < 0 {
+				break
+			}
+			start += offset
+			openEnd := bytes.IndexByte(lower[start:], '>')
+			if openEnd < 0 {
+				break
+			}
+			openEnd += start + 1
+			end := bytes.Index(lower[openEnd:], []byte("</"+tag))
+			if end < 0 {
+				end = len(lower)
+			} else {
+				end += openEnd
+			}
+
+			result = append(result, data[offset:openEnd]...)
+			offset = end
+		}
+		if result != nil {
+			data = append(result, data[offset:]...)
+			lower = bytes.ToLower(data)
+		}
+	}
+
+	return data
+}
+
+// Text returns concatenated text content of node and its descendants with surrounding
+// whitespace trimmed.
+func (n *HTMLNode) Text() string {
+	var sb strings.Builder
+	n.writeText(&sb)
+
+	return strings.TrimSpace(sb.String())
+}
+
+func (n *HTMLNode) writeText(sb *strings.Builder) {
+	for _, item := range n.content {
+		switch v := item.(type) {
+		case string:
+			sb.WriteString(v)
+		case *HTMLNode:
+			v.writeText(sb)
+		}
+	}
+}
+
+// Attr returns value of attribute with provided name or empty string, if attribute is absent.
+func (n *HTMLNode) Attr(name string) string {
+	return n.Attrs[strings.ToLower(name)]
+}
+
+// Find returns descendants of node matching CSS selector in document order. Supported selectors
+// are type ("a"), universal ("*"), id ("#main"), class (".item"), attribute ("[href]",
+// "[type=text]") selectors and their compounds, combined with descendant (" ") and
+// child (">") combinators. Selectors can be grouped with comma.
+func (n *HTMLNode) Find(selector string) ([]*HTMLNode, error) {
+	groups, err := parseSelectorGroups(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*HTMLNode
+	n.walk(func(node *HTMLNode) {
+		for _, group := range groups {
+			if group.matches(node, n) {
+				found = append(found, node)
+				return
+			}
+		}
+	})
+
+	return found, nil
+}
+
+// FindFirst returns first descendant of node matching CSS selector. If nothing matches,
+// error wrapping ErrPathNotFound is returned. See Find for supported selectors.
+func (n *HTMLNode) FindFirst(selector string) (*HTMLNode, error) {
+	found, err := n.Find(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrPathNotFound, selector)
+	}
+
+	return found[0], nil
+}
+
+func (n *HTMLNode) walk(fn func(node *HTMLNode)) {
+	for _, child := range n.Children {
+		fn(child)
+		child.walk(fn)
+	}
+}
+
+// compoundSelector is a sequence of simple selectors, which all must match element.
+type compoundSelector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+	// child reports whether selector is joined with previous one with child combinator.
+	child bool
+}
+
+type attrSelector struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// complexSelector is a chain of compound selectors joined with combinators.
+type complexSelector []compoundSelector
+
+func parseSelectorGroups(selector string) ([]complexSelector, error) {
+	var groups []complexSelector
+	for _, group := range strings.Split(selector, ",") {
+		parsed, err := parseComplexSelector(group)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, parsed)
+	}
+
+	return groups, nil
+}
+
+func parseComplexSelector(selector string) (complexSelector, error) {
+	fields := strings.Fields(strings.ReplaceAll(selector, ">", " > "))
+
+	var (
+		result complexSelector
+		child  bool
+	)
+	for _, field := range fields {
+		if field == ">" {
+			if child || len(result) == 0 {
+				return nil, fmt.Errorf("invalid selector %q", selector)
+			}
+			child = true
+			continue
+		}
+
+		compound, err := parseCompoundSelector(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+		compound.child = child
+		child = false
+		result = append(result, compound)
+	}
+
+	if len(result) == 0 || child {
+		return nil, fmt.Errorf("invalid selector %q", selector)
+	}
+
+	return result, nil
+}
+
+func parseCompoundSelector(s string) (compoundSelector, error) {
+	var sel compoundSelector
+
+	end := strings.IndexAny(s, "#.[")
+	if end < 0 {
+		end = len(s)
+	}
+	if tag := strings.ToLower(s[:end]); tag != "*" {
+		sel.tag = tag
+	}
+	s = s[end:]
+
+	for s != "" {
+		switch s[0] {
+		case '#', '.':
+			end = strings.IndexAny(s[1:], "#.[")
+			if end < 0 {
+				end = len(s) - 1
+			}
+			name := s[1 : end+1]
+			if name == "" {
+				return sel, fmt.Errorf("empty name after %q", s[0])
+			}
+			if s[0] == '#' {
+				sel.id = name
+			} else {
+				sel.classes = append(sel.classes, name)
+			}
+			s = s[end+1:]
+		case '[':
+			end = strings.IndexByte(s, ']')
+			if end < 0 {
+				return sel, errors.New("unclosed attribute selector")
+			}
+			name, value, hasValue := strings.Cut(s[1:end], "=")
+			if name == "" {
+				return sel, errors.New("empty attribute name")
+			}
+			sel.attrs = append(sel.attrs, attrSelector{
+				name:     strings.ToLower(name),
+				value:    strings.Trim(value, `"'`),
+				hasValue: hasValue,
+			})
+			s = s[end+1:]
+		default:
+			return sel, fmt.Errorf("unexpected character %q", s[0])
+		}
+	}
+
+	return sel, nil
+}
+
+func (sel compoundSelector) matches(node *HTMLNode) bool {
+	if node.Tag == "" || (sel.tag != "" && sel.tag != node.Tag) {
+		return false
+	}
+	if sel.id != "" && node.Attrs["id"] != sel.id {
+		return false
+	}
+
+	classes := strings.Fields(node.Attrs["class"])
+	for _, class := range sel.classes {
+		if !containsString(classes, class) {
+			return false
+		}
+	}
+
+	for _, attr := range sel.attrs {
+		value, ok := node.Attrs[attr.name]
+		if !ok || (attr.hasValue && value != attr.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches reports whether node matches selector. Ancestors are looked up until scope node.
+func (sel complexSelector) matches(node, scope *HTMLNode) bool {
+	last := len(sel) - 1
+	if !sel[last].matches(node) {
+		return false
+	}
+	if last == 0 {
+		return true
+	}
+
+	rest := sel[:last]
+	for ancestor := node.Parent; ancestor != nil && ancestor != scope; ancestor = ancestor.Parent {
+		if rest.matches(ancestor, scope) {
+			return true
+		}
+		if sel[last].child {
+			return false
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+
+	return false
+}
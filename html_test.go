@@ -0,0 +1,89 @@
+package httpr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseHTML(t *testing.T) {
+	resp := &Response{
+		rawResp: &http.Response{},
+		body: []byte(`<!DOCTYPE html>
+<html>
+<head>
+	<title>Shop &amp; Co</title>
+	<script>if (a < b && c) { render("<div>"); }</script>
+	<meta charset=utf-8>
+</head>
+<body>
+	<div id="main" class="content wide">
+		<ul class="items">
+			<li class="item"><a href="/1">First</a></li>
+			<li class="item sale"><a href="/2" data-id=2>Second&nbsp;item</a></li>
+		</ul>
+		<p>Total: <b>2</b><br>items</p>
+		<input type="text" disabled>
+	</div>
+	<a href="/about">About</a>
+</body>
+</html>`),
+	}
+
+	doc, err := resp.HTML()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		selector string
+		count    int
+		text     string
+	}{
+		{selector: "title", count: 1, text: "Shop & Co"},
+		{selector: "a", count: 3, text: "First"},
+		{selector: "#main a", count: 2, text: "First"},
+		{selector: "div > a", count: 0},
+		{selector: "body > a", count: 1, text: "About"},
+		{selector: "ul.items > li.sale a", count: 1, text: "Second\u00a0item"},
+		{selector: "a[data-id=2]", count: 1, text: "Second\u00a0item"},
+		{selector: `a[href="/about"], li.item`, count: 3, text: "First"},
+		{selector: "p", count: 1, text: "Total: 2items"},
+		{selector: "input[disabled]", count: 1},
+		{selector: "div.content.wide", count: 1},
+		{selector: "script", count: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			found, err := doc.Find(tt.selector)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(found) != tt.count {
+				t.Fatalf("expected %d element(s), got %d instead", tt.count, len(found))
+			}
+			if tt.text != "" && found[0].Text() != tt.text {
+				t.Errorf("expected text %q, got %q instead", tt.text, found[0].Text())
+			}
+		})
+	}
+
+	link, err := doc.FindFirst("li.sale a")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if href := link.Attr("HREF"); href != "/2" {
+		t.Errorf("expected href %q, got %q instead", "/2", href)
+	}
+
+	if _, err = doc.FindFirst("table"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("expected ErrPathNotFound, got %v", err)
+	}
+
+	for _, selector := range []string{"> a", "a >", "a[href", "div#", ""} {
+		if _, err = doc.Find(selector); err == nil {
+			t.Errorf("expected error for selector %q, got nil", selector)
+		}
+	}
+}
@@ -4,6 +4,10 @@
 
 // Package httpr provides convenient methods for building and executing HTTP requests
 // in GO idiomatic way.
+//
+// httpr depends on standard library only. Formats, content encodings and charsets, which need
+// third-party implementations, are plugged in with RegisterCodec, RegisterDecompressor
+// and RegisterCharsetDecoder.
 package httpr
 
 import (
@@ -21,12 +25,16 @@ var DefaultClient = New()
 
 // New creates new client with provided Options. Options must implement Option interface.
 // Call to New is similar to call NewWithClient(&http.Client{}, opts...}.
-func New(opts ...Option) Client {
+func New(opts ...Option) *Client {
 	return NewWithClient(&http.Client{}, opts...)
 }
 
 // NewWithClient creates new client, which uses passed http.Client instance and options.
-func NewWithClient(httpClient *http.Client, opts ...Option) Client {
+// Transport, cookie jar and redirect policy of passed http.Client are replaced only if corresponding
+// options (WithTransport, WithCookieJar, WithCheckRedirect) were provided. If WithPreserveClient option is provided, passed
+// instance is never modified and its shallow copy is used instead.
+// Client settings are immutable after construction, so returned client is safe for concurrent use.
+func NewWithClient(httpClient *http.Client, opts ...Option) *Client {
 	settings := newDefaultSettings()
 	for _, opt := range opts {
 		opt(&settings)
@@ -34,13 +42,25 @@ func NewWithClient(httpClient *http.Client, opts ...Option) Client {
 
 	if httpClient == nil {
 		httpClient = &http.Client{}
+	} else if settings.preserveClient {
+		clientCopy := *httpClient
+		httpClient = &clientCopy
 	}
 
-	httpClient.Transport = settings.transport
-	httpClient.Jar = settings.cookieJar
+	if settings.transport != nil {
+		httpClient.Transport = settings.transport
+	}
+	if settings.cookieJar != nil {
+		httpClient.Jar = settings.cookieJar
+	}
+	if settings.redirectCheckFn != nil {
+		httpClient.CheckRedirect = settings.redirectCheckFn
+	}
+	httpClient.Transport = tuneTransport(httpClient.Transport, settings)
 
-	return Client{
-		client:   httpClient,
-		settings: settings,
+	return &Client{
+		client:    httpClient,
+		settings:  settings,
+		hostStats: newHostStatsTracker(),
 	}
 }
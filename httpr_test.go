@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -152,8 +153,58 @@ func TestIsValidURL(t *testing.T) {
 			expected: false,
 		},
 		{
-			name:     "Invalid_NotCompletedDomain",
+			name:     "Valid_SingleLabelHost",
 			input:    "https://test",
+			expected: true,
+		},
+		{
+			name:     "Valid_LocalhostWithPort",
+			input:    "http://localhost:8080/path",
+			expected: true,
+		},
+		{
+			name:     "Valid_IPv4",
+			input:    "http://127.0.0.1",
+			expected: true,
+		},
+		{
+			name:     "Valid_IPv6WithPort",
+			input:    "http://[::1]:8080",
+			expected: true,
+		},
+		{
+			name:     "Valid_ClusterInternalHost",
+			input:    "http://my-service.my-namespace.svc.cluster.local:9000",
+			expected: true,
+		},
+		{
+			name:     "Invalid_EmptyPort",
+			input:    "http://localhost:",
+			expected: false,
+		},
+		{
+			name:     "Invalid_PortOutOfRange",
+			input:    "http://localhost:70000",
+			expected: false,
+		},
+		{
+			name:     "Invalid_PortOnly",
+			input:    "http://:8080",
+			expected: false,
+		},
+		{
+			name:     "Invalid_HostWithSpace",
+			input:    "http://my host",
+			expected: false,
+		},
+		{
+			name:     "Invalid_LabelStartsWithHyphen",
+			input:    "http://-test.com",
+			expected: false,
+		},
+		{
+			name:     "Invalid_EmptyLabel",
+			input:    "http://test..com",
 			expected: false,
 		},
 		{
@@ -178,3 +229,81 @@ func TestIsValidURL(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateURLStrict(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: "https://test.com"},
+		{input: "http://127.0.0.1:8080"},
+		{input: "http://[::1]"},
+		{input: "http://localhost", wantErr: true},
+		{input: "https://test", wantErr: true},
+		{input: "ftp://test.com", wantErr: true},
+		{input: "http://1.2.3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if err := ValidateURL(tt.input, true); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v instead", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := NewRequest().SetURL("http://localhost:8080").SetStrictURLValidation(true).Build(); err == nil {
+		t.Fatal("expected strict URL validation error, got nil instead")
+	}
+}
+
+func TestNewWithClient(t *testing.T) {
+	t.Run("KeepsClientTransport", func(t *testing.T) {
+		var (
+			tr         = DefaultTransport()
+			jar        = &fakeCookieJar{}
+			httpClient = &http.Client{Transport: tr, Jar: jar}
+		)
+
+		c := NewWithClient(httpClient, WithRetryCount(3))
+		if c.Client().Transport != tr {
+			t.Error("transport of passed client must not be replaced")
+		}
+		if c.Client().Jar != jar {
+			t.Error("cookie jar of passed client must not be replaced")
+		}
+	})
+
+	t.Run("OverridesProvidedOptions", func(t *testing.T) {
+		var (
+			tr         = DefaultTransport()
+			httpClient = &http.Client{Transport: http.DefaultTransport}
+		)
+
+		c := NewWithClient(httpClient, WithTransport(tr))
+		if c.Client().Transport != tr {
+			t.Error("transport must be replaced with provided one")
+		}
+	})
+
+	t.Run("PreserveClient", func(t *testing.T) {
+		var (
+			tr         = DefaultTransport()
+			httpClient = &http.Client{Transport: http.DefaultTransport}
+		)
+
+		c := NewWithClient(httpClient, WithTransport(tr), WithPreserveClient())
+		if httpClient.Transport != http.DefaultTransport {
+			t.Error("passed client must not be modified")
+		}
+		if c.Client() == httpClient || c.Client().Transport != tr {
+			t.Error("copy of passed client with provided transport must be used")
+		}
+	})
+}
+
+type fakeCookieJar struct{}
+
+func (j *fakeCookieJar) SetCookies(_ *url.URL, _ []*http.Cookie) {}
+
+func (j *fakeCookieJar) Cookies(_ *url.URL) []*http.Cookie { return nil }
@@ -0,0 +1,109 @@
+package httpr
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JitterFunc randomizes provided delay. Methods of Jitter have this signature.
+type JitterFunc func(d time.Duration) time.Duration
+
+// DelayFunc returns delay to be taken, see Delay.
+type DelayFunc func() time.Duration
+
+// Jitter produces random delays from its own random source, so results are reproducible
+// with seeded source. Jitter is safe for concurrent use.
+type Jitter struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// _defaultJitter is used by RandomDelay.
+var _defaultJitter = NewJitter(nil)
+
+// NewJitter creates Jitter with provided random source. If source is nil, source seeded
+// with current time is used.
+func NewJitter(src rand.Source) *Jitter {
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+
+	//nolint:gosec
+	return &Jitter{rnd: rand.New(src)}
+}
+
+// Full returns random delay in [0, d) range ("full jitter").
+func (j *Jitter) Full(d time.Duration) time.Duration {
+	return j.Between(0, d)
+}
+
+// Equal returns random delay in [d/2, d) range ("equal jitter"), which keeps at least half of delay.
+func (j *Jitter) Equal(d time.Duration) time.Duration {
+	return j.Between(d/2, d)
+}
+
+// Between returns random delay in [minDelay, maxDelay) range. If range is empty, minDelay is returned.
+func (j *Jitter) Between(minDelay, maxDelay time.Duration) time.Duration {
+	if minDelay < 0 {
+		minDelay = 0
+	}
+	if maxDelay <= minDelay {
+		return minDelay
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return minDelay + time.Duration(j.rnd.Int63n(int64(maxDelay-minDelay)))
+}
+
+// Delay is pre-built PreRequestHookFn compliant function, which delays request execution by duration
+// returned by provided function. Waiting is interrupted, when request context is done, in which case
+// context error is returned and request isn't sent. For example, to wait 1-3 seconds before each request:
+//
+//	jitter := httpr.NewJitter(nil)
+//	client := httpr.New(httpr.WithPreRequestHook(httpr.Delay(func() time.Duration {
+//		return jitter.Between(time.Second, 3*time.Second)
+//	})))
+func Delay(delayFn DelayFunc) PreRequestHookFn {
+	return func(req *http.Request) error {
+		return sleepContext(req.Context(), systemClock{}, delayFn())
+	}
+}
+
+// nextRetryDelay returns delay, which follows provided one according to backoff settings.
+func nextRetryDelay(delay time.Duration, settings clientSettings) time.Duration {
+	if multiplier := settings.retryBackoffMultiplier; multiplier > 1 {
+		if next := float64(delay) * multiplier; next < math.MaxInt64 {
+			delay = time.Duration(next)
+		} else {
+			delay = math.MaxInt64
+		}
+	}
+	if delta := settings.retryDelayDelta; delta > 0 && delay > math.MaxInt64-delta {
+		delay = math.MaxInt64
+	} else {
+		delay += delta
+	}
+
+	return capRetryDelay(delay, settings)
+}
+
+// capRetryDelay limits delay with maximum retry delay, if it's set.
+func capRetryDelay(delay time.Duration, settings clientSettings) time.Duration {
+	if settings.retryMaxDelay > 0 && delay > settings.retryMaxDelay {
+		return settings.retryMaxDelay
+	}
+	return delay
+}
+
+// retryDelayWithJitter returns retry delay randomized with jitter function, if it's set.
+func retryDelayWithJitter(delay time.Duration, jitter JitterFunc) time.Duration {
+	if jitter == nil || delay <= 0 {
+		return delay
+	}
+	return jitter(delay)
+}
@@ -0,0 +1,190 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fn       func(j *Jitter) time.Duration
+		min, max time.Duration
+	}{
+		{name: "full", fn: func(j *Jitter) time.Duration { return j.Full(time.Second) }, min: 0, max: time.Second},
+		{name: "equal", fn: func(j *Jitter) time.Duration { return j.Equal(time.Second) }, min: time.Second / 2, max: time.Second},
+		{name: "between", fn: func(j *Jitter) time.Duration { return j.Between(time.Second, 2*time.Second) }, min: time.Second, max: 2 * time.Second},
+		{name: "zero", fn: func(j *Jitter) time.Duration { return j.Full(0) }, min: 0, max: 1},
+		{name: "empty range", fn: func(j *Jitter) time.Duration { return j.Between(time.Second, time.Second) }, min: time.Second, max: time.Second + 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			j := NewJitter(rand.NewSource(1))
+			for i := 0; i < 100; i++ {
+				if d := tc.fn(j); d < tc.min || d >= tc.max {
+					t.Fatalf("expected delay in [%s, %s), got %s instead", tc.min, tc.max, d)
+				}
+			}
+		})
+	}
+
+	t.Run("reproducible", func(t *testing.T) {
+		first, second := NewJitter(rand.NewSource(42)), NewJitter(rand.NewSource(42))
+		for i := 0; i < 10; i++ {
+			if a, b := first.Full(time.Hour), second.Full(time.Hour); a != b {
+				t.Fatalf("expected equally seeded jitters to match, got %s and %s", a, b)
+			}
+		}
+	})
+}
+
+func TestRandomDelay(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	start := time.Now()
+	if err := RandomDelay(20 * time.Millisecond)(req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected delay below 20ms, took %s", elapsed)
+	}
+
+	if err := RandomDelay(0)(req); err != nil {
+		t.Errorf("unexpected error for zero limit: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := RandomDelay(time.Hour)(req.WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v instead", err)
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	var jittered []time.Duration
+
+	client := New(
+		WithClock(clock),
+		WithRetryCount(3),
+		WithRetryDelay(time.Minute),
+		WithRetryJitter(func(d time.Duration) time.Duration {
+			jittered = append(jittered, d)
+			return d / 4
+		}),
+	)
+	if _, err := client.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(jittered) != 2 {
+		t.Fatalf("expected jitter to be applied to 2 retry delays, got %d instead", len(jittered))
+	}
+	if waited := clock.Now().Sub(start); waited != 30*time.Second {
+		t.Errorf("expected jittered delays to sum to 30s, got %s instead", waited)
+	}
+}
+
+func TestRetryDelayDelta(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	start := time.Unix(0, 0)
+	clock := NewFakeClock(start)
+	var delays []time.Duration
+
+	client := New(
+		WithClock(clock),
+		WithRetryCount(4),
+		WithRetryDelay(time.Second),
+		WithRetryDelayDelta(2*time.Second),
+		WithStatsHandler(StatsHandlerFunc(func(_ context.Context, event StatsEvent) {
+			if retry, ok := event.(RetryScheduled); ok {
+				delays = append(delays, retry.Delay)
+			}
+		})),
+	)
+	if _, err := client.Get(context.Background(), ts.URL, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}
+	if !reflect.DeepEqual(delays, expected) {
+		t.Fatalf("expected growing retry delays %v, got %v instead", expected, delays)
+	}
+	if waited := clock.Now().Sub(start); waited != 9*time.Second {
+		t.Errorf("expected retry delays to sum to 9s, got %s instead", waited)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		name     string
+		opts     []Option
+		expected []time.Duration
+	}{
+		{
+			name:     "exponential",
+			opts:     []Option{WithRetryBackoff(2)},
+			expected: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		{
+			name:     "exponential with delta",
+			opts:     []Option{WithRetryBackoff(3), WithRetryDelayDelta(time.Second)},
+			expected: []time.Duration{time.Second, 4 * time.Second, 13 * time.Second, 40 * time.Second},
+		},
+		{
+			name:     "max delay",
+			opts:     []Option{WithRetryBackoff(2), WithRetryMaxDelay(3 * time.Second)},
+			expected: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			name:     "multiplier ignored",
+			opts:     []Option{WithRetryBackoff(0.5)},
+			expected: []time.Duration{time.Second, time.Second, time.Second, time.Second},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var delays []time.Duration
+			opts := append([]Option{
+				WithClock(NewFakeClock(time.Unix(0, 0))),
+				WithRetryCount(5),
+				WithRetryDelay(time.Second),
+				WithStatsHandler(StatsHandlerFunc(func(_ context.Context, event StatsEvent) {
+					if retry, ok := event.(RetryScheduled); ok {
+						delays = append(delays, retry.Delay)
+					}
+				})),
+			}, tc.opts...)
+
+			if _, err := New(opts...).Get(context.Background(), ts.URL, nil); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(delays, tc.expected) {
+				t.Errorf("expected retry delays %v, got %v instead", tc.expected, delays)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package httpr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONValue is a value extracted from JSON response body with Response.JSONPath.
+type JSONValue struct {
+	value any
+}
+
+// JSONPath returns value located at provided path in response JSON body without unmarshalling
+// it into structs. Path consists of object keys and array indexes separated by dots,
+// e.g. "data.items.0.id". Dots in keys can be escaped with backslash. Empty path refers to
+// the whole document. If value doesn't exist, error wrapping ErrPathNotFound is returned.
+func (r *Response) JSONPath(path string) (JSONValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(r.Bytes()))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return JSONValue{}, fmt.Errorf("failed to decode response JSON: %w", withSentinel(ErrDecodeBody, err))
+	}
+
+	for _, segment := range splitJSONPath(path) {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return JSONValue{}, fmt.Errorf("%w: %q", ErrPathNotFound, path)
+			}
+			value = next
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return JSONValue{}, fmt.Errorf("%w: %q", ErrPathNotFound, path)
+			}
+			value = v[idx]
+		default:
+			return JSONValue{}, fmt.Errorf("%w: %q", ErrPathNotFound, path)
+		}
+	}
+
+	return JSONValue{value: value}, nil
+}
+
+func splitJSONPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	var (
+		segments []string
+		segment  strings.Builder
+	)
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			segment.WriteByte('.')
+			i++
+		case path[i] == '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(path[i])
+		}
+	}
+
+	return append(segments, segment.String())
+}
+
+// Value returns underlying value, which is one of nil, bool, json.Number, string,
+// []any or map[string]any.
+func (v JSONValue) Value() any {
+	return v.value
+}
+
+// IsNull reports whether value is JSON null.
+func (v JSONValue) IsNull() bool {
+	return v.value == nil
+}
+
+// String returns string value. Values of other types are returned in their JSON representation.
+func (v JSONValue) String() string {
+	if s, ok := v.value.(string); ok {
+		return s
+	}
+
+	data, err := json.Marshal(v.value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Int returns value as int64. Error is returned, if value isn't an integer number.
+func (v JSONValue) Int() (int64, error) {
+	n, ok := v.value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("value of type %T is not a number", v.value)
+	}
+
+	return n.Int64()
+}
+
+// Float returns value as float64. Error is returned, if value isn't a number.
+func (v JSONValue) Float() (float64, error) {
+	n, ok := v.value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("value of type %T is not a number", v.value)
+	}
+
+	return n.Float64()
+}
+
+// Bool returns value as bool. Error is returned, if value isn't a boolean.
+func (v JSONValue) Bool() (bool, error) {
+	b, ok := v.value.(bool)
+	if !ok {
+		return false, fmt.Errorf("value of type %T is not a boolean", v.value)
+	}
+
+	return b, nil
+}
+
+// Array returns elements of array value. Error is returned, if value isn't an array.
+func (v JSONValue) Array() ([]JSONValue, error) {
+	arr, ok := v.value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("value of type %T is not an array", v.value)
+	}
+
+	values := make([]JSONValue, 0, len(arr))
+	for _, elem := range arr {
+		values = append(values, JSONValue{value: elem})
+	}
+	return values, nil
+}
+
+// Unmarshal stores value in the value pointed by p, the same way json.Unmarshal does.
+func (v JSONValue) Unmarshal(p any) error {
+	data, err := json.Marshal(v.value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, p)
+}
@@ -0,0 +1,65 @@
+package httpr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseJSONPath(t *testing.T) {
+	resp := &Response{
+		rawResp: &http.Response{},
+		body: []byte(`{
+			"data": {
+				"items": [{"id": 9007199254740993, "name": "first", "price": 1.5, "active": true}],
+				"meta.total": 1,
+				"next": null
+			}
+		}`),
+	}
+
+	id, err := resp.JSONPath("data.items.0.id")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n, err := id.Int(); err != nil || n != 9007199254740993 {
+		t.Errorf("expected id 9007199254740993, got %d (error %v)", n, err)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "data.items.0.name", expected: "first"},
+		{path: "data.items.0.price", expected: "1.5"},
+		{path: "data.items.0.active", expected: "true"},
+		{path: `data.meta\.total`, expected: "1"},
+		{path: "data.next", expected: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := resp.JSONPath(tt.path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if value.String() != tt.expected {
+				t.Errorf("expected value %q, got %q instead", tt.expected, value.String())
+			}
+		})
+	}
+
+	items, err := resp.JSONPath("data.items")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if arr, err := items.Array(); err != nil || len(arr) != 1 {
+		t.Errorf("expected array of 1 element, got %v (error %v)", arr, err)
+	}
+
+	for _, path := range []string{"data.missing", "data.items.1", "data.items.first", "data.items.0.name.x"} {
+		if _, err = resp.JSONPath(path); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("expected ErrPathNotFound for path %q, got %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/hickar/httpr/msgpackcodec
+
+go 1.18
+
+require (
+	github.com/hickar/httpr v0.1.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+)
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
@@ -0,0 +1,60 @@
+// Package msgpackcodec adds MessagePack body support to httpr. It's separate module,
+// so core httpr module stays free of dependencies. Importing package registers codec for
+// httpr.MediaTypeMsgPack, "application/x-msgpack" and "application/vnd.msgpack" globally,
+// so MessagePack bodies are sent with RequestBuilder.SetCodecBody and decoded with
+// Response.Decode according to response Content-Type:
+//
+//	import _ "github.com/hickar/httpr/msgpackcodec"
+//
+//	req, err := httpr.NewRequest().Post(url, nil).SetCodecBody(httpr.MediaTypeMsgPack, user).Build()
+//	// ...
+//	err = resp.Decode(&user)
+//
+// Struct fields without "msgpack" tag are named after their "json" tag, so types shared
+// with JSON APIs keep their wire names.
+package msgpackcodec
+
+import (
+	"bytes"
+
+	"github.com/hickar/httpr"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec marshals and unmarshals MessagePack bodies.
+var Codec = httpr.Codec{
+	Marshal:   Marshal,
+	Unmarshal: Unmarshal,
+}
+
+func init() {
+	for _, mediaType := range []string{httpr.MediaTypeMsgPack, "application/x-msgpack", "application/vnd.msgpack"} {
+		httpr.RegisterCodec(mediaType, Codec)
+	}
+}
+
+// Marshal encodes provided value as MessagePack.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := msgpack.GetEncoder()
+	defer msgpack.PutEncoder(enc)
+
+	enc.Reset(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes MessagePack data into value pointed by v.
+func Unmarshal(data []byte, v any) error {
+	dec := msgpack.GetDecoder()
+	defer msgpack.PutDecoder(dec)
+
+	dec.Reset(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
@@ -0,0 +1,82 @@
+package msgpackcodec
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hickar/httpr"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestMsgPackBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if contentType := req.Header.Get("Content-Type"); contentType != httpr.MediaTypeMsgPack {
+			t.Errorf("expected Content-Type %q, got %q instead", httpr.MediaTypeMsgPack, contentType)
+		}
+
+		body, _ := io.ReadAll(req.Body)
+		var received map[string]any
+		if err := msgpack.Unmarshal(body, &received); err != nil || received["name"] != "gopher" {
+			t.Errorf("expected fields to be named after json tags, got %v, %v", received, err)
+		}
+
+		w.Header().Set("Content-Type", "application/x-msgpack")
+		_, _ = w.Write(body)
+	}))
+	defer ts.Close()
+
+	req, err := httpr.NewRequest().
+		Post(ts.URL, nil).
+		SetCodecBody(httpr.MediaTypeMsgPack, user{ID: 42, Name: "gopher"}).
+		SetContext(context.Background()).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	resp, err := httpr.New().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+
+	var echoed user
+	if err = resp.Decode(&echoed); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if echoed != (user{ID: 42, Name: "gopher"}) {
+		t.Fatalf("expected echoed user, got %+v instead", echoed)
+	}
+}
+
+func TestMsgPackMapBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var received map[string]any
+		if err := msgpack.Unmarshal(body, &received); err != nil || received["name"] != "gopher" {
+			t.Errorf("expected map to be sent as msgpack, got %q, %v", body, err)
+		}
+	}))
+	defer ts.Close()
+
+	req, err := httpr.NewRequest().
+		Post(ts.URL, nil).
+		SetHeader("Content-Type", httpr.MediaTypeMsgPack).
+		SetBody(map[string]any{"name": "gopher"}).
+		SetContext(context.Background()).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	if _, err = httpr.New().Do(req); err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+}
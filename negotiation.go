@@ -0,0 +1,79 @@
+package httpr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// qualityValue is a content negotiation header element with its relative quality (weight).
+type qualityValue struct {
+	value   string
+	quality float64
+}
+
+func newQualityValue(value string, quality []float64) qualityValue {
+	qv := qualityValue{value: value, quality: 1}
+	if len(quality) > 0 {
+		qv.quality = quality[0]
+	}
+
+	switch {
+	case qv.quality < 0:
+		qv.quality = 0
+	case qv.quality > 1:
+		qv.quality = 1
+	}
+
+	return qv
+}
+
+// formatQualityValues composes header value like "application/json, application/xml;q=0.5".
+// Quality is omitted for elements with default weight of 1.
+func formatQualityValues(values []qualityValue) string {
+	parts := make([]string, 0, len(values))
+	for _, qv := range values {
+		quality := formatQuality(qv.quality)
+		if quality == "1" {
+			parts = append(parts, qv.value)
+			continue
+		}
+
+		parts = append(parts, qv.value+";q="+quality)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// formatQuality formats weight with at most three decimal digits, as RFC 9110 (section 12.4.2) allows,
+// and without trailing zeros, e.g. 1/3 is formatted as "0.333" and 0.5 as "0.5".
+func formatQuality(quality float64) string {
+	formatted := strconv.FormatFloat(quality, 'f', 3, 64)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
+
+// formatAcceptLanguage composes 'Accept-Language' header value from language tags listed in
+// order of preference. Tags without explicit quality value get decreasing weights, e.g.
+// ("en-US", "en", "de;q=0.5") results in "en-US, en;q=0.9, de;q=0.5".
+func formatAcceptLanguage(tags []string) string {
+	values := make([]qualityValue, 0, len(tags))
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		if strings.Contains(tag, ";") {
+			values = append(values, qualityValue{value: tag, quality: 1})
+			continue
+		}
+
+		quality := float64(10-i) / 10
+		if quality < 0.1 {
+			quality = 0.1
+		}
+		values = append(values, qualityValue{value: tag, quality: quality})
+	}
+
+	return formatQualityValues(values)
+}
@@ -0,0 +1,406 @@
+package httpr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OpenAPIRecorder accumulates method, path, query parameters, status codes and shapes of JSON
+// request and response bodies of executed requests and composes draft OpenAPI 3 document
+// from them. It's handy for documenting third-party APIs, which are only known through usage:
+//
+//	recorder := httpr.NewOpenAPIRecorder("Payments API", "draft")
+//	client := httpr.New(httpr.WithOpenAPIRecorder(recorder))
+//	// ... run test suite ...
+//	err := recorder.WriteJSON(file)
+//
+// Path segments looking like identifiers (numbers, UUIDs and long hex strings) are replaced
+// with path parameters, so "/users/42" and "/users/43" are recorded as "/users/{userId}".
+// OpenAPIRecorder is safe for concurrent use.
+type OpenAPIRecorder struct {
+	title   string
+	version string
+
+	mu      sync.Mutex
+	servers map[string]struct{}
+	paths   map[string]map[string]*recordedOperation
+}
+
+type recordedOperation struct {
+	pathParams  []string
+	queryParams map[string]struct{}
+	requestBody map[string]*openAPISchema
+	responses   map[int]map[string]*openAPISchema
+}
+
+// openAPISchema is subset of OpenAPI schema object, which can be inferred from JSON values.
+type openAPISchema struct {
+	Type       string                    `json:"type,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Items      *openAPISchema            `json:"items,omitempty"`
+	Nullable   bool                      `json:"nullable,omitempty"`
+
+	// mixed is set, when values of different types were observed.
+	mixed bool
+}
+
+var (
+	_uuidSegmentRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	_hexSegmentRegexp  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+	_numSegmentRegexp  = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// NewOpenAPIRecorder creates OpenAPIRecorder, which composes document with provided title and version.
+func NewOpenAPIRecorder(title, version string) *OpenAPIRecorder {
+	return &OpenAPIRecorder{
+		title:   title,
+		version: version,
+		servers: make(map[string]struct{}),
+		paths:   make(map[string]map[string]*recordedOperation),
+	}
+}
+
+// Record adds request and its response to recorded traffic. Request body is read with
+// http.Request.GetBody, so it's only recorded for rewindable bodies.
+func (r *OpenAPIRecorder) Record(req *http.Request, resp *Response) {
+	if req == nil || req.URL == nil {
+		return
+	}
+
+	path, params := templatePath(req.URL.EscapedPath())
+	reqMediaType, reqSchema := requestBodySchema(req)
+
+	var (
+		status        int
+		respMediaType string
+		respSchema    *openAPISchema
+	)
+	if resp != nil && resp.rawResp != nil {
+		status = resp.StatusCode()
+		if body, err := resp.bodyBytes(); err == nil {
+			respMediaType, respSchema = bodySchema(resp.Header().Get("Content-Type"), body)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Host != "" {
+		r.servers[req.URL.Scheme+"://"+req.URL.Host] = struct{}{}
+	}
+
+	methods, ok := r.paths[path]
+	if !ok {
+		methods = make(map[string]*recordedOperation)
+		r.paths[path] = methods
+	}
+	method := strings.ToLower(composeMethod(req.Method))
+	op, ok := methods[method]
+	if !ok {
+		op = &recordedOperation{
+			pathParams:  params,
+			queryParams: make(map[string]struct{}),
+			requestBody: make(map[string]*openAPISchema),
+			responses:   make(map[int]map[string]*openAPISchema),
+		}
+		methods[method] = op
+	}
+
+	for name := range req.URL.Query() {
+		op.queryParams[name] = struct{}{}
+	}
+	if reqMediaType != "" {
+		op.requestBody[reqMediaType] = mergeSchemas(op.requestBody[reqMediaType], reqSchema)
+	}
+	if status != 0 {
+		content, ok := op.responses[status]
+		if !ok {
+			content = make(map[string]*openAPISchema)
+			op.responses[status] = content
+		}
+		if respMediaType != "" {
+			content[respMediaType] = mergeSchemas(content[respMediaType], respSchema)
+		}
+	}
+}
+
+// Document returns draft OpenAPI 3 document composed from recorded traffic.
+func (r *OpenAPIRecorder) Document() map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	servers := make([]map[string]any, 0, len(r.servers))
+	for _, server := range sortedKeys(r.servers) {
+		servers = append(servers, map[string]any{"url": server})
+	}
+
+	paths := make(map[string]any, len(r.paths))
+	for path, methods := range r.paths {
+		item := make(map[string]any, len(methods))
+		for method, op := range methods {
+			item[method] = op.document()
+		}
+		paths[path] = item
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   r.title,
+			"version": r.version,
+		},
+		"paths": paths,
+	}
+	if len(servers) > 0 {
+		doc["servers"] = servers
+	}
+
+	return doc
+}
+
+// WriteJSON writes indented JSON representation of document, see Document.
+func (r *OpenAPIRecorder) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Document())
+}
+
+func (op *recordedOperation) document() map[string]any {
+	doc := make(map[string]any)
+
+	params := make([]map[string]any, 0, len(op.pathParams)+len(op.queryParams))
+	for _, name := range op.pathParams {
+		params = append(params, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+	for _, name := range sortedKeys(op.queryParams) {
+		params = append(params, map[string]any{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		doc["parameters"] = params
+	}
+
+	if len(op.requestBody) > 0 {
+		doc["requestBody"] = map[string]any{"content": schemaContent(op.requestBody)}
+	}
+
+	responses := make(map[string]any, len(op.responses))
+	for status, content := range op.responses {
+		response := map[string]any{"description": http.StatusText(status)}
+		if len(content) > 0 {
+			response["content"] = schemaContent(content)
+		}
+		responses[strconv.Itoa(status)] = response
+	}
+	if len(responses) == 0 {
+		responses["default"] = map[string]any{"description": "Unknown response"}
+	}
+	doc["responses"] = responses
+
+	return doc
+}
+
+func schemaContent(schemas map[string]*openAPISchema) map[string]any {
+	content := make(map[string]any, len(schemas))
+	for mediaType, schema := range schemas {
+		if schema == nil {
+			schema = &openAPISchema{}
+		}
+		content[mediaType] = map[string]any{"schema": schema}
+	}
+	return content
+}
+
+// templatePath replaces identifier-like segments of escaped path with parameters,
+// named after preceding segment: "/users/42" becomes "/users/{userId}".
+func templatePath(path string) (string, []string) {
+	if path == "" {
+		return "/", nil
+	}
+
+	var (
+		segments = strings.Split(path, "/")
+		params   []string
+		used     = make(map[string]int)
+	)
+	for i, segment := range segments {
+		if !isIdentifierSegment(segment) {
+			continue
+		}
+
+		name := "id"
+		if i > 0 && segments[i-1] != "" && !strings.HasPrefix(segments[i-1], "{") {
+			name = strings.TrimSuffix(segments[i-1], "s") + "Id"
+		}
+		if n := used[name]; n > 0 {
+			used[name]++
+			name += strconv.Itoa(n + 1)
+		} else {
+			used[name] = 1
+		}
+
+		params = append(params, name)
+		segments[i] = "{" + name + "}"
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+func isIdentifierSegment(segment string) bool {
+	return _numSegmentRegexp.MatchString(segment) ||
+		_uuidSegmentRegexp.MatchString(segment) ||
+		_hexSegmentRegexp.MatchString(segment)
+}
+
+// requestBodySchema returns media type and schema of request body, if it can be rewound.
+func requestBodySchema(req *http.Request) (string, *openAPISchema) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return "", nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", nil
+	}
+
+	return bodySchema(req.Header.Get("Content-Type"), data)
+}
+
+// bodySchema infers schema of body with provided Content-Type. Only JSON and form bodies
+// are inspected, schema of others is left empty.
+func bodySchema(contentType string, body []byte) (string, *openAPISchema) {
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "application/octet-stream", nil
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+
+		var v any
+		if err = dec.Decode(&v); err != nil {
+			return mediaType, nil
+		}
+		return mediaType, inferSchema(v)
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return mediaType, nil
+		}
+		schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema, len(values))}
+		for name := range values {
+			schema.Properties[name] = &openAPISchema{Type: "string"}
+		}
+		return mediaType, schema
+	default:
+		return mediaType, nil
+	}
+}
+
+// inferSchema infers schema of value decoded from JSON with json.Decoder.UseNumber.
+func inferSchema(v any) *openAPISchema {
+	switch v := v.(type) {
+	case nil:
+		return &openAPISchema{Nullable: true}
+	case bool:
+		return &openAPISchema{Type: "boolean"}
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return &openAPISchema{Type: "integer"}
+		}
+		return &openAPISchema{Type: "number"}
+	case string:
+		return &openAPISchema{Type: "string"}
+	case []any:
+		schema := &openAPISchema{Type: "array"}
+		for _, item := range v {
+			schema.Items = mergeSchemas(schema.Items, inferSchema(item))
+		}
+		if schema.Items == nil {
+			schema.Items = &openAPISchema{}
+		}
+		return schema
+	case map[string]any:
+		schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema, len(v))}
+		for key, value := range v {
+			schema.Properties[key] = inferSchema(value)
+		}
+		return schema
+	default:
+		return &openAPISchema{}
+	}
+}
+
+// mergeSchemas returns schema describing values of both provided schemas. Properties of objects
+// are united, integers are widened to numbers and conflicting types are dropped.
+func mergeSchemas(a, b *openAPISchema) *openAPISchema {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+
+	nullable := a.Nullable || b.Nullable
+	switch {
+	case a.Type == "" && !a.mixed:
+		merged := *b
+		merged.Nullable = nullable
+		return &merged
+	case b.Type == "" && !b.mixed:
+		merged := *a
+		merged.Nullable = nullable
+		return &merged
+	}
+
+	merged := &openAPISchema{Type: a.Type, Nullable: nullable}
+	switch {
+	case a.mixed || b.mixed:
+		merged.Type, merged.mixed = "", true
+	case a.Type == b.Type && a.Type == "object":
+		merged.Properties = make(map[string]*openAPISchema, len(a.Properties)+len(b.Properties))
+		for key, schema := range a.Properties {
+			merged.Properties[key] = schema
+		}
+		for key, schema := range b.Properties {
+			merged.Properties[key] = mergeSchemas(merged.Properties[key], schema)
+		}
+	case a.Type == b.Type && a.Type == "array":
+		merged.Items = mergeSchemas(a.Items, b.Items)
+	case a.Type == b.Type:
+	case (a.Type == "integer" || a.Type == "number") && (b.Type == "integer" || b.Type == "number"):
+		merged.Type = "number"
+	default:
+		merged.Type, merged.mixed = "", true
+	}
+
+	return merged
+}
@@ -0,0 +1,146 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIRecorder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		case strings.HasSuffix(req.URL.Path, "/42"):
+			_, _ = w.Write([]byte(`{"id": 42, "name": "alice", "tags": ["a"], "score": 1}`))
+		default:
+			_, _ = w.Write([]byte(`{"id": 43, "name": null, "tags": [], "score": 1.5, "active": true}`))
+		}
+	}))
+	defer ts.Close()
+
+	recorder := NewOpenAPIRecorder("Test API", "draft")
+	client := New(WithOpenAPIRecorder(recorder))
+
+	ctx := context.Background()
+	for _, path := range []string{"/users/42?expand=posts", "/users/43"} {
+		if _, err := client.Get(ctx, ts.URL+path, nil); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	rb := NewRequest().Post(ts.URL+"/users", map[string]any{"name": "bob"}).SetHeader("Content-Type", "application/json")
+	if _, err := client.DoBuilder(rb); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.WriteJSON(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to unmarshal document: %s", err)
+	}
+
+	expected := map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": "Test API", "version": "draft"},
+		"servers": []any{map[string]any{"url": ts.URL}},
+		"paths": map[string]any{
+			"/users/{userId}": map[string]any{
+				"get": map[string]any{
+					"parameters": []any{
+						map[string]any{"name": "userId", "in": "path", "required": true, "schema": map[string]any{"type": "string"}},
+						map[string]any{"name": "expand", "in": "query", "schema": map[string]any{"type": "string"}},
+					},
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "OK",
+							"content": map[string]any{
+								"application/json": map[string]any{
+									"schema": map[string]any{
+										"type": "object",
+										"properties": map[string]any{
+											"id":     map[string]any{"type": "integer"},
+											"name":   map[string]any{"type": "string", "nullable": true},
+											"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+											"score":  map[string]any{"type": "number"},
+											"active": map[string]any{"type": "boolean"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"/users": map[string]any{
+				"post": map[string]any{
+					"requestBody": map[string]any{
+						"content": map[string]any{
+							"application/json": map[string]any{
+								"schema": map[string]any{
+									"type":       "object",
+									"properties": map[string]any{"name": map[string]any{"type": "string"}},
+								},
+							},
+						},
+					},
+					"responses": map[string]any{
+						"201": map[string]any{
+							"description": "Created",
+							"content": map[string]any{
+								"application/json": map[string]any{
+									"schema": map[string]any{
+										"type":       "object",
+										"properties": map[string]any{"id": map[string]any{"type": "integer"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("expected document:\n%v\ngot:\n%s instead", expected, buf.String())
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+		params   []string
+	}{
+		{path: "", expected: "/"},
+		{path: "/users", expected: "/users"},
+		{path: "/users/42/posts/7", expected: "/users/{userId}/posts/{postId}", params: []string{"userId", "postId"}},
+		{path: "/42", expected: "/{id}", params: []string{"id"}},
+		{path: "/orders/5f0c1e2a-9a3b-4c7d-8e6f-0a1b2c3d4e5f", expected: "/orders/{orderId}", params: []string{"orderId"}},
+		{path: "/blobs/0123456789abcdef0123", expected: "/blobs/{blobId}", params: []string{"blobId"}},
+		{path: "/pairs/1/2", expected: "/pairs/{pairId}/{id}", params: []string{"pairId", "id"}},
+		{path: "/v2/items", expected: "/v2/items"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, params := templatePath(tc.path)
+			if path != tc.expected {
+				t.Errorf("expected path %q, got %q instead", tc.expected, path)
+			}
+			if !reflect.DeepEqual(params, tc.params) {
+				t.Errorf("expected params %v, got %v instead", tc.params, params)
+			}
+		})
+	}
+}
@@ -0,0 +1,600 @@
+// Package openapispec builds httpr requests from operations of OpenAPI 3 documents and validates
+// them against operation schemas. It's separate module, so core httpr module stays free of dependencies.
+package openapispec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hickar/httpr"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrContractViolation is returned when request built from OpenAPI operation doesn't match
+// its schema, see Spec.NewRequestFromOperation.
+var ErrContractViolation = errors.New("OpenAPI contract violation")
+
+// Spec is OpenAPI 3 document, from which requests are built with NewRequestFromOperation.
+// Parameters and JSON bodies of built requests are validated against operation schemas, so contract
+// violations are caught on client side before request is sent.
+type Spec struct {
+	server     string
+	operations map[string]*specOperation
+	schemas    map[string]*specSchema
+	parameters map[string]*specParameter
+}
+
+// OperationParams holds parameters and body of request built from OpenAPI operation.
+type OperationParams struct {
+	Path   map[string]string
+	Query  map[string]string
+	Header map[string]string
+	// Body is marshaled with codec of operation request body media type, JSON bodies are validated
+	// against schema. Raw bodies ([]byte, string and readers) are sent as is.
+	Body any
+}
+
+type specDocument struct {
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+
+	Components struct {
+		Schemas    map[string]*specSchema    `json:"schemas"`
+		Parameters map[string]*specParameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type specOperation struct {
+	method      string
+	path        string
+	parameters  []*specParameter
+	requestBody *specRequestBody
+}
+
+type specOperationObject struct {
+	OperationID string           `json:"operationId"`
+	Parameters  []*specParameter `json:"parameters"`
+	RequestBody *specRequestBody `json:"requestBody"`
+}
+
+type specParameter struct {
+	Ref      string      `json:"$ref"`
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Schema   *specSchema `json:"schema"`
+}
+
+type specRequestBody struct {
+	Required bool `json:"required"`
+	Content  map[string]struct {
+		Schema *specSchema `json:"schema"`
+	} `json:"content"`
+}
+
+type specSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Nullable             bool                   `json:"nullable"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*specSchema `json:"properties"`
+	AdditionalProperties any                    `json:"additionalProperties"`
+	Items                *specSchema            `json:"items"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	Pattern              string                 `json:"pattern"`
+	AllOf                []*specSchema          `json:"allOf"`
+	AnyOf                []*specSchema          `json:"anyOf"`
+	OneOf                []*specSchema          `json:"oneOf"`
+}
+
+// _specMethods are path item fields, which hold operations.
+var _specMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Load reads OpenAPI 3 document in YAML or JSON format. Requests are sent to the first server
+// of document, unless other is set with Spec.SetServer.
+func Load(r io.Reader) (*Spec, error) {
+	doc, err := decodeDocument(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI document: %w", err)
+	}
+
+	spec := &Spec{
+		operations: make(map[string]*specOperation),
+		schemas:    doc.Components.Schemas,
+		parameters: doc.Components.Parameters,
+	}
+	if len(doc.Servers) > 0 {
+		spec.server = doc.Servers[0].URL
+	}
+
+	for path, item := range doc.Paths {
+		var common []*specParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &common); err != nil {
+				return nil, fmt.Errorf("failed to decode parameters of path %q: %w", path, err)
+			}
+		}
+
+		for _, method := range _specMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+
+			var obj specOperationObject
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return nil, fmt.Errorf("failed to decode operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			if obj.OperationID == "" {
+				continue
+			}
+			if _, ok = spec.operations[obj.OperationID]; ok {
+				return nil, fmt.Errorf("duplicate operation %q", obj.OperationID)
+			}
+
+			params, err := spec.mergeParameters(common, obj.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("operation %q: %w", obj.OperationID, err)
+			}
+			spec.operations[obj.OperationID] = &specOperation{
+				method:      strings.ToUpper(method),
+				path:        path,
+				parameters:  params,
+				requestBody: obj.RequestBody,
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// LoadFile reads OpenAPI 3 document in YAML or JSON format from file, see Load.
+func LoadFile(path string) (*Spec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// decodeDocument decodes YAML document, which JSON one is subset of. Document is converted
+// to JSON first, so JSON field tags and json.RawMessage of document types apply to both formats.
+func decodeDocument(r io.Reader) (*specDocument, error) {
+	var node any
+	if err := yaml.NewDecoder(r).Decode(&node); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(jsonValue(node))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc specDocument
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// jsonValue converts YAML mappings with non-string keys, e.g. response status codes,
+// to maps with string keys, so value can be marshaled to JSON.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = jsonValue(value)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// SetServer sets base URL, which operation paths are appended to.
+func (s *Spec) SetServer(serverURL string) *Spec {
+	s.server = serverURL
+	return s
+}
+
+// NewRequestFromOperation creates httpr.RequestBuilder for operation with provided operationId.
+// Parameters and body are validated against operation schemas; violations are returned as errors
+// matching ErrContractViolation, so invalid request is never built.
+func (s *Spec) NewRequestFromOperation(operationID string, params OperationParams) (*httpr.RequestBuilder, error) {
+	op, ok := s.operations[operationID]
+	if !ok {
+		return nil, fmt.Errorf("unknown OpenAPI operation %q", operationID)
+	}
+	if err := s.validateParams(op, params); err != nil {
+		return nil, fmt.Errorf("%w: operation %q: %s", ErrContractViolation, operationID, err)
+	}
+
+	path := op.path
+	for name, value := range params.Path {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+	rb := httpr.NewRequest().SetMethod(op.method).SetURL(strings.TrimSuffix(s.server, "/") + path)
+	rb.SetQueryParams(params.Query)
+	rb.SetHeaders(params.Header)
+
+	if params.Body == nil {
+		return rb, nil
+	}
+	mediaType, schema := op.bodyContent()
+	if mediaType != "" && !hasHeader(params.Header, "Content-Type") {
+		rb.SetHeader("Content-Type", mediaType)
+	}
+	if schema != nil && isJSONMediaType(mediaType) {
+		if err := s.validateBody(schema, params.Body); err != nil {
+			return nil, fmt.Errorf("%w: operation %q: %s", ErrContractViolation, operationID, err)
+		}
+	}
+	rb.SetBody(params.Body)
+
+	return rb, nil
+}
+
+// mergeParameters resolves references of path item and operation parameters.
+// Operation parameters override path item ones with the same name and location.
+func (s *Spec) mergeParameters(common, own []*specParameter) ([]*specParameter, error) {
+	var params []*specParameter
+	for _, list := range [][]*specParameter{common, own} {
+		for _, param := range list {
+			param, err := s.resolveParameter(param)
+			if err != nil {
+				return nil, err
+			}
+
+			replaced := false
+			for i, existing := range params {
+				if existing.Name == param.Name && existing.In == param.In {
+					params[i], replaced = param, true
+				}
+			}
+			if !replaced {
+				params = append(params, param)
+			}
+		}
+	}
+
+	return params, nil
+}
+
+func (s *Spec) resolveParameter(param *specParameter) (*specParameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+
+	name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+	resolved, ok := s.parameters[name]
+	if !ok || name == param.Ref {
+		return nil, fmt.Errorf("unresolved parameter reference %q", param.Ref)
+	}
+	return resolved, nil
+}
+
+func (s *Spec) resolveSchema(schema *specSchema) (*specSchema, error) {
+	for depth := 0; schema.Ref != ""; depth++ {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := s.schemas[name]
+		if !ok || name == schema.Ref || depth > 32 {
+			return nil, fmt.Errorf("unresolved schema reference %q", schema.Ref)
+		}
+		schema = resolved
+	}
+	return schema, nil
+}
+
+// bodyContent returns media type of operation request body with its schema, preferring JSON.
+func (op *specOperation) bodyContent() (string, *specSchema) {
+	if op.requestBody == nil || len(op.requestBody.Content) == 0 {
+		return "", nil
+	}
+
+	mediaTypes := sortedKeys(op.requestBody.Content)
+	for _, mediaType := range mediaTypes {
+		if isJSONMediaType(mediaType) {
+			return mediaType, op.requestBody.Content[mediaType].Schema
+		}
+	}
+	return mediaTypes[0], op.requestBody.Content[mediaTypes[0]].Schema
+}
+
+func (s *Spec) validateParams(op *specOperation, params OperationParams) error {
+	for _, param := range op.parameters {
+		var values map[string]string
+		switch param.In {
+		case "path":
+			values = params.Path
+		case "query":
+			values = params.Query
+		case "header":
+			values = params.Header
+		default:
+			continue
+		}
+
+		value, ok := values[param.Name]
+		if !ok {
+			if param.Required || param.In == "path" {
+				return fmt.Errorf("missing required %s parameter %q", param.In, param.Name)
+			}
+			continue
+		}
+		if param.Schema == nil {
+			continue
+		}
+
+		schema, err := s.resolveSchema(param.Schema)
+		if err != nil {
+			return err
+		}
+		if err = s.validateValue(schema, parseParamValue(schema.Type, value), param.Name); err != nil {
+			return fmt.Errorf("%s parameter %s", param.In, err)
+		}
+	}
+
+	if op.requestBody != nil && op.requestBody.Required && params.Body == nil {
+		return fmt.Errorf("missing required request body")
+	}
+
+	return nil
+}
+
+// validateBody validates value against schema by its JSON representation.
+func (s *Spec) validateBody(schema *specSchema, body any) error {
+	var data []byte
+	switch body := body.(type) {
+	case []byte:
+		data = body
+	case string:
+		data = []byte(body)
+	case io.Reader:
+		return nil
+	default:
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to marshal request body: %s", err)
+		}
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("request body is not valid JSON: %s", err)
+	}
+	return s.validateValue(schema, v, "body")
+}
+
+// parseParamValue converts parameter value to type of its schema, so it can be validated
+// the same way as JSON values. Values, which can't be converted, are left as strings.
+func parseParamValue(typ, value string) any {
+	switch typ {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validateValue validates value decoded from JSON against schema. Path is used in error messages.
+func (s *Spec) validateValue(schema *specSchema, v any, path string) error {
+	schema, err := s.resolveSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range schema.AllOf {
+		if err = s.validateValue(sub, v, path); err != nil {
+			return err
+		}
+	}
+	if len(schema.AnyOf) > 0 && s.countValid(schema.AnyOf, v, path) == 0 {
+		return fmt.Errorf("%s: doesn't match any of schemas", path)
+	}
+	if len(schema.OneOf) > 0 && s.countValid(schema.OneOf, v, path) != 1 {
+		return fmt.Errorf("%s: doesn't match exactly one of schemas", path)
+	}
+
+	if v == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.Enum) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: must not be null", path)
+	}
+	if len(schema.Enum) > 0 && !containsJSONValue(schema.Enum, v) {
+		return fmt.Errorf("%s: value %v is not one of %v", path, v, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "":
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return typeMismatch(path, schema.Type, v)
+		}
+		return validateString(schema, str, path)
+	case "integer", "number":
+		num, ok := v.(float64)
+		if !ok || (schema.Type == "integer" && num != math.Trunc(num)) {
+			return typeMismatch(path, schema.Type, v)
+		}
+		if schema.Minimum != nil && num < *schema.Minimum {
+			return fmt.Errorf("%s: %v is less than minimum %v", path, num, *schema.Minimum)
+		}
+		if schema.Maximum != nil && num > *schema.Maximum {
+			return fmt.Errorf("%s: %v is greater than maximum %v", path, num, *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return typeMismatch(path, schema.Type, v)
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return typeMismatch(path, schema.Type, v)
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			return fmt.Errorf("%s: must have at least %d items", path, *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			return fmt.Errorf("%s: must have at most %d items", path, *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				if err = s.validateValue(schema.Items, item, path+"["+strconv.Itoa(i)+"]"); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return typeMismatch(path, schema.Type, v)
+		}
+		return s.validateObject(schema, obj, path)
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", path, schema.Type)
+	}
+
+	return nil
+}
+
+func (s *Spec) validateObject(schema *specSchema, obj map[string]any, path string) error {
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	for _, name := range sortedKeys(obj) {
+		prop, ok := schema.Properties[name]
+		if !ok {
+			// Schemas of additional properties aren't validated, only their prohibition is.
+			if allowed, ok := schema.AdditionalProperties.(bool); ok && !allowed {
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			}
+			continue
+		}
+		if err := s.validateValue(prop, obj[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) countValid(schemas []*specSchema, v any, path string) int {
+	var n int
+	for _, schema := range schemas {
+		if s.validateValue(schema, v, path) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func validateString(schema *specSchema, str, path string) error {
+	length := len([]rune(str))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("%s: must be at least %d characters long", path, *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("%s: must be at most %d characters long", path, *schema.MaxLength)
+	}
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %s", path, schema.Pattern, err)
+		}
+		if !re.MatchString(str) {
+			return fmt.Errorf("%s: %q doesn't match pattern %q", path, str, schema.Pattern)
+		}
+	}
+	return nil
+}
+
+func typeMismatch(path, expected string, v any) error {
+	var actual string
+	switch v.(type) {
+	case string:
+		actual = "string"
+	case float64:
+		actual = "number"
+	case bool:
+		actual = "boolean"
+	case []any:
+		actual = "array"
+	case map[string]any:
+		actual = "object"
+	default:
+		actual = fmt.Sprintf("%T", v)
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, expected, actual)
+}
+
+func containsJSONValue(values []any, v any) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func hasHeader(header map[string]string, key string) bool {
+	for name := range header {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
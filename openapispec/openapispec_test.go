@@ -0,0 +1,273 @@
+package openapispec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hickar/httpr"
+)
+
+const _testOpenAPISpec = `{
+	"openapi": "3.0.3",
+	"servers": [{"url": "https://api.example.com/v1"}],
+	"paths": {
+		"/users/{id}": {
+			"parameters": [{"$ref": "#/components/parameters/UserID"}],
+			"get": {
+				"operationId": "getUserById",
+				"parameters": [
+					{"name": "expand", "in": "query", "schema": {"type": "string", "enum": ["posts", "friends"]}},
+					{"name": "X-Request-ID", "in": "header", "required": true, "schema": {"type": "string"}}
+				]
+			},
+			"put": {
+				"operationId": "updateUser",
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}
+				}
+			}
+		}
+	},
+	"components": {
+		"parameters": {
+			"UserID": {"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "minimum": 1}}
+		},
+		"schemas": {
+			"User": {
+				"type": "object",
+				"required": ["name"],
+				"additionalProperties": false,
+				"properties": {
+					"name": {"type": "string", "minLength": 1},
+					"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+					"age": {"type": "integer", "nullable": true},
+					"roles": {"type": "array", "items": {"type": "string", "enum": ["admin", "user"]}}
+				}
+			}
+		}
+	}
+}`
+
+func TestSpecNewRequestFromOperation(t *testing.T) {
+	spec, err := Load(strings.NewReader(_testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		name      string
+		operation string
+		params    OperationParams
+		violation string
+	}{
+		{
+			name:      "valid get",
+			operation: "getUserById",
+			params: OperationParams{
+				Path:   map[string]string{"id": "42"},
+				Query:  map[string]string{"expand": "posts"},
+				Header: map[string]string{"X-Request-ID": "abc"},
+			},
+		},
+		{
+			name:      "missing path parameter",
+			operation: "getUserById",
+			params:    OperationParams{Header: map[string]string{"X-Request-ID": "abc"}},
+			violation: `missing required path parameter "id"`,
+		},
+		{
+			name:      "invalid path parameter type",
+			operation: "getUserById",
+			params:    OperationParams{Path: map[string]string{"id": "abc"}, Header: map[string]string{"X-Request-ID": "abc"}},
+			violation: "path parameter id: expected integer, got string",
+		},
+		{
+			name:      "path parameter below minimum",
+			operation: "getUserById",
+			params:    OperationParams{Path: map[string]string{"id": "0"}, Header: map[string]string{"X-Request-ID": "abc"}},
+			violation: "path parameter id: 0 is less than minimum 1",
+		},
+		{
+			name:      "query parameter not in enum",
+			operation: "getUserById",
+			params: OperationParams{
+				Path:   map[string]string{"id": "42"},
+				Query:  map[string]string{"expand": "comments"},
+				Header: map[string]string{"X-Request-ID": "abc"},
+			},
+			violation: "query parameter expand: value comments is not one of [posts friends]",
+		},
+		{
+			name:      "missing header parameter",
+			operation: "getUserById",
+			params:    OperationParams{Path: map[string]string{"id": "42"}},
+			violation: `missing required header parameter "X-Request-ID"`,
+		},
+		{
+			name:      "valid body",
+			operation: "updateUser",
+			params: OperationParams{
+				Path: map[string]string{"id": "42"},
+				Body: map[string]any{"name": "alice", "email": "alice@example.com", "age": nil, "roles": []string{"admin"}},
+			},
+		},
+		{
+			name:      "missing body",
+			operation: "updateUser",
+			params:    OperationParams{Path: map[string]string{"id": "42"}},
+			violation: "missing required request body",
+		},
+		{
+			name:      "missing required property",
+			operation: "updateUser",
+			params:    OperationParams{Path: map[string]string{"id": "42"}, Body: map[string]any{"email": "alice@example.com"}},
+			violation: `body: missing required property "name"`,
+		},
+		{
+			name:      "unexpected property",
+			operation: "updateUser",
+			params:    OperationParams{Path: map[string]string{"id": "42"}, Body: map[string]any{"name": "alice", "nick": "al"}},
+			violation: `body: unexpected property "nick"`,
+		},
+		{
+			name:      "invalid nested item",
+			operation: "updateUser",
+			params:    OperationParams{Path: map[string]string{"id": "42"}, Body: `{"name": "alice", "roles": ["root"]}`},
+			violation: "body.roles[0]: value root is not one of [admin user]",
+		},
+		{
+			name:      "pattern mismatch",
+			operation: "updateUser",
+			params:    OperationParams{Path: map[string]string{"id": "42"}, Body: map[string]any{"name": "alice", "email": "alice"}},
+			violation: `body.email: "alice" doesn't match pattern "^[^@]+@[^@]+$"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rb, err := spec.NewRequestFromOperation(tc.operation, tc.params)
+			if err == nil {
+				_, err = rb.Build()
+			}
+			if tc.violation == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrContractViolation) {
+				t.Fatalf("expected ErrContractViolation, got %v instead", err)
+			}
+			if !strings.HasSuffix(err.Error(), tc.violation) {
+				t.Errorf("expected error ending with %q, got %q instead", tc.violation, err)
+			}
+		})
+	}
+}
+
+func TestSpecSend(t *testing.T) {
+	var (
+		method, path, contentType, body string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		method, path, contentType = req.Method, req.URL.Path, req.Header.Get("Content-Type")
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}))
+	defer ts.Close()
+
+	spec, err := Load(strings.NewReader(_testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	spec.SetServer(ts.URL + "/v1/")
+
+	rb, err := spec.NewRequestFromOperation("updateUser", OperationParams{
+		Path: map[string]string{"id": "42"},
+		Body: map[string]any{"name": "alice"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = httpr.New().DoBuilder(rb.SetContext(context.Background())); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if method != http.MethodPut || path != "/v1/users/42" {
+		t.Errorf("expected PUT /v1/users/42, got %s %s instead", method, path)
+	}
+	if contentType != "application/json" || body != `{"name":"alice"}` {
+		t.Errorf("expected JSON body, got %q (%s) instead", body, contentType)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+	}{
+		{name: "invalid JSON", spec: `{`},
+		{name: "invalid YAML", spec: "paths:\n  - a\n b"},
+		{name: "unresolved parameter", spec: `{"paths": {"/a": {"get": {"operationId": "a", "parameters": [{"$ref": "#/components/parameters/X"}]}}}}`},
+		{name: "duplicate operation", spec: `{"paths": {"/a": {"get": {"operationId": "a"}}, "/b": {"get": {"operationId": "a"}}}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(tc.spec)); err == nil {
+				t.Error("expected error, got nil instead")
+			}
+		})
+	}
+}
+
+const _testYAMLSpec = `
+openapi: 3.0.3
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{id}:
+    get:
+      operationId: getUserById
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+            minimum: 1
+      responses:
+        200:
+          description: User
+`
+
+func TestLoadYAML(t *testing.T) {
+	spec, err := Load(strings.NewReader(_testYAMLSpec))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rb, err := spec.NewRequestFromOperation("getUserById", OperationParams{Path: map[string]string{"id": "42"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	req, err := rb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "https://api.example.com/v1/users/42"; req.URL.String() != expected {
+		t.Errorf("expected URL %q, got %q instead", expected, req.URL)
+	}
+
+	_, err = spec.NewRequestFromOperation("getUserById", OperationParams{Path: map[string]string{"id": "0"}})
+	if !errors.Is(err, ErrContractViolation) {
+		t.Errorf("expected ErrContractViolation, got %v instead", err)
+	}
+}
@@ -1,21 +1,26 @@
 package httpr
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 	"time"
 )
 
 func newDefaultSettings() clientSettings {
 	return clientSettings{
-		redirectCheckFn:   func(_ *http.Request, _ []*http.Request) error { return nil },
 		preRequestHookFn:  func(_ *http.Request) error { return nil },
 		postRequestHookFn: func(_ *http.Request, _ *Response) {},
-		retryConditionFn:  func(_ *Response, err error) bool { return true },
+		retryConditionFn:  DefaultRetryCondition,
 	}
 }
 
 // Option is a function type used for altering client-scoped or request-scoped settings like
-// retry count, retry delay, timeout and others.
+// retry count, retry delay, timeout and others. Since settings are copied by value, options
+// must never modify maps or slices, which settings refer to, in place.
 type Option func(settings *clientSettings)
 
 // WithRetryCount sets number of retries used for request being carried. If requests number failed equals
@@ -34,6 +39,14 @@ func WithRetryDelay(delay time.Duration) Option {
 	}
 }
 
+// WithRetryJitter sets function, which randomizes delays between retries, so clients failed at the same
+// time don't retry in lockstep. Methods of Jitter can be used, e.g. WithRetryJitter(NewJitter(nil).Full).
+func WithRetryJitter(jitter JitterFunc) Option {
+	return func(settings *clientSettings) {
+		settings.retryJitterFn = jitter
+	}
+}
+
 // WithRetryDelayDelta is used to specify delay delta being added to delay time after each unsuccessful request.
 // This option is ignored if retry count is not set.
 func WithRetryDelayDelta(delayDelta time.Duration) Option {
@@ -42,6 +55,24 @@ func WithRetryDelayDelta(delayDelta time.Duration) Option {
 	}
 }
 
+// WithRetryBackoff sets multiplier, which delay is multiplied by after each unsuccessful request, so delays
+// grow exponentially, e.g. with retry delay of 100ms and multiplier 2 requests are retried after 100ms, 200ms,
+// 400ms and so on. Delay delta set with WithRetryDelayDelta is added after multiplication.
+// Multipliers not greater than 1 are ignored. This option is ignored if retry count is not set.
+func WithRetryBackoff(multiplier float64) Option {
+	return func(settings *clientSettings) {
+		settings.retryBackoffMultiplier = multiplier
+	}
+}
+
+// WithRetryMaxDelay limits delay between retries, which grows with WithRetryDelayDelta and WithRetryBackoff.
+// Jitter set with WithRetryJitter is applied to limited delay. Zero means no limit.
+func WithRetryMaxDelay(maxDelay time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.retryMaxDelay = maxDelay
+	}
+}
+
 // WithTransport is used to change http.Transport used.
 func WithTransport(transport http.RoundTripper) Option {
 	return func(settings *clientSettings) {
@@ -51,14 +82,35 @@ func WithTransport(transport http.RoundTripper) Option {
 	}
 }
 
-// WithTimeout specified timeout for request being executed. If response wasn't received within specified timeout,
-// Client.Do and all shortcut methods return context.DeadlineExceeded.
+// WithTimeout sets timeout of single request attempt, covering connection establishment, TLS handshake,
+// sending request, waiting for response headers and reading response body. Attempt, which exceeded
+// timeout, fails with error wrapping context.DeadlineExceeded and can be retried according to retry
+// condition. For Client.DoRaw timeout covers reading of returned response body as well.
+// Use WithTotalTimeout to bound request execution including retries and delays between them.
 func WithTimeout(timeout time.Duration) Option {
 	return func(settings *clientSettings) {
 		settings.timeout = timeout
 	}
 }
 
+// WithTotalTimeout sets timeout of whole request execution, including all attempts, delays between them
+// and waiting for rate limiter. When it's exceeded, Client.Do and all shortcut methods return error
+// wrapping context.DeadlineExceeded.
+func WithTotalTimeout(timeout time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.totalTimeout = timeout
+	}
+}
+
+// WithResponseHeaderTimeout sets ResponseHeaderTimeout of client *http.Transport, which limits time
+// of waiting for response headers after request is fully sent. It doesn't limit time of reading
+// response body. Option has effect only when passed to client constructor or Client.With.
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.responseHeaderTimeout = timeout
+	}
+}
+
 // WithCheckRedirect sets middleware function for specifying request redirect policy.
 func WithCheckRedirect(checkFn func(*http.Request, []*http.Request) error) Option {
 	return func(settings *clientSettings) {
@@ -68,17 +120,386 @@ func WithCheckRedirect(checkFn func(*http.Request, []*http.Request) error) Optio
 	}
 }
 
+// WithMaxRedirects limits number of redirects followed by client. If limit is exceeded,
+// request fails with ErrTooManyRedirects.
+func WithMaxRedirects(maxRedirects int) Option {
+	return WithCheckRedirect(MaxRedirects(maxRedirects))
+}
+
+// WithNoRedirects disables following of redirects. Redirect response is returned
+// as is and can be inspected with Response.Location.
+func WithNoRedirects() Option {
+	return WithCheckRedirect(func(_ *http.Request, _ []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+}
+
 // RetryConditionFunc is function, used for specifying whether request execution must be
 // attempted again. Function must return true is retry is needed, false if not.
 type RetryConditionFunc func(*Response, error) bool
 
-// WithRetryCondition sets RetryConditionFunc middleware.
+// WithRetryCondition sets RetryConditionFunc middleware, replacing DefaultRetryCondition. Conditions
+// can be composed from presets:
+//
+//	httpr.WithRetryCondition(httpr.Or(httpr.RetryOnNetworkError, httpr.RetryOnStatus(http.StatusConflict)))
 func WithRetryCondition(conditionFn RetryConditionFunc) Option {
 	return func(settings *clientSettings) {
 		settings.retryConditionFn = conditionFn
 	}
 }
 
+// WithAttemptRetryCondition sets AttemptRetryConditionFunc, which takes precedence over RetryConditionFunc
+// set with WithRetryCondition.
+func WithAttemptRetryCondition(conditionFn AttemptRetryConditionFunc) Option {
+	return func(settings *clientSettings) {
+		settings.attemptRetryConditionFn = conditionFn
+	}
+}
+
+// WithErrorDecoder sets function, which converts unsuccessful (4xx or 5xx) responses into errors
+// returned by Client.Do along with response. It takes precedence over error models set with WithErrorModel.
+// Error decoding doesn't take place for responses returned by Client.DoRaw.
+func WithErrorDecoder(decoderFn ErrorDecoderFunc) Option {
+	return func(settings *clientSettings) {
+		settings.errorDecoderFn = decoderFn
+	}
+}
+
+// WithErrorModel makes Client.Do unmarshal body of responses with provided status code into new value
+// of prototype type and return it as error along with response. Zero status matches all 4xx and 5xx responses
+// without explicitly registered model. Body is decoded with codec registered for response Content-Type
+// (see RegisterCodec), falling back to JSON. Nil prototype removes model registered for status.
+// Prototype must implement error interface, e.g.:
+//
+//	type APIError struct {
+//		Code    string `json:"code"`
+//		Message string `json:"message"`
+//	}
+//
+//	func (e *APIError) Error() string { return e.Code + ": " + e.Message }
+//
+//	client := httpr.New(httpr.WithErrorModel(0, &APIError{}))
+//	_, err := client.Get(ctx, "https://mysite.com/items", nil)
+//	var apiErr *APIError
+//	if errors.As(err, &apiErr) {
+//		...
+//	}
+func WithErrorModel(status int, prototype any) Option {
+	return func(settings *clientSettings) {
+		errorModels := make(map[int]reflect.Type, len(settings.errorModels)+1)
+		for code, modelType := range settings.errorModels {
+			errorModels[code] = modelType
+		}
+		if prototype != nil {
+			errorModels[status] = reflect.TypeOf(prototype)
+		} else {
+			delete(errorModels, status)
+		}
+		settings.errorModels = errorModels
+	}
+}
+
+// WithErrorTarget sets factory of values, in which bodies of unsuccessful (non-2xx) responses are decoded
+// by Client.Do. Decoded value is available with Response.ErrorResult, while successful responses are decoded
+// as usual, e.g. with Response.JSON. Body is decoded with codec registered for response Content-Type
+// (see RegisterCodec), falling back to JSON:
+//
+//	client := httpr.New(httpr.WithErrorTarget(func() any { return &APIError{} }))
+//	resp, err := client.Get(ctx, "https://mysite.com/items/1", nil)
+//	if err != nil {
+//		return err
+//	}
+//	if apiErr, ok := resp.ErrorResult().(*APIError); ok {
+//		...
+//	}
+func WithErrorTarget(factory func() any) Option {
+	return func(settings *clientSettings) {
+		settings.errorTargetFn = factory
+	}
+}
+
+// WithFailOnStatus makes Client.Do return *ResponseError along with response, when response status
+// satisfies any of provided predicates. If no predicates are passed, 4xx and 5xx statuses are
+// considered unsuccessful. Responses with "application/problem+json" media type produce *ProblemError.
+// Errors decoded with WithErrorDecoder and WithErrorModel take precedence. For example, to fail
+// only on server errors:
+//
+//	client := httpr.New(httpr.WithFailOnStatus(httpr.Is5xx))
+func WithFailOnStatus(predicates ...func(code int) bool) Option {
+	return func(settings *clientSettings) {
+		if len(predicates) == 0 {
+			settings.failOnStatusFn = func(code int) bool {
+				return Is4xx(code) || Is5xx(code)
+			}
+			return
+		}
+
+		settings.failOnStatusFn = func(code int) bool {
+			for _, predicate := range predicates {
+				if predicate(code) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+}
+
+// WithMaxResponseSize limits size of buffered response body (after decompression) to provided number of bytes.
+// If body exceeds limit, Client.Do returns error wrapping ErrResponseTooLarge. Zero or negative size means no limit.
+// Limit isn't applied to responses returned by Client.DoRaw.
+func WithMaxResponseSize(size int64) Option {
+	return func(settings *clientSettings) {
+		settings.maxResponseSize = size
+	}
+}
+
+// WithBodySpillThreshold makes client write response bodies larger than threshold bytes to temporary file
+// instead of keeping them in memory. Spilled body is streamed with Response.Reader and Response.SaveFile,
+// while other accessors read it from file. Charset decoding isn't applied to spilled bodies.
+// Temporary file is removed with Response.Close (or when response is garbage collected), so callers
+// should close responses, which may be spilled:
+//
+//	resp, err := client.Get(ctx, "https://mysite.com/export.csv", nil, httpr.WithBodySpillThreshold(8<<20))
+//	if err != nil {
+//		return err
+//	}
+//	defer resp.Close()
+//	_, err = io.Copy(dst, resp.Reader())
+func WithBodySpillThreshold(threshold int64) Option {
+	return func(settings *clientSettings) {
+		settings.bodySpillThreshold = threshold
+	}
+}
+
+// WithAutoRateLimitRetry makes client wait and retry requests, which received 429 Too Many Requests response.
+// Delay is taken from Retry-After header, falling back to reset time of rate limit headers (see Response.RateLimit)
+// and to one second. Throttled retries don't count towards retry count set with WithRetryCount. Requests are retried
+// until total wait time would exceed maxWait, but no more than 10 times. Zero or negative maxWait disables automatic retry.
+func WithAutoRateLimitRetry(maxWait time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.rateLimitMaxWait = maxWait
+	}
+}
+
+// WithOnThrottled sets ThrottledFunc compliant function, which is called before throttled request is retried
+// by client with automatic rate limit retry enabled, see WithAutoRateLimitRetry.
+func WithOnThrottled(throttledFn ThrottledFunc) Option {
+	return func(settings *clientSettings) {
+		settings.throttledFn = throttledFn
+	}
+}
+
+// WithRobotsPolicy sets robots.txt compliance policy. With RobotsRespect policy client fetches robots.txt
+// of each requested host (caching it for 24 hours) and checks rules for request User-Agent before request
+// execution. Requests disallowed by robots.txt fail with ErrDisallowedByRobots. Crawl-delay directive
+// is honored as minimal interval between requests to the host, see also WithPerHostDelay.
+// Passing this option to client constructor makes all requests share robots.txt cache.
+func WithRobotsPolicy(policy RobotsPolicy) Option {
+	return func(settings *clientSettings) {
+		settings.robotsPolicy = policy
+		if policy == RobotsRespect && settings.robotsCache == nil {
+			settings.robotsCache = newRobotsCache()
+		}
+	}
+}
+
+// WithPerHostDelay enforces minimal interval between requests (including retries) to the same host,
+// independently of rate limiter set with WithRateLimiter. Intervals for specific hosts can be set with
+// overrides map, which is keyed by host name without port. If robots.txt compliance is enabled with
+// WithRobotsPolicy, longer Crawl-delay from robots.txt takes precedence. Passing this option to client
+// constructor makes all requests share per-host schedule.
+func WithPerHostDelay(defaultDelay time.Duration, overrides map[string]time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.hostDelayer = newHostDelayer(defaultDelay, overrides)
+	}
+}
+
+// WithBandwidthLimit limits download (response body) and upload (request body) throughput to provided
+// number of bytes per second, so background jobs don't saturate network. Non-positive limit leaves
+// corresponding direction unlimited. Passing this option to client constructor makes all requests
+// share bandwidth, while request-scoped option limits single request only.
+func WithBandwidthLimit(readBytesPerSec, writeBytesPerSec int64) Option {
+	return func(settings *clientSettings) {
+		settings.bandwidth = &bandwidthLimits{
+			read:  newTokenBucket(readBytesPerSec),
+			write: newTokenBucket(writeBytesPerSec),
+		}
+	}
+}
+
+// WithClock sets Clock used for retry delays, rate limit waits and per-host delays. It's intended
+// for tests, which can use FakeClock to run retry and backoff scenarios instantly:
+//
+//	clock := httpr.NewFakeClock(time.Now())
+//	client := httpr.New(httpr.WithRetryCount(3), httpr.WithRetryDelay(time.Minute), httpr.WithClock(clock))
+func WithClock(clock Clock) Option {
+	return func(settings *clientSettings) {
+		settings.clockSource = clock
+	}
+}
+
+// WithTags adds request tags, e.g. logical operation name. Tags are passed to StatsHandler events,
+// request context (see TagsFromContext) and Response. Tags set with RequestBuilder.SetTag
+// take precedence over ones set with this option.
+func WithTags(tags map[string]string) Option {
+	return func(settings *clientSettings) {
+		settings.tags = mergeTags(settings.tags, tags)
+	}
+}
+
+// WithOpenAPIRecorder sets OpenAPIRecorder, which records every successfully executed request
+// and its final response. See OpenAPIRecorder for details.
+func WithOpenAPIRecorder(recorder *OpenAPIRecorder) Option {
+	return func(settings *clientSettings) {
+		settings.openAPIRecorder = recorder
+	}
+}
+
+// WithCache enables caching of GET responses in provided cache, following RFC 9111 rules for private
+// caches: fresh responses are served without contacting origin (see Response.FromCache), stale ones
+// are revalidated with conditional requests, and successful requests with unsafe methods invalidate
+// stored response of their URL. Only buffered responses are cached, Client.DoRaw bypasses cache.
+// Nil cache disables caching.
+//
+//	client := httpr.New(httpr.WithCache(httpr.NewMemoryCache(500)))
+func WithCache(cache Cache) Option {
+	return func(settings *clientSettings) {
+		settings.cache = cache
+	}
+}
+
+// WithStaleIfError makes client serve the most recent cached response (see WithCache) instead
+// of failing, when origin can't be reached or responds with 5xx status after all retries. Such
+// response is flagged with Response.Stale. Cached response is served, if it's stale by no more
+// than maxStale or its 'stale-if-error' Cache-Control directive allows it, and it doesn't require
+// revalidation with 'must-revalidate' or 'no-cache'. Zero or negative maxStale disables the feature.
+// Stale responses take precedence over fallback set with WithFallback.
+func WithStaleIfError(maxStale time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.staleIfError = maxStale
+	}
+}
+
+// WithPprofLabels enables attaching of pprof labels around each request attempt, so CPU and goroutine
+// profiles can be sliced by upstream host, method and path (see PprofLabelHost, PprofLabelMethod
+// and PprofLabelPath). Additional labels can be set with WithRequestLabel.
+func WithPprofLabels(enabled bool) Option {
+	return func(settings *clientSettings) {
+		settings.pprofLabels = enabled
+	}
+}
+
+// WithRequestLabel adds pprof label, attached around request attempts along with default ones.
+// Labels are only attached when enabled with WithPprofLabels.
+func WithRequestLabel(key, value string) Option {
+	return func(settings *clientSettings) {
+		labels := make([]string, 0, len(settings.requestLabels)+2)
+		labels = append(labels, settings.requestLabels...)
+		settings.requestLabels = append(labels, key, value)
+	}
+}
+
+// WithTransferStats sets TransferStatsFunc compliant function, which receives progress of request and
+// response bodies transfer: bytes transferred, throughput and ETA. Reports are sent as data flows,
+// at most twice a second per body, and final report with Done set is sent once body is exhausted
+// or closed. Reported download size is size of body on the wire, i.e. before decompression.
+func WithTransferStats(fn TransferStatsFunc) Option {
+	return func(settings *clientSettings) {
+		settings.transferStatsFn = fn
+	}
+}
+
+// WithUserAgentRotation sets User-Agent header of each request to one of provided values picked with
+// rotation strategy. It takes precedence over default User-Agent set with WithDefaultHeader, but
+// User-Agent set for request explicitly is kept.
+func WithUserAgentRotation(userAgents []string, strategy RotationStrategy) Option {
+	return func(settings *clientSettings) {
+		settings.userAgentFn = newUserAgentRotation(userAgents, strategy)
+	}
+}
+
+// WithUserAgentProvider sets function, which returns User-Agent header value for each request.
+// Empty value leaves request User-Agent untouched. See WithUserAgentRotation for precedence rules.
+func WithUserAgentProvider(providerFn UserAgentProviderFunc) Option {
+	return func(settings *clientSettings) {
+		settings.userAgentFn = providerFn
+	}
+}
+
+// WithRefererTracking makes client remember URL of last successfully fetched page of each host and send it
+// in Referer header of subsequent requests to the same host, mimicking browser navigation. Referer set for
+// request explicitly is kept. Passing this option to client constructor makes all requests share navigation history.
+func WithRefererTracking() Option {
+	return func(settings *clientSettings) {
+		if settings.refererTracker == nil {
+			settings.refererTracker = newRefererTracker()
+		}
+	}
+}
+
+// WithEphemeralCookies makes request use throwaway cookie jar instead of client one. Cookies received
+// during request execution (including redirects) are discarded afterwards and cookies of client jar
+// aren't sent, so login probing or multi-account workflows don't pollute shared jar.
+// Default cookies set with WithDefaultCookies are still sent.
+func WithEphemeralCookies() Option {
+	return func(settings *clientSettings) {
+		settings.ephemeralCookies = true
+	}
+}
+
+// WithSSRFProtection makes client refuse connections to loopback, private, link-local (including
+// cloud metadata services), multicast and reserved addresses, which is essential for services fetching
+// user-supplied URLs. Check is made at dialer after DNS resolution, so redirects and DNS rebinding
+// are caught as well; blocked requests fail with ErrSSRFBlocked. When request is sent through proxy,
+// both proxy address and target host are checked, the latter before round trip, as proxy resolves it.
+// Transports other than *http.Transport can't be protected at dialer, so request host is checked
+// before every round trip instead.
+func WithSSRFProtection() Option {
+	return func(settings *clientSettings) {
+		settings.ssrfProtection = true
+	}
+}
+
+// WithStrictHeaderValidation makes Client.Do validate request headers after defaults and pre-request hook
+// were applied. Requests with invalid header names, values containing CR, LF or other control characters
+// and hop-by-hop headers (Connection, Keep-Alive, Proxy-Connection, TE, Trailer, Transfer-Encoding
+// and Upgrade), which are managed by transport, fail with ErrInvalidHeader instead of being sent malformed.
+// See RequestBuilder.SetStrictHeaderValidation for validation at build time.
+func WithStrictHeaderValidation() Option {
+	return func(settings *clientSettings) {
+		settings.strictHeaders = true
+	}
+}
+
+// WithAllowedHosts restricts hosts, which client is permitted to connect to, including every redirect hop.
+// Patterns have "[scheme://]host[:port]" form, where host may be "*" or start with "*." to match any
+// subdomain (but not domain itself), e.g. "api.example.com", "https://*.example.com" or "localhost:8080".
+// Requests to other hosts fail with ErrHostNotAllowed. Repeated calls extend allowlist.
+func WithAllowedHosts(patterns ...string) Option {
+	return func(settings *clientSettings) {
+		settings.allowedHosts = parseHostPatterns(settings.allowedHosts, patterns)
+	}
+}
+
+// WithBlockedHosts forbids connections to hosts matching provided patterns, including every redirect hop.
+// Patterns have the same form as in WithAllowedHosts and take precedence over allowed ones.
+// Requests to blocked hosts fail with ErrHostNotAllowed. Repeated calls extend denylist.
+func WithBlockedHosts(patterns ...string) Option {
+	return func(settings *clientSettings) {
+		settings.blockedHosts = parseHostPatterns(settings.blockedHosts, patterns)
+	}
+}
+
+// WithPreserveClient makes NewWithClient leave passed http.Client instance untouched.
+// Options, which alter http.Client (like WithTransport or WithCookieJar), are applied
+// to its shallow copy instead.
+func WithPreserveClient() Option {
+	return func(settings *clientSettings) {
+		settings.preserveClient = true
+	}
+}
+
 // WithCookieJar sets http.CookieJar used by underlying http.Client.
 func WithCookieJar(cookieJar http.CookieJar) Option {
 	return func(settings *clientSettings) {
@@ -111,7 +532,33 @@ func WithPostRequestHook(hookFn PostRequestHookFn) Option {
 	}
 }
 
+// WithPreAttemptHook sets PreAttemptHookFn compliant function. Unlike PreRequestHookFn, which is called
+// once per Client.Do call, it's called before every attempt with request context, attempt number and
+// elapsed time, so per-attempt logging, deadline checks and header refresh can be implemented.
+func WithPreAttemptHook(hookFn PreAttemptHookFn) Option {
+	return func(settings *clientSettings) {
+		settings.preAttemptHookFn = hookFn
+	}
+}
+
+// WithPostAttemptHook sets PostAttemptHookFn compliant function, which is called after every attempt
+// with its response or error, attempt number and elapsed time.
+func WithPostAttemptHook(hookFn PostAttemptHookFn) Option {
+	return func(settings *clientSettings) {
+		settings.postAttemptHookFn = hookFn
+	}
+}
+
+// WithStatsHandler sets StatsHandler, which receives RequestStart, ResponseReceived, RetryScheduled
+// and RequestEnd events of every request, so metrics and tracing can be attached at single point.
+func WithStatsHandler(handler StatsHandler) Option {
+	return func(settings *clientSettings) {
+		settings.statsHandler = handler
+	}
+}
+
 // WithRateLimiter sets Limiter instance. Limiter is in charged for limiting rate of requests being executed.
+// Waiting for limiter is abandoned, when request context is done, see also WithContextRateLimiter.
 func WithRateLimiter(limiter Limiter) Option {
 	return func(settings *clientSettings) {
 		if limiter != nil {
@@ -121,19 +568,181 @@ func WithRateLimiter(limiter Limiter) Option {
 }
 
 // WithAutoDecompression specifies whether response body should be unarchived automatically.
-// Currently only GZIP is supported.
+// Response body is decoded according to 'Content-Encoding' header with registered decompressors,
+// see RegisterDecompressor and WithDecompressor.
 func WithAutoDecompression(enabled bool) Option {
 	return func(settings *clientSettings) {
 		settings.decompressionEnabled = enabled
 	}
 }
 
+// WithGzipSniffing specifies whether automatic decompression decodes responses without 'Content-Encoding'
+// header as gzip, when 'Content-Type' declares gzip payload or body starts with gzip magic number.
+// It recovers responses of proxies, which strip 'Content-Encoding' header, but it also decodes
+// downloaded .gz files, so it must not be enabled for clients fetching archives.
+func WithGzipSniffing(enabled bool) Option {
+	return func(settings *clientSettings) {
+		settings.gzipSniffing = enabled
+	}
+}
+
+// WithDefaultHeader sets header, which is added to every request executed by client,
+// unless request already has header with the same key.
+func WithDefaultHeader(key, value string) Option {
+	return func(settings *clientSettings) {
+		headers := settings.defaultHeaders.Clone()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+
+		headers.Set(key, value)
+		settings.defaultHeaders = headers
+	}
+}
+
+// WithAcceptLanguage sets default 'Accept-Language' header for every request executed by client.
+// See RequestBuilder.SetAcceptLanguage for tags format.
+func WithAcceptLanguage(tags ...string) Option {
+	return WithDefaultHeader("Accept-Language", formatAcceptLanguage(tags))
+}
+
+// WithDefaultQueryParam sets query parameter, which is added to every request executed by client,
+// unless request already has query parameter with the same key. This can be used for API keys,
+// tenant identifiers or API version pins.
+func WithDefaultQueryParam(key, value string) Option {
+	return func(settings *clientSettings) {
+		params := make(url.Values, len(settings.defaultQueryParams)+1)
+		for k, values := range settings.defaultQueryParams {
+			params[k] = values
+		}
+
+		params.Set(key, value)
+		settings.defaultQueryParams = params
+	}
+}
+
+// WithDefaultCookies sets cookies, which are attached to every request executed by client,
+// unless request already has cookie with the same name. Unlike WithCookieJar, cookies are static
+// and sent regardless of request URL, no cookie jar is required.
+func WithDefaultCookies(cookies []*http.Cookie) Option {
+	return func(settings *clientSettings) {
+		defaultCookies := make([]*http.Cookie, 0, len(settings.defaultCookies)+len(cookies))
+		defaultCookies = append(defaultCookies, settings.defaultCookies...)
+		settings.defaultCookies = append(defaultCookies, cookies...)
+	}
+}
+
+// WithHostOverride sets 'Host' header value sent with every request instead of URL host,
+// unless request has its own Host set. This can be used for virtual host testing, when
+// connection is made to IP address. TLS server name (SNI) is configured separately with tls.Config.
+func WithHostOverride(host string) Option {
+	return func(settings *clientSettings) {
+		settings.hostOverride = host
+	}
+}
+
+// WithExpectContinue makes client send 'Expect: 100-continue' header with requests having body
+// and wait for server approval up to provided timeout before sending body, so large uploads aren't
+// sent in vain when server rejects them. When used as client-scoped option, ExpectContinueTimeout
+// of client *http.Transport is set as well; request-scoped option only adds header.
+func WithExpectContinue(timeout time.Duration) Option {
+	return func(settings *clientSettings) {
+		settings.expectContinueTimeout = timeout
+	}
+}
+
+// WithContentDigest makes client calculate digest of request body with provided algorithm and set
+// it to 'Content-Digest' header (or 'Content-MD5' for DigestMD5). Bodies, which can't be rewound,
+// are buffered in memory, so the same body and digest are sent on retries.
+func WithContentDigest(algo DigestAlgorithm) Option {
+	return func(settings *clientSettings) {
+		settings.digestAlgorithm = algo
+	}
+}
+
+// WithRoutePolicy sets options, which are applied only to requests matching route pattern.
+// Route pattern consists of optional method and path pattern separated by space, e.g. "GET /v1/reports/*".
+// Path pattern ending with "/*" matches any path with such prefix, otherwise path.Match syntax is used.
+// Route options are applied over client-scoped options, request-scoped options take precedence over them.
+func WithRoutePolicy(route string, opts ...Option) Option {
+	return func(settings *clientSettings) {
+		policies := make([]routePolicy, len(settings.routePolicies), len(settings.routePolicies)+1)
+		copy(policies, settings.routePolicies)
+		settings.routePolicies = append(policies, newRoutePolicy(route, opts))
+	}
+}
+
+// WithDecompressor sets decompressor for provided content encoding, which is used by client
+// instead of globally registered one. Takes effect only with WithAutoDecompression enabled.
+func WithDecompressor(encoding string, decompressor Decompressor) Option {
+	return func(settings *clientSettings) {
+		decompressors := make(map[string]Decompressor, len(settings.decompressors)+1)
+		for key, value := range settings.decompressors {
+			decompressors[key] = value
+		}
+
+		decompressors[strings.ToLower(encoding)] = decompressor
+		settings.decompressors = decompressors
+	}
+}
+
+// WithAcceptEncoding sets encodings advertised in 'Accept-Encoding' header and enables automatic
+// decompression of responses. Only encodings with registered decompressors (see RegisterDecompressor
+// and WithDecompressor) are advertised, so every negotiated encoding can be decoded. When used as
+// client-scoped option, implicit gzip handling of client *http.Transport is disabled.
+func WithAcceptEncoding(encodings ...string) Option {
+	return func(settings *clientSettings) {
+		acceptEncodings := make([]string, 0, len(encodings))
+		for _, encoding := range encodings {
+			acceptEncodings = append(acceptEncodings, strings.ToLower(strings.TrimSpace(encoding)))
+		}
+
+		settings.acceptEncodings = acceptEncodings
+		settings.decompressionEnabled = true
+	}
+}
+
+// WithCharsetDecoding specifies whether buffered response body should be converted to UTF-8
+// according to charset declared in 'Content-Type' header. Decoding is enabled by default and
+// takes place only for charsets with registered decoders, see RegisterCharsetDecoder.
+func WithCharsetDecoding(enabled bool) Option {
+	return func(settings *clientSettings) {
+		settings.charsetDecodingDisabled = !enabled
+	}
+}
+
+// WithContextRateLimiter sets limiter, which waiting is interrupted, when request context is done.
+// Unlike limiters set with WithRateLimiter, which don't implement ContextLimiter, canceled request
+// doesn't consume token.
+func WithContextRateLimiter(limiter ContextLimiter) Option {
+	return func(settings *clientSettings) {
+		if limiter != nil {
+			settings.rateLimiter = contextLimiter{limiter}
+		}
+	}
+}
+
+// WithResponseTee sets writer, to which response body bytes are copied as they are read off the wire,
+// i.e. before decompression and charset decoding. Bodies of responses discarded on retries are copied
+// as well. Writer must be safe for concurrent use if client executes requests concurrently.
+func WithResponseTee(w io.Writer) Option {
+	return func(settings *clientSettings) {
+		settings.responseTee = w
+	}
+}
+
 // Limiter interface is used to abstract concrete types which purpose is to set and handle rate-limiting for
-// request execution.
+// request execution. If limiter also implements ContextLimiter, its Wait method is used instead of Take.
 type Limiter interface {
 	Take() time.Time
 }
 
+// ContextLimiter is limiter, which waiting can be interrupted by context cancellation,
+// e.g. *rate.Limiter from golang.org/x/time/rate. See WithContextRateLimiter.
+type ContextLimiter interface {
+	Wait(ctx context.Context) error
+}
+
 // NewUnlimitedLimiter creates dummy struct which implements Limiter interface.
 // Used for testing purposes.
 func NewUnlimitedLimiter() Limiter {
@@ -0,0 +1,40 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+)
+
+// Labels attached to profiles of request attempts, see WithPprofLabels.
+const (
+	PprofLabelHost   = "httpr.host"
+	PprofLabelMethod = "httpr.method"
+	PprofLabelPath   = "httpr.path"
+)
+
+// pprofLabels returns pprof labels of request: host, method, path and labels set with WithRequestLabel.
+func pprofLabels(req *http.Request, extra []string) pprof.LabelSet {
+	labels := make([]string, 0, 6+len(extra))
+	labels = append(labels,
+		PprofLabelHost, req.URL.Host,
+		PprofLabelMethod, req.Method,
+		PprofLabelPath, req.URL.Path,
+	)
+	labels = append(labels, extra...)
+	return pprof.Labels(labels...)
+}
+
+// withPprofLabels runs fn with pprof labels of request attached to current goroutine, if labeling
+// is enabled. Request passed to fn carries labeled context, so goroutines started by transport
+// with pprof.Do or pprof.SetGoroutineLabels inherit them too.
+func withPprofLabels(req *http.Request, settings clientSettings, fn func(req *http.Request)) {
+	if !settings.pprofLabels {
+		fn(req)
+		return
+	}
+
+	pprof.Do(req.Context(), pprofLabels(req, settings.requestLabels), func(ctx context.Context) {
+		fn(req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,74 @@
+package httpr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func TestPprofLabels(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     []Option
+		expected map[string]string
+	}{
+		{
+			name:     "disabled",
+			opts:     []Option{WithRequestLabel("service", "billing")},
+			expected: map[string]string{},
+		},
+		{
+			name: "enabled",
+			opts: []Option{WithPprofLabels(true)},
+			expected: map[string]string{
+				PprofLabelHost:   "example.com",
+				PprofLabelMethod: http.MethodGet,
+				PprofLabelPath:   "/users",
+			},
+		},
+		{
+			name: "with request labels",
+			opts: []Option{WithPprofLabels(true), WithRequestLabel("service", "billing")},
+			expected: map[string]string{
+				PprofLabelHost:   "example.com",
+				PprofLabelMethod: http.MethodGet,
+				PprofLabelPath:   "/users",
+				"service":        "billing",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			labels := make(map[string]string)
+			transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				pprof.ForLabels(req.Context(), func(key, value string) bool {
+					labels[key] = value
+					return true
+				})
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("")),
+					Request:    req,
+				}, nil
+			})
+
+			client := New(append([]Option{WithTransport(transport)}, tc.opts...)...)
+			if _, err := client.Get(context.Background(), "http://example.com/users", nil); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(labels) != len(tc.expected) {
+				t.Fatalf("expected labels %v, got %v instead", tc.expected, labels)
+			}
+			for key, value := range tc.expected {
+				if labels[key] != value {
+					t.Errorf("expected label %q to be %q, got %q instead", key, value, labels[key])
+				}
+			}
+		})
+	}
+}
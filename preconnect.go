@@ -0,0 +1,103 @@
+package httpr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Preconnect warms up connection pool of client: for every distinct origin (scheme and host)
+// of provided URLs it resolves DNS, dials connection and completes TLS handshake concurrently,
+// so the first real requests after startup don't pay cold-start latency.
+//
+// Transport of net/http doesn't allow adding connections to its pool directly, therefore
+// connection is established with HEAD request to provided URL, which response is discarded.
+// Retries, hooks, redirects and cookies aren't involved, but host filters (see WithAllowedHosts)
+// are respected. Connection is kept idle in pool, unless server closes it or transport
+// doesn't keep idle connections.
+//
+// Error is returned, if any origin couldn't be connected to; other connections are kept anyway.
+func (c *Client) Preconnect(ctx context.Context, urls ...string) error {
+	var (
+		origins = make(map[string]struct{}, len(urls))
+		targets []*url.URL
+	)
+	for _, rawURL := range urls {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("failed to parse URL %q: %w", rawURL, err)
+		}
+		scheme := strings.ToLower(u.Scheme)
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("can't preconnect to URL %q with scheme %q", u.Redacted(), u.Scheme)
+		}
+
+		origin := scheme + "://" + strings.ToLower(u.Host)
+		if _, ok := origins[origin]; ok {
+			continue
+		}
+		origins[origin] = struct{}{}
+		targets = append(targets, u)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(targets))
+	)
+	for i, u := range targets {
+		wg.Add(1)
+		go func(i int, u *url.URL) {
+			defer wg.Done()
+			errs[i] = c.preconnect(ctx, u)
+		}(i, u)
+	}
+	wg.Wait()
+
+	var (
+		firstErr error
+		failed   int
+	)
+	for _, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("failed to preconnect to %d of %d origin(s): %w", failed, len(targets), firstErr)
+	}
+
+	return nil
+}
+
+func (c *Client) preconnect(ctx context.Context, u *url.URL) error {
+	if err := checkHost(u, c.settings); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.settings.hostOverride != "" {
+		req.Host = c.settings.hostOverride
+	}
+
+	transport := c.client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s://%s: %w", u.Scheme, u.Host, err)
+	}
+	discardBody(&Response{rawResp: resp}, false)
+
+	return nil
+}
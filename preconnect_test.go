@@ -0,0 +1,60 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientPreconnect(t *testing.T) {
+	var (
+		heads      int32
+		handshakes int32
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			atomic.AddInt32(&heads, 1)
+		}
+	}))
+	defer server.Close()
+
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	client := New(WithTransport(transport))
+
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		TLSHandshakeStart: func() { atomic.AddInt32(&handshakes, 1) },
+	})
+	if err := client.Preconnect(ctx, server.URL+"/a", server.URL+"/b"); err != nil {
+		t.Fatalf("unexpected preconnect error: %v", err)
+	}
+	if atomic.LoadInt32(&heads) != 1 || atomic.LoadInt32(&handshakes) != 1 {
+		t.Fatalf("expected single connection to origin, got %d request(s) and %d handshake(s) instead",
+			atomic.LoadInt32(&heads), atomic.LoadInt32(&handshakes))
+	}
+
+	var reused bool
+	ctx = httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused },
+	})
+	if _, err := client.Get(ctx, server.URL, nil); err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+	if !reused {
+		t.Fatal("expected request to reuse preconnected connection")
+	}
+}
+
+func TestClientPreconnectErrors(t *testing.T) {
+	client := New(WithAllowedHosts("api.test.com"))
+
+	if err := client.Preconnect(context.Background(), "ftp://api.test.com"); err == nil {
+		t.Fatal("expected error for unsupported scheme")
+	}
+	if err := client.Preconnect(context.Background(), "https://evil.test.com"); !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("expected ErrHostNotAllowed, got %v instead", err)
+	}
+}
@@ -0,0 +1,58 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// _prefetchPriority is value of 'Priority' header (RFC 9218) of prefetch requests:
+// the lowest urgency, allowing incremental delivery.
+const _prefetchPriority = "u=7, i"
+
+// Prefetch fetches resources at provided URLs into client cache (see WithCache) in background,
+// so requests, which application expects to make soon (next page, assets), are served from cache.
+// It returns immediately; resources are fetched one at a time with the lowest priority signalled
+// to server by 'Priority' header, and ones still fresh in cache aren't requested again.
+//
+// Returned channel receives errors of failed fetches and is closed when prefetching is finished.
+// It's buffered to hold all errors, so it can be ignored. Canceling ctx stops prefetching.
+func (c *Client) Prefetch(ctx context.Context, urls ...string) <-chan error {
+	errs := make(chan error, len(urls)+1)
+	if c.settings.cache == nil {
+		errs <- errors.New("prefetch requires client cache, see WithCache")
+		close(errs)
+		return errs
+	}
+
+	go func() {
+		defer close(errs)
+
+		for _, rawURL := range urls {
+			if ctx.Err() != nil {
+				errs <- ctx.Err()
+				return
+			}
+			if err := c.prefetch(ctx, rawURL); err != nil {
+				errs <- fmt.Errorf("failed to prefetch %q: %w", rawURL, err)
+			}
+		}
+	}()
+
+	return errs
+}
+
+func (c *Client) prefetch(ctx context.Context, rawURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Priority", _prefetchPriority)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Close()
+}
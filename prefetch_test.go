@@ -0,0 +1,65 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientPrefetch(t *testing.T) {
+	var (
+		hits     int32
+		priority atomic.Value
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		priority.Store(r.Header.Get("Priority"))
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	client := New(WithCache(NewMemoryCache(0)), WithFailOnStatus(func(code int) bool { return code >= 400 }))
+	var errCount int
+	for err := range client.Prefetch(context.Background(), server.URL+"/page/2", server.URL+"/missing", server.URL+"/page/3") {
+		if err == nil {
+			t.Fatal("expected only errors to be sent")
+		}
+		errCount++
+	}
+	if errCount != 1 {
+		t.Fatalf("expected 1 prefetch error, got %d instead", errCount)
+	}
+	if got := priority.Load(); got != _prefetchPriority {
+		t.Fatalf("expected priority %q, got %q instead", _prefetchPriority, got)
+	}
+
+	for _, path := range []string{"/page/2", "/page/3"} {
+		resp, err := client.Get(context.Background(), server.URL+path, nil)
+		if err != nil {
+			t.Fatalf("unexpected request error: %v", err)
+		}
+		if !resp.FromCache() || resp.String() != path {
+			t.Fatalf("expected prefetched response %q from cache, got %q", path, resp.String())
+		}
+	}
+	if atomic.LoadInt32(&hits) != 3 {
+		t.Fatalf("expected 3 requests to origin, got %d instead", atomic.LoadInt32(&hits))
+	}
+}
+
+func TestClientPrefetchWithoutCache(t *testing.T) {
+	errs := New().Prefetch(context.Background(), "http://localhost")
+	if err := <-errs; err == nil {
+		t.Fatal("expected error for client without cache")
+	}
+	if _, ok := <-errs; ok {
+		t.Fatal("expected channel to be closed")
+	}
+}
@@ -0,0 +1,129 @@
+package httpr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+)
+
+// MediaTypeProblemJSON is media type of RFC 7807 problem details documents.
+const MediaTypeProblemJSON = "application/problem+json"
+
+// ProblemDetails is RFC 7807 problem details document, which describes API error.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions contains problem type specific members, which aren't defined by RFC 7807.
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	type problemDetails ProblemDetails
+	if err := json.Unmarshal(data, (*problemDetails)(p)); err != nil {
+		return err
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+	if len(members) > 0 {
+		p.Extensions = members
+	} else {
+		p.Extensions = nil
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	type problemDetails ProblemDetails
+	data, err := json.Marshal(problemDetails(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	if err = json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
+
+// ProblemError is an error, which is described by RFC 7807 problem details document
+// returned by server. It is returned by Client.Do in fail-on-status mode, see WithFailOnStatus.
+type ProblemError struct {
+	Problem  *ProblemDetails
+	Response *Response
+
+	respErr *ResponseError
+}
+
+// Error implements error interface.
+func (e *ProblemError) Error() string {
+	status := e.Problem.Status
+	if status == 0 {
+		status = e.Response.StatusCode()
+	}
+
+	msg := fmt.Sprintf("problem (status %d)", status)
+	if e.Problem.Title != "" {
+		msg += ": " + e.Problem.Title
+	}
+	if e.Problem.Detail != "" {
+		msg += ": " + e.Problem.Detail
+	}
+	return msg
+}
+
+// Problem parses response body as RFC 7807 problem details document. Error is returned,
+// if response media type isn't "application/problem+json".
+func (r *Response) Problem() (*ProblemDetails, error) {
+	if !r.isProblem() {
+		return nil, fmt.Errorf("response isn't a problem details document: unexpected Content-Type %q",
+			r.Header().Get("Content-Type"))
+	}
+	body, err := r.bodyBytes()
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, errors.New("response body is nil")
+	}
+
+	problem := new(ProblemDetails)
+	if err = json.Unmarshal(body, problem); err != nil {
+		return nil, fmt.Errorf("failed to decode problem details: %w", withSentinel(ErrDecodeBody, err))
+	}
+
+	return problem, nil
+}
+
+// Unwrap returns *ResponseError describing response, so ProblemError can be matched
+// with errors.As as *ResponseError as well.
+func (e *ProblemError) Unwrap() error {
+	if e.respErr == nil {
+		e.respErr = newResponseError(e.Response, 1)
+	}
+
+	return e.respErr
+}
+
+// isProblem reports whether response media type is "application/problem+json".
+func (r *Response) isProblem() bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header().Get("Content-Type"))
+	return err == nil && mediaType == MediaTypeProblemJSON
+}
@@ -0,0 +1,58 @@
+package httpr
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestResponseProblem(t *testing.T) {
+	resp := &Response{
+		rawResp: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+		},
+		body: []byte(`{
+			"type": "https://example.com/probs/out-of-credit",
+			"title": "You do not have enough credit.",
+			"status": 403,
+			"detail": "Your current balance is 30, but that costs 50.",
+			"instance": "/account/12345/msgs/abc",
+			"balance": 30
+		}`),
+	}
+
+	problem, err := resp.Problem()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if problem.Status != http.StatusForbidden || problem.Title != "You do not have enough credit." {
+		t.Errorf("unexpected problem details %+v", problem)
+	}
+	if balance, ok := problem.Extensions["balance"].(float64); !ok || balance != 30 {
+		t.Errorf("expected balance extension 30, got %v", problem.Extensions["balance"])
+	}
+
+	data, err := json.Marshal(problem)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var roundTrip ProblemDetails
+	if err = json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if roundTrip.Instance != problem.Instance || roundTrip.Extensions["balance"] != problem.Extensions["balance"] {
+		t.Errorf("expected problem details %+v after marshalling, got %+v instead", problem, roundTrip)
+	}
+
+	problemErr := &ProblemError{Problem: problem, Response: resp}
+	expected := "problem (status 403): You do not have enough credit.: Your current balance is 30, but that costs 50."
+	if problemErr.Error() != expected {
+		t.Errorf("expected error message %q, got %q instead", expected, problemErr.Error())
+	}
+
+	resp.rawResp.Header.Set("Content-Type", "application/json")
+	if _, err = resp.Problem(); err == nil {
+		t.Error("expected error for non-problem response, got nil")
+	}
+}
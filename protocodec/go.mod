@@ -0,0 +1,7 @@
+module github.com/hickar/httpr/protocodec
+
+go 1.18
+
+require github.com/hickar/httpr v0.1.0
+
+require google.golang.org/protobuf v1.33.0
@@ -0,0 +1,50 @@
+// Package protocodec adds Protocol Buffers body support to httpr. It's separate module,
+// so core httpr module stays free of dependencies. Importing package registers codec for
+// httpr.MediaTypeProtobuf and "application/protobuf" globally, which makes
+// RequestBuilder.SetProtoBody, Response.Proto and Response.Decode work with proto.Message values:
+//
+//	import _ "github.com/hickar/httpr/protocodec"
+//
+//	req, err := httpr.NewRequest().Post(url, nil).SetProtoBody(&pb.CreateUserRequest{Name: "gopher"}).Build()
+//	// ...
+//	var user pb.User
+//	err = resp.Proto(&user)
+package protocodec
+
+import (
+	"fmt"
+
+	"github.com/hickar/httpr"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec marshals and unmarshals proto.Message values in binary wire format.
+var Codec = httpr.Codec{
+	Marshal:   Marshal,
+	Unmarshal: Unmarshal,
+}
+
+func init() {
+	httpr.RegisterCodec(httpr.MediaTypeProtobuf, Codec)
+	httpr.RegisterCodec("application/protobuf", Codec)
+}
+
+// Marshal encodes provided proto.Message. Values of other types are refused with error.
+func Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protocodec: %T doesn't implement proto.Message", v)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Unmarshal decodes data into provided proto.Message. Values of other types are refused with error.
+func Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protocodec: %T doesn't implement proto.Message", v)
+	}
+
+	return proto.Unmarshal(data, msg)
+}
@@ -0,0 +1,68 @@
+package protocodec
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hickar/httpr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtoBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if contentType := req.Header.Get("Content-Type"); contentType != httpr.MediaTypeProtobuf {
+			t.Errorf("expected Content-Type %q, got %q instead", httpr.MediaTypeProtobuf, contentType)
+		}
+
+		body, _ := io.ReadAll(req.Body)
+		var received wrapperspb.StringValue
+		if err := proto.Unmarshal(body, &received); err != nil {
+			t.Errorf("failed to unmarshal request body: %v", err)
+		}
+
+		reply, _ := proto.Marshal(wrapperspb.String("hello, " + received.GetValue()))
+		w.Header().Set("Content-Type", httpr.MediaTypeProtobuf)
+		_, _ = w.Write(reply)
+	}))
+	defer ts.Close()
+
+	req, err := httpr.NewRequest().
+		Post(ts.URL, nil).
+		SetProtoBody(wrapperspb.String("gopher")).
+		SetContext(context.Background()).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	resp, err := httpr.New().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+
+	var reply wrapperspb.StringValue
+	if err = resp.Proto(&reply); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if reply.GetValue() != "hello, gopher" {
+		t.Fatalf("expected reply %q, got %q instead", "hello, gopher", reply.GetValue())
+	}
+
+	var decoded wrapperspb.StringValue
+	if err = resp.Decode(&decoded); err != nil || decoded.GetValue() != "hello, gopher" {
+		t.Fatalf("expected response to be decoded by Content-Type, got %q, %v", decoded.GetValue(), err)
+	}
+}
+
+func TestNotProtoMessage(t *testing.T) {
+	if _, err := httpr.NewRequest().Post("http://localhost", nil).SetProtoBody(struct{}{}).Build(); err == nil {
+		t.Fatal("expected error for value not implementing proto.Message")
+	}
+	if err := Unmarshal(nil, new(string)); err == nil {
+		t.Fatal("expected error for value not implementing proto.Message")
+	}
+}
@@ -0,0 +1,239 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	_defaultProxyFailureThreshold = 3
+	_defaultProxyQuarantine       = time.Minute
+	// _proxyLatencyWeight is weight of the latest sample in moving average of proxy latency.
+	_proxyLatencyWeight = 0.2
+)
+
+// ProxyPoolConfig describes behaviour of ProxyPool.
+type ProxyPoolConfig struct {
+	// FailureThreshold is number of consecutive failures, after which proxy is quarantined. Default is 3.
+	FailureThreshold int
+	// Quarantine is duration, for which failing proxy is excluded from rotation. Default is one minute.
+	Quarantine time.Duration
+	// Transport is base transport, which is cloned for each proxy. Default is DefaultTransport().
+	Transport *http.Transport
+}
+
+// ProxyPool is http.RoundTripper, which rotates requests across proxies in round-robin manner.
+// It keeps success and latency statistics of each proxy and temporarily quarantines failing ones.
+// Proxy is considered failed, if request through it returned transport error or
+// 407 Proxy Authentication Required status. Pool can be used with WithTransport.
+type ProxyPool struct {
+	proxies          []*poolProxy
+	next             uint64
+	failureThreshold int
+	quarantine       time.Duration
+}
+
+type poolProxy struct {
+	url       *url.URL
+	transport *http.Transport
+
+	mu                  sync.Mutex
+	successes           int
+	failures            int
+	consecutiveFailures int
+	latency             time.Duration
+	quarantinedUntil    time.Time
+}
+
+// ProxyHealth is snapshot of proxy statistics.
+type ProxyHealth struct {
+	// URL is proxy URL with password redacted.
+	URL       string
+	Healthy   bool
+	Successes int
+	Failures  int
+	// SuccessRate is ratio of successful requests, 1 if proxy wasn't used yet.
+	SuccessRate float64
+	// Latency is moving average of request latency through proxy.
+	Latency          time.Duration
+	QuarantinedUntil time.Time
+}
+
+// NewProxyPool creates pool of proxies with provided URLs.
+func NewProxyPool(proxyURLs []string, cfg ProxyPoolConfig) (*ProxyPool, error) {
+	if len(proxyURLs) == 0 {
+		return nil, errors.New("proxy pool must contain at least one proxy")
+	}
+
+	base := cfg.Transport
+	if base == nil {
+		base = DefaultTransport()
+	}
+
+	pool := &ProxyPool{
+		failureThreshold: cfg.FailureThreshold,
+		quarantine:       cfg.Quarantine,
+	}
+	if pool.failureThreshold <= 0 {
+		pool.failureThreshold = _defaultProxyFailureThreshold
+	}
+	if pool.quarantine <= 0 {
+		pool.quarantine = _defaultProxyQuarantine
+	}
+
+	for _, rawURL := range proxyURLs {
+		proxyURL, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+
+		tr := base.Clone()
+		tr.Proxy = http.ProxyURL(proxyURL)
+		pool.proxies = append(pool.proxies, &poolProxy{url: proxyURL, transport: tr})
+	}
+
+	return pool, nil
+}
+
+// RoundTrip implements http.RoundTripper interface.
+func (p *ProxyPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxy := p.pick()
+
+	start := time.Now()
+	resp, err := proxy.transport.RoundTrip(req)
+	p.report(proxy, err == nil && resp.StatusCode != http.StatusProxyAuthRequired, time.Since(start))
+
+	return resp, err
+}
+
+// pick returns next proxy, which isn't quarantined. If all proxies are quarantined,
+// the one, which quarantine ends first, is returned.
+func (p *ProxyPool) pick() *poolProxy {
+	var (
+		now      = time.Now()
+		start    = atomic.AddUint64(&p.next, 1) - 1
+		earliest *poolProxy
+		until    time.Time
+	)
+	for i := 0; i < len(p.proxies); i++ {
+		proxy := p.proxies[(start+uint64(i))%uint64(len(p.proxies))]
+
+		proxy.mu.Lock()
+		quarantinedUntil := proxy.quarantinedUntil
+		proxy.mu.Unlock()
+
+		if !quarantinedUntil.After(now) {
+			return proxy
+		}
+		if earliest == nil || quarantinedUntil.Before(until) {
+			earliest, until = proxy, quarantinedUntil
+		}
+	}
+
+	return earliest
+}
+
+// report updates proxy statistics with result of request made through it.
+func (p *ProxyPool) report(proxy *poolProxy, ok bool, latency time.Duration) {
+	proxy.mu.Lock()
+	defer proxy.mu.Unlock()
+
+	if !ok {
+		proxy.failures++
+		proxy.consecutiveFailures++
+		if proxy.consecutiveFailures >= p.failureThreshold {
+			proxy.quarantinedUntil = time.Now().Add(p.quarantine)
+		}
+		return
+	}
+
+	proxy.successes++
+	proxy.consecutiveFailures = 0
+	proxy.quarantinedUntil = time.Time{}
+	if proxy.latency == 0 {
+		proxy.latency = latency
+	} else {
+		proxy.latency += time.Duration(_proxyLatencyWeight * float64(latency-proxy.latency))
+	}
+}
+
+// Health returns statistics of all proxies in pool.
+func (p *ProxyPool) Health() []ProxyHealth {
+	now := time.Now()
+	health := make([]ProxyHealth, 0, len(p.proxies))
+	for _, proxy := range p.proxies {
+		proxy.mu.Lock()
+		h := ProxyHealth{
+			URL:              proxy.url.Redacted(),
+			Healthy:          !proxy.quarantinedUntil.After(now),
+			Successes:        proxy.successes,
+			Failures:         proxy.failures,
+			SuccessRate:      1,
+			Latency:          proxy.latency,
+			QuarantinedUntil: proxy.quarantinedUntil,
+		}
+		proxy.mu.Unlock()
+
+		if total := h.Successes + h.Failures; total > 0 {
+			h.SuccessRate = float64(h.Successes) / float64(total)
+		}
+		health = append(health, h)
+	}
+
+	return health
+}
+
+// CheckHealth probes each proxy concurrently by requesting probeURL through it and updates
+// proxy statistics. Quarantined proxies are probed as well, so they are brought back to rotation
+// as soon as they recover.
+func (p *ProxyPool) CheckHealth(ctx context.Context, probeURL string) {
+	var wg sync.WaitGroup
+	for _, proxy := range p.proxies {
+		wg.Add(1)
+		go func(proxy *poolProxy) {
+			defer wg.Done()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+			if err != nil {
+				return
+			}
+
+			start := time.Now()
+			resp, err := proxy.transport.RoundTrip(req)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			p.report(proxy, err == nil && resp.StatusCode < http.StatusInternalServerError &&
+				resp.StatusCode != http.StatusProxyAuthRequired, time.Since(start))
+		}(proxy)
+	}
+	wg.Wait()
+}
+
+// StartHealthChecks runs CheckHealth with provided interval in background until context is done.
+func (p *ProxyPool) StartHealthChecks(ctx context.Context, probeURL string, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				checkCtx, cancel := context.WithTimeout(ctx, interval)
+				p.CheckHealth(checkCtx, probeURL)
+				cancel()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
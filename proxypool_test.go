@@ -0,0 +1,68 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProxyPool(t *testing.T) {
+	newProxy := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(name + " " + req.URL.String()))
+		}))
+	}
+
+	alive := newProxy("alive")
+	defer alive.Close()
+	dead := newProxy("dead")
+	deadURL := dead.URL
+	dead.Close()
+
+	pool, err := NewProxyPool([]string{alive.URL, deadURL}, ProxyPoolConfig{
+		FailureThreshold: 2,
+		Quarantine:       time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	c := New(WithTransport(pool), WithRetryCount(1))
+
+	var failures int
+	for i := 0; i < 10; i++ {
+		resp, err := c.Get(context.Background(), "http://target.test.com/page", nil)
+		if err != nil {
+			failures++
+			continue
+		}
+		if expected := "alive http://target.test.com/page"; resp.String() != expected {
+			t.Errorf("expected response %q, got %q instead", expected, resp.String())
+		}
+	}
+	if failures != 2 {
+		t.Errorf("expected dead proxy to be quarantined after 2 failures, got %d failures", failures)
+	}
+
+	health := pool.Health()
+	if len(health) != 2 {
+		t.Fatalf("expected health of 2 proxies, got %d", len(health))
+	}
+	if !health[0].Healthy || health[0].Successes != 8 || health[0].SuccessRate != 1 || health[0].Latency <= 0 {
+		t.Errorf("unexpected alive proxy health %+v", health[0])
+	}
+	if health[1].Healthy || health[1].Failures != 2 || health[1].SuccessRate != 0 || health[1].QuarantinedUntil.IsZero() {
+		t.Errorf("unexpected dead proxy health %+v", health[1])
+	}
+
+	pool.CheckHealth(context.Background(), "http://target.test.com/health")
+	if health = pool.Health(); health[0].Successes != 9 || health[1].Failures != 3 {
+		t.Errorf("expected health check to update statistics, got %+v", health)
+	}
+
+	if _, err = NewProxyPool(nil, ProxyPoolConfig{}); err == nil {
+		t.Error("expected error for empty proxy pool, got nil")
+	}
+}
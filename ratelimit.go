@@ -0,0 +1,195 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// _maxThrottledRetries is maximum number of retries of throttled request made by WithAutoRateLimitRetry.
+	_maxThrottledRetries = 10
+	// _defaultThrottleDelay is delay before retrying throttled request, which has no Retry-After header.
+	_defaultThrottleDelay = time.Second
+)
+
+// _unixTimestampThreshold separates reset values, which are Unix timestamps, from ones,
+// which are number of seconds until reset.
+const _unixTimestampThreshold = 1_000_000_000
+
+// RateLimit describes rate limit quota state reported by server.
+type RateLimit struct {
+	// Limit is maximum number of requests in current window, -1 if unknown.
+	Limit int
+	// Remaining is number of requests left in current window, -1 if unknown.
+	Remaining int
+	// Reset is time, when quota is reset. It is zero if unknown.
+	Reset time.Time
+}
+
+// RateLimit returns rate limit quota state parsed from response headers. Following headers are
+// recognized: de-facto standard X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset
+// (Unix timestamp or number of seconds), IETF RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset, and combined IETF RateLimit header in both "limit=100, remaining=10, reset=30"
+// and structured `"default";r=10;t=30` forms along with RateLimit-Policy header. False is returned,
+// if response has no rate limit headers.
+func (r *Response) RateLimit() (RateLimit, bool) {
+	return r.rateLimitAt(time.Now())
+}
+
+// rateLimitAt parses rate limit headers, resolving relative reset values against provided time.
+func (r *Response) rateLimitAt(now time.Time) (RateLimit, bool) {
+	rl := RateLimit{Limit: -1, Remaining: -1}
+	header := r.Header()
+
+	var found bool
+	setInt := func(dst *int, value string) {
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			*dst = n
+			found = true
+		}
+	}
+	setReset := func(value string, allowTimestamp bool) {
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || n < 0 {
+			return
+		}
+
+		if allowTimestamp && n >= _unixTimestampThreshold {
+			rl.Reset = time.Unix(n, 0)
+		} else {
+			rl.Reset = now.Add(time.Duration(n) * time.Second)
+		}
+		found = true
+	}
+
+	if value := header.Get("RateLimit"); value != "" {
+		for _, param := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+			key, val, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok {
+				continue
+			}
+
+			switch strings.ToLower(key) {
+			case "limit":
+				setInt(&rl.Limit, val)
+			case "remaining", "r":
+				setInt(&rl.Remaining, val)
+			case "reset", "t":
+				setReset(val, false)
+			}
+		}
+	}
+	if value := header.Get("RateLimit-Policy"); value != "" && rl.Limit < 0 {
+		for _, param := range strings.Split(value, ";") {
+			if key, val, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				setInt(&rl.Limit, val)
+			}
+		}
+	}
+
+	for _, prefix := range []string{"RateLimit-", "X-RateLimit-"} {
+		if value := header.Get(prefix + "Limit"); value != "" && rl.Limit < 0 {
+			// Limit can be followed by quota policy, e.g. "100, 100;w=60".
+			limit, _, _ := strings.Cut(value, ",")
+			setInt(&rl.Limit, limit)
+		}
+		if value := header.Get(prefix + "Remaining"); value != "" && rl.Remaining < 0 {
+			setInt(&rl.Remaining, value)
+		}
+		if value := header.Get(prefix + "Reset"); value != "" && rl.Reset.IsZero() {
+			setReset(value, prefix == "X-RateLimit-")
+		}
+	}
+
+	return rl, found
+}
+
+// ThrottledFunc is function, which is called before throttled (429 Too Many Requests) request is retried
+// after wait duration. See WithAutoRateLimitRetry.
+type ThrottledFunc func(req *http.Request, resp *Response, wait time.Duration)
+
+// throttleDelay returns delay before retrying request, if response is 429 Too Many Requests and
+// automatic retry of throttled requests is enabled and its wait budget isn't exhausted.
+func throttleDelay(settings clientSettings, resp *Response, err error, throttled int, waited time.Duration) (time.Duration, bool) {
+	if settings.rateLimitMaxWait <= 0 || err != nil || resp.StatusCode() != http.StatusTooManyRequests ||
+		throttled >= _maxThrottledRetries {
+		return 0, false
+	}
+
+	wait, ok := retryAfter(resp, settings.clock().Now())
+	if !ok {
+		wait = _defaultThrottleDelay
+	}
+	if waited+wait > settings.rateLimitMaxWait {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// retryAfter returns delay requested by server with Retry-After header (in seconds or HTTP date),
+// falling back to reset time of rate limit headers. Dates are resolved against provided time.
+func retryAfter(resp *Response, now time.Time) (time.Duration, bool) {
+	if value := strings.TrimSpace(resp.Header().Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			if wait := date.Sub(now); wait > 0 {
+				return wait, true
+			}
+			return 0, true
+		}
+	}
+
+	if rl, ok := resp.rateLimitAt(now); ok && !rl.Reset.IsZero() {
+		if wait := rl.Reset.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// waitLimiter waits for limiter permission to execute request, returning context error once
+// context is done. Limiters, which don't implement ContextLimiter, can't be interrupted, so their
+// Take method is called in background and consumes token even if request is canceled.
+func waitLimiter(ctx context.Context, limiter Limiter) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l, ok := limiter.(ContextLimiter); ok {
+		return l.Wait(ctx)
+	}
+	if ctx.Done() == nil {
+		limiter.Take()
+		return nil
+	}
+
+	taken := make(chan struct{})
+	go func() {
+		limiter.Take()
+		close(taken)
+	}()
+
+	select {
+	case <-taken:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// contextLimiter adapts ContextLimiter to Limiter interface.
+type contextLimiter struct {
+	ContextLimiter
+}
+
+func (l contextLimiter) Take() time.Time {
+	_ = l.Wait(context.Background())
+	return time.Now()
+}
@@ -0,0 +1,265 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseRateLimit(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		found     bool
+		limit     int
+		remaining int
+		reset     time.Duration
+		resetAt   time.Time
+	}{
+		{
+			name:      "NoHeaders",
+			header:    http.Header{},
+			limit:     -1,
+			remaining: -1,
+		},
+		{
+			name: "XRateLimit_Timestamp",
+			header: http.Header{
+				"X-Ratelimit-Limit":     {"5000"},
+				"X-Ratelimit-Remaining": {"4999"},
+				"X-Ratelimit-Reset":     {strconv.FormatInt(resetAt.Unix(), 10)},
+			},
+			found:     true,
+			limit:     5000,
+			remaining: 4999,
+			resetAt:   resetAt,
+		},
+		{
+			name: "XRateLimit_Seconds",
+			header: http.Header{
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {"30"},
+			},
+			found:     true,
+			limit:     -1,
+			remaining: 0,
+			reset:     30 * time.Second,
+		},
+		{
+			name: "IETF_Fields",
+			header: http.Header{
+				"Ratelimit-Limit":     {"100, 100;w=60"},
+				"Ratelimit-Remaining": {"42"},
+				"Ratelimit-Reset":     {"50"},
+			},
+			found:     true,
+			limit:     100,
+			remaining: 42,
+			reset:     50 * time.Second,
+		},
+		{
+			name: "IETF_Combined",
+			header: http.Header{
+				"Ratelimit": {"limit=10, remaining=1, reset=5"},
+			},
+			found:     true,
+			limit:     10,
+			remaining: 1,
+			reset:     5 * time.Second,
+		},
+		{
+			name: "IETF_Structured",
+			header: http.Header{
+				"Ratelimit":        {`"default";r=7;t=20`},
+				"Ratelimit-Policy": {`"default";q=50;w=60`},
+			},
+			found:     true,
+			limit:     50,
+			remaining: 7,
+			reset:     20 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{rawResp: &http.Response{Header: tt.header}}
+
+			rl, found := resp.RateLimit()
+			if found != tt.found {
+				t.Fatalf("expected found %t, got %t instead", tt.found, found)
+			}
+			if rl.Limit != tt.limit || rl.Remaining != tt.remaining {
+				t.Errorf("expected limit %d and remaining %d, got %d and %d instead", tt.limit, tt.remaining, rl.Limit, rl.Remaining)
+			}
+
+			switch {
+			case !tt.resetAt.IsZero():
+				if !rl.Reset.Equal(tt.resetAt) {
+					t.Errorf("expected reset at %v, got %v instead", tt.resetAt, rl.Reset)
+				}
+			case tt.reset > 0:
+				if until := time.Until(rl.Reset); until > tt.reset || until < tt.reset-time.Second {
+					t.Errorf("expected reset in %v, got %v instead", tt.reset, until)
+				}
+			case !rl.Reset.IsZero():
+				t.Errorf("expected zero reset time, got %v", rl.Reset)
+			}
+		})
+	}
+}
+
+func TestAutoRateLimitRetry(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.Header().Set("X-RateLimit-Reset", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(_testMsg))
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := New(
+		WithRetryCount(1),
+		WithAutoRateLimitRetry(time.Second),
+		WithOnThrottled(func(_ *http.Request, resp *Response, wait time.Duration) {
+			if resp.StatusCode() != http.StatusTooManyRequests {
+				t.Errorf("expected throttled response status %d, got %d", http.StatusTooManyRequests, resp.StatusCode())
+			}
+			waits = append(waits, wait)
+		}),
+	)
+
+	resp, err := c.Post(context.Background(), ts.URL, _testMsg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode() != http.StatusOK || resp.String() != _testMsg {
+		t.Fatalf("expected successful response after throttling, got status %d", resp.StatusCode())
+	}
+	if len(waits) != 2 {
+		t.Errorf("expected 2 throttled retries, got %d", len(waits))
+	}
+
+	start := time.Now()
+	resp, err = c.Get(context.Background(), ts.URL+"/slow", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.StatusCode() != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got %d instead", http.StatusTooManyRequests, resp.StatusCode())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected no wait exceeding budget, waited %v", elapsed)
+	}
+}
+
+type blockingLimiter struct {
+	release chan struct{}
+	takes   int32
+}
+
+func (l *blockingLimiter) Take() time.Time {
+	atomic.AddInt32(&l.takes, 1)
+	<-l.release
+	return time.Now()
+}
+
+type countingContextLimiter struct {
+	waits int32
+}
+
+func (l *countingContextLimiter) Wait(ctx context.Context) error {
+	atomic.AddInt32(&l.waits, 1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRateLimiterCancellation(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	t.Run("BlockingLimiter", func(t *testing.T) {
+		limiter := &blockingLimiter{release: make(chan struct{})}
+		defer close(limiter.release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := New(WithRateLimiter(limiter)).Get(ctx, server.URL, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v instead", err)
+		}
+	})
+
+	t.Run("ContextLimiter", func(t *testing.T) {
+		limiter := &countingContextLimiter{}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := New(WithContextRateLimiter(limiter)).Get(ctx, server.URL, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v instead", err)
+		}
+		if atomic.LoadInt32(&limiter.waits) != 1 {
+			t.Fatalf("expected single wait, got %d instead", atomic.LoadInt32(&limiter.waits))
+		}
+	})
+
+	t.Run("CanceledBeforeStart", func(t *testing.T) {
+		limiter := &blockingLimiter{release: make(chan struct{})}
+		close(limiter.release)
+
+		var hookCalled bool
+		client := New(WithRateLimiter(limiter), WithPreRequestHook(func(*http.Request) error {
+			hookCalled = true
+			return nil
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := client.Get(ctx, server.URL, nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v instead", err)
+		}
+		if atomic.LoadInt32(&limiter.takes) != 0 || hookCalled {
+			t.Fatal("expected neither limiter nor hooks to be called for canceled request")
+		}
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := New(WithRetryCount(5), WithRetryDelay(time.Hour)).Get(ctx, server.URL, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v instead", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected backoff to be interrupted, took %s", elapsed)
+		}
+		if atomic.LoadInt32(&hits) != 1 {
+			t.Fatalf("expected single attempt, got %d instead", atomic.LoadInt32(&hits))
+		}
+	})
+}
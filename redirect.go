@@ -0,0 +1,138 @@
+package httpr
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// _defaultMaxRedirects is redirects limit used by redirect policies, same as http.Client default.
+const _defaultMaxRedirects = 10
+
+// RedirectPolicy is a function, which decides whether redirect must be followed.
+// It has the same semantics as http.Client.CheckRedirect and can be passed to WithCheckRedirect.
+type RedirectPolicy func(req *http.Request, via []*http.Request) error
+
+// SameHostRedirectPolicy allows only redirects to the same host (and port) as the original request,
+// which prevents leakage of credentials to foreign hosts.
+func SameHostRedirectPolicy() RedirectPolicy {
+	return ComposeRedirectPolicies(checkRedirectsLimit, SameHost())
+}
+
+// HTTPSOnlyRedirectPolicy allows only redirects to URLs with "https" scheme.
+func HTTPSOnlyRedirectPolicy() RedirectPolicy {
+	return func(req *http.Request, via []*http.Request) error {
+		if err := checkRedirectsLimit(req, via); err != nil {
+			return err
+		}
+
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("%w: redirect to non-https URL %q", ErrRedirectNotAllowed, req.URL.Redacted())
+		}
+
+		return nil
+	}
+}
+
+// DenyDowngradeRedirectPolicy forbids redirects from "https" to "http" URLs.
+func DenyDowngradeRedirectPolicy() RedirectPolicy {
+	return ComposeRedirectPolicies(checkRedirectsLimit, SchemeUpgradeOnly())
+}
+
+// ComposeRedirectPolicies returns RedirectPolicy, which allows redirect only if all provided
+// policies allow it. Policies are checked in order and the first error is returned, so building
+// blocks can be combined with each other and with custom policies:
+//
+//	policy := httpr.ComposeRedirectPolicies(
+//		httpr.MaxRedirects(5),
+//		httpr.SameHost(),
+//		httpr.LogRedirects(log.Default()),
+//	)
+//	client := httpr.New(httpr.WithCheckRedirect(policy))
+//
+// Unlike preset policies like SameHostRedirectPolicy, composed policy doesn't limit number
+// of redirects by itself, so MaxRedirects should be included.
+func ComposeRedirectPolicies(policies ...RedirectPolicy) RedirectPolicy {
+	return func(req *http.Request, via []*http.Request) error {
+		for _, policy := range policies {
+			if err := policy(req, via); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// MaxRedirects is redirect policy building block, which allows following at most n redirects.
+// If limit is exceeded, request fails with ErrTooManyRedirects.
+func MaxRedirects(n int) RedirectPolicy {
+	return func(_ *http.Request, via []*http.Request) error {
+		if len(via) > n {
+			return fmt.Errorf("%w: stopped after %d redirect(s)", ErrTooManyRedirects, n)
+		}
+
+		return nil
+	}
+}
+
+// SameHost is redirect policy building block, which allows only redirects to the same host (and port)
+// as the original request. Omitted port is treated as default port of URL scheme, so
+// "https://example.com" and "https://example.com:443" are the same host.
+func SameHost() RedirectPolicy {
+	return func(req *http.Request, via []*http.Request) error {
+		origin := via[0].URL
+		if !strings.EqualFold(req.URL.Hostname(), origin.Hostname()) || urlPort(req.URL) != urlPort(origin) {
+			return fmt.Errorf("%w: redirect from host %q to %q", ErrRedirectNotAllowed, origin.Host, req.URL.Host)
+		}
+
+		return nil
+	}
+}
+
+// SchemeUpgradeOnly is redirect policy building block, which allows scheme of redirect target
+// to differ from the previous hop only if it's upgraded to secure one: "http" to "https"
+// or "ws" to "wss".
+func SchemeUpgradeOnly() RedirectPolicy {
+	return func(req *http.Request, via []*http.Request) error {
+		from, to := strings.ToLower(via[len(via)-1].URL.Scheme), strings.ToLower(req.URL.Scheme)
+		if from == to || (from == "http" && to == "https") || (from == "ws" && to == "wss") {
+			return nil
+		}
+
+		if (from == "https" && to == "http") || (from == "wss" && to == "ws") {
+			return fmt.Errorf("%w: redirect downgrades scheme to %s", ErrRedirectNotAllowed, to)
+		}
+		return fmt.Errorf("%w: redirect changes scheme from %s to %s", ErrRedirectNotAllowed, from, to)
+	}
+}
+
+// Logger is minimal logging interface, which is implemented by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// LogRedirects is redirect policy building block, which logs every followed redirect hop
+// with provided logger. It never forbids redirects, so it should be placed after policies,
+// which do, to log only hops actually followed.
+func LogRedirects(logger Logger) RedirectPolicy {
+	return func(req *http.Request, via []*http.Request) error {
+		status := 0
+		if req.Response != nil {
+			status = req.Response.StatusCode
+		}
+		logger.Printf("httpr: redirect %d (%d): %s %s -> %s",
+			len(via), status, req.Method, via[len(via)-1].URL.Redacted(), req.URL.Redacted())
+
+		return nil
+	}
+}
+
+// checkRedirectsLimit limits number of redirects the same way as http.Client does by default.
+func checkRedirectsLimit(_ *http.Request, via []*http.Request) error {
+	if len(via) >= _defaultMaxRedirects {
+		return fmt.Errorf("%w: stopped after %d redirect(s)", ErrTooManyRedirects, _defaultMaxRedirects)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package httpr
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// refererTracker remembers last fetched URL of each host to send it as Referer
+// with subsequent requests to the same host.
+type refererTracker struct {
+	mu   sync.Mutex
+	last map[string]*url.URL
+}
+
+func newRefererTracker() *refererTracker {
+	return &refererTracker{last: make(map[string]*url.URL)}
+}
+
+// apply sets Referer header to last URL fetched from request host, unless request has one already.
+// Referer isn't sent from "https" to "http" URLs, as browsers do.
+func (t *refererTracker) apply(req *http.Request) {
+	if req.Header.Get("Referer") != "" {
+		return
+	}
+
+	t.mu.Lock()
+	referer, ok := t.last[strings.ToLower(req.URL.Hostname())]
+	t.mu.Unlock()
+
+	if !ok || (referer.Scheme == "https" && req.URL.Scheme != "https") {
+		return
+	}
+
+	req.Header.Set("Referer", referer.String())
+}
+
+// record remembers fetched URL without user info and fragment.
+func (t *refererTracker) record(u *url.URL) {
+	referer := *u
+	referer.User = nil
+	referer.Fragment = ""
+	referer.RawFragment = ""
+
+	t.mu.Lock()
+	t.last[strings.ToLower(u.Hostname())] = &referer
+	t.mu.Unlock()
+}
@@ -0,0 +1,223 @@
+package httpr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+)
+
+// RelatedPart is part of "multipart/related" request body, see RequestBuilder.SetMultipartRelated.
+type RelatedPart struct {
+	// ContentType is media type of part.
+	ContentType string
+	// Header contains additional part headers, e.g. "Content-ID".
+	Header map[string]string
+	// Body is raw part content ([]byte, string or io.Reader) or value, which is marshaled
+	// with codec registered for ContentType.
+	Body any
+	// Size is length of io.Reader body. If zero, it's detected for readers with Len method
+	// (like *bytes.Reader) and files; otherwise request is sent with chunked transfer encoding.
+	Size int64
+}
+
+// SetMultipartRelated sets "multipart/related" (RFC 2387) request body, composed of provided parts,
+// as used by upload APIs accepting JSON metadata along with binary content:
+//
+//	rb.SetMultipartRelated(
+//		httpr.RelatedPart{ContentType: "application/json", Body: metadata},
+//		httpr.RelatedPart{ContentType: "image/png", Body: file},
+//	)
+//
+// The first part is root part, which media type is sent as "type" parameter of Content-Type.
+// Reader parts are streamed; request body can be rewound for retries, if they implement io.Seeker.
+func (rb *RequestBuilder) SetMultipartRelated(parts ...RelatedPart) *RequestBuilder {
+	rb.body = &relatedBody{parts: parts}
+	return rb
+}
+
+// SetSizedBody sets streamed request body of known size, so it's sent with correct 'Content-Length'
+// header instead of chunked transfer encoding. If reader implements io.Seeker, it's rewound
+// to its current offset on retries.
+func (rb *RequestBuilder) SetSizedBody(r io.Reader, size int64) *RequestBuilder {
+	rb.body = &sizedBody{r: r, size: size}
+	return rb
+}
+
+// relatedBody is "multipart/related" request body.
+type relatedBody struct {
+	parts    []RelatedPart
+	segments []func() io.ReadCloser
+}
+
+func (b *relatedBody) prepare() (int64, string, error) {
+	if len(b.parts) == 0 {
+		return 0, "", errors.New("multipart/related body must contain at least one part")
+	}
+
+	var (
+		buf    bytes.Buffer
+		w      = multipart.NewWriter(&buf)
+		length int64
+	)
+
+	b.segments = nil
+	flush := func() {
+		data := append([]byte(nil), buf.Bytes()...)
+		if length >= 0 {
+			length += int64(len(data))
+		}
+		b.segments = append(b.segments, func() io.ReadCloser {
+			return io.NopCloser(bytes.NewReader(data))
+		})
+		buf.Reset()
+	}
+
+	for i, part := range b.parts {
+		header := make(textproto.MIMEHeader, len(part.Header)+1)
+		for key, value := range part.Header {
+			header.Set(key, value)
+		}
+		if part.ContentType != "" {
+			header.Set("Content-Type", part.ContentType)
+		}
+		if _, err := w.CreatePart(header); err != nil {
+			return 0, "", err
+		}
+		flush()
+
+		segment, size, err := relatedPartSegment(part)
+		if err != nil {
+			return 0, "", fmt.Errorf("part %d: %w", i, err)
+		}
+		if size < 0 {
+			length = -1
+		} else if length >= 0 {
+			length += size
+		}
+		b.segments = append(b.segments, segment)
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, "", err
+	}
+	flush()
+
+	params := map[string]string{"boundary": w.Boundary()}
+	if rootType := b.parts[0].ContentType; rootType != "" {
+		if mediaType, _, err := mime.ParseMediaType(rootType); err == nil {
+			params["type"] = mediaType
+		}
+	}
+
+	return length, mime.FormatMediaType("multipart/related", params), nil
+}
+
+func (b *relatedBody) open() io.ReadCloser {
+	readers := make([]io.Reader, 0, len(b.segments))
+	closers := make([]io.Closer, 0, len(b.segments))
+	for _, segment := range b.segments {
+		rc := segment()
+		readers = append(readers, rc)
+		closers = append(closers, rc)
+	}
+
+	return &multiCloseBody{Reader: io.MultiReader(readers...), closers: closers}
+}
+
+// relatedPartSegment returns function opening part content along with its size, -1 if it's unknown.
+func relatedPartSegment(part RelatedPart) (func() io.ReadCloser, int64, error) {
+	var data []byte
+	switch body := part.Body.(type) {
+	case nil:
+	case []byte:
+		data = body
+	case string:
+		data = []byte(body)
+	case io.Reader:
+		sized := &sizedBody{r: body, size: part.Size}
+		if sized.size == 0 {
+			sized.size = readerSize(body)
+		}
+		if _, _, err := sized.prepare(); err != nil {
+			return nil, 0, err
+		}
+		return sized.open, sized.size, nil
+	default:
+		encoded, err := marshalBody(part.ContentType, body)
+		if err != nil {
+			return nil, 0, err
+		}
+		data = encoded
+	}
+
+	return func() io.ReadCloser {
+		return io.NopCloser(bytes.NewReader(data))
+	}, int64(len(data)), nil
+}
+
+// readerSize returns number of bytes left in reader, if it's known, or -1.
+func readerSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	default:
+		return -1
+	}
+}
+
+// sizedBody is streamed request body of known size. Seekable readers are rewound
+// to the offset, which they had on request building.
+type sizedBody struct {
+	r      io.Reader
+	size   int64
+	offset int64
+}
+
+func (b *sizedBody) prepare() (int64, string, error) {
+	if seeker, ok := b.r.(io.Seeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to get body offset: %w", err)
+		}
+		b.offset = offset
+	}
+
+	return b.size, "", nil
+}
+
+func (b *sizedBody) open() io.ReadCloser {
+	if seeker, ok := b.r.(io.Seeker); ok {
+		if _, err := seeker.Seek(b.offset, io.SeekStart); err != nil {
+			return io.NopCloser(&errReader{err: fmt.Errorf("failed to rewind body: %w", err)})
+		}
+	}
+
+	var r io.Reader = b.r
+	if b.size >= 0 {
+		r = io.LimitReader(r, b.size)
+	}
+	return io.NopCloser(r)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
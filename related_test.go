@@ -0,0 +1,180 @@
+package httpr
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type relatedRequest struct {
+	contentLength    int64
+	transferEncoding []string
+	mediaType        string
+	params           map[string]string
+	parts            []relatedRequestPart
+}
+
+type relatedRequestPart struct {
+	header http.Header
+	body   string
+}
+
+func newRelatedServer(t *testing.T, requests *[]relatedRequest) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil {
+			t.Errorf("invalid Content-Type: %s", err)
+			return
+		}
+
+		r := relatedRequest{
+			contentLength:    req.ContentLength,
+			transferEncoding: req.TransferEncoding,
+			mediaType:        mediaType,
+			params:           params,
+		}
+		mr := multipart.NewReader(req.Body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("failed to read part: %s", err)
+				return
+			}
+			data, _ := io.ReadAll(part)
+			r.parts = append(r.parts, relatedRequestPart{header: http.Header(part.Header), body: string(data)})
+		}
+		*requests = append(*requests, r)
+
+		if len(*requests) == 1 && req.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+}
+
+func TestRequestBuilderSetMultipartRelated(t *testing.T) {
+	var requests []relatedRequest
+	ts := newRelatedServer(t, &requests)
+	defer ts.Close()
+
+	rb := NewRequest().Post(ts.URL+"?fail=1", nil).SetMultipartRelated(
+		RelatedPart{ContentType: "application/json; charset=UTF-8", Body: map[string]string{"name": "photo.png"}},
+		RelatedPart{ContentType: "image/png", Header: map[string]string{"Content-ID": "<media>"}, Body: bytes.NewReader([]byte("PNGDATA"))},
+	)
+	client := New(
+		WithRetryCount(2),
+		WithRetryCondition(func(resp *Response, err error) bool { return err != nil || resp.StatusCode() >= 500 }),
+	)
+	if _, err := client.DoBuilder(rb); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d instead", len(requests))
+	}
+	for _, r := range requests {
+		if r.mediaType != "multipart/related" || r.params["type"] != "application/json" {
+			t.Errorf("expected multipart/related with JSON root type, got %s %v instead", r.mediaType, r.params)
+		}
+		if r.contentLength <= 0 {
+			t.Errorf("expected known Content-Length, got %d instead", r.contentLength)
+		}
+		if len(r.parts) != 2 {
+			t.Fatalf("expected 2 parts, got %d instead", len(r.parts))
+		}
+		if r.parts[0].body != `{"name":"photo.png"}` || r.parts[0].header.Get("Content-Type") != "application/json; charset=UTF-8" {
+			t.Errorf("unexpected metadata part %+v", r.parts[0])
+		}
+		if r.parts[1].body != "PNGDATA" || r.parts[1].header.Get("Content-ID") != "<media>" {
+			t.Errorf("unexpected media part %+v", r.parts[1])
+		}
+	}
+}
+
+func TestRequestBuilderSetMultipartRelatedUnknownSize(t *testing.T) {
+	var requests []relatedRequest
+	ts := newRelatedServer(t, &requests)
+	defer ts.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte("streamed"))
+		_ = pw.Close()
+	}()
+
+	rb := NewRequest().Post(ts.URL, nil).SetMultipartRelated(
+		RelatedPart{ContentType: "application/json", Body: `{"name":"stream"}`},
+		RelatedPart{ContentType: "application/octet-stream", Body: pr},
+	)
+	if _, err := New().DoBuilder(rb); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(requests) != 1 || len(requests[0].parts) != 2 || requests[0].parts[1].body != "streamed" {
+		t.Fatalf("unexpected requests %+v", requests)
+	}
+	if requests[0].contentLength != -1 || len(requests[0].transferEncoding) == 0 {
+		t.Errorf("expected chunked request, got Content-Length %d instead", requests[0].contentLength)
+	}
+}
+
+func TestRequestBuilderSetSizedBody(t *testing.T) {
+	var (
+		lengths []int64
+		bodies  []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		lengths = append(lengths, req.ContentLength)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "body")
+	if err := os.WriteFile(path, []byte("skip:payload"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err = f.Seek(int64(len("skip:")), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	client := New(
+		WithRetryCount(2),
+		WithRetryCondition(func(resp *Response, err error) bool { return err != nil || resp.StatusCode() >= 500 }),
+	)
+	if _, err = client.DoBuilder(NewRequest().Put(ts.URL, nil).SetSizedBody(f, int64(len("payload")))); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Join(bodies, ",") != "payload,payload" {
+		t.Errorf("expected body to be rewound on retry, got %q instead", bodies)
+	}
+	for _, length := range lengths {
+		if length != int64(len("payload")) {
+			t.Errorf("expected Content-Length %d, got %d instead", len("payload"), length)
+		}
+	}
+
+	if _, err = client.DoBuilder(NewRequest().Put(ts.URL, nil).SetSizedBody(strings.NewReader("short"), 10)); err == nil {
+		t.Error("expected error for body shorter than its size, got nil instead")
+	}
+}
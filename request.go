@@ -2,11 +2,15 @@ package httpr
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"time"
 )
 
 // RequestBuilder struct provides convenient interface
@@ -14,14 +18,26 @@ import (
 type RequestBuilder struct {
 	err error
 
-	ctx                  context.Context
-	url                  *url.URL
-	method               string
-	body                 any
-	headers              map[string][]string
-	queryParams          url.Values
-	cookies              []*http.Cookie
-	basicAuthCredentials *struct {
+	ctx           context.Context
+	url           *url.URL
+	method        string
+	body          any
+	headers       map[string][]string
+	queryParams   url.Values
+	queryEncoding QueryEncoding
+	contentLength *int64
+	forceChunked  bool
+	fragment      *string
+	userInfo      *url.Userinfo
+	host          string
+	accept        []qualityValue
+	tags          map[string]string
+
+	disableContentTypeDetection bool
+	strictURLValidation         bool
+	strictHeaderValidation      bool
+	cookies                     []*http.Cookie
+	basicAuthCredentials        *struct {
 		user string
 		pass string
 	}
@@ -42,6 +58,39 @@ func (rb *RequestBuilder) SetURL(requestURL string) *RequestBuilder {
 	return rb
 }
 
+// SetStrictURLValidation enables strict validation of request URL, which is made on Build.
+// See ValidateURL for details.
+func (rb *RequestBuilder) SetStrictURLValidation(enabled bool) *RequestBuilder {
+	rb.strictURLValidation = enabled
+	return rb
+}
+
+// SetStrictHeaderValidation enables validation of request headers on Build, which fails with ErrInvalidHeader
+// instead of producing malformed request. See WithStrictHeaderValidation for details.
+func (rb *RequestBuilder) SetStrictHeaderValidation(enabled bool) *RequestBuilder {
+	rb.strictHeaderValidation = enabled
+	return rb
+}
+
+// SetFragment sets URL fragment (part after "#") for current request. Fragment is escaped
+// properly when URL is composed.
+func (rb *RequestBuilder) SetFragment(fragment string) *RequestBuilder {
+	rb.fragment = &fragment
+	return rb
+}
+
+// SetUserInfo sets URL user information for current request. If pass is empty,
+// only user name is set.
+func (rb *RequestBuilder) SetUserInfo(user, pass string) *RequestBuilder {
+	if pass == "" {
+		rb.userInfo = url.User(user)
+	} else {
+		rb.userInfo = url.UserPassword(user, pass)
+	}
+
+	return rb
+}
+
 // SetMethod sets method for current request.
 func (rb *RequestBuilder) SetMethod(method string) *RequestBuilder {
 	rb.method = method
@@ -119,12 +168,161 @@ func (rb *RequestBuilder) Trace(requestURL string) *RequestBuilder {
 
 // SetBody method sets body for current request.
 // Body can be one of following concrete types or types, which implement
-// interfaces: string, []byte, io.Reader.
+// interfaces: string, []byte, io.Reader. Values of other types are marshalled
+// on Build with codec registered for request Content-Type header, see RegisterCodec.
 func (rb *RequestBuilder) SetBody(body any) *RequestBuilder {
 	rb.body = body
 	return rb
 }
 
+// SetJSONBody marshals provided value to JSON and sets it as request body along with
+// "application/json" Content-Type header. Marshalling error is returned by Build.
+// Body is kept in memory, so it can be sent again on request retries.
+func (rb *RequestBuilder) SetJSONBody(v any) *RequestBuilder {
+	body, err := json.Marshal(v)
+	if err != nil {
+		rb.err = fmt.Errorf("failed to marshal JSON body: %w", err)
+		return rb
+	}
+
+	rb.body = body
+	rb.replaceHeader("Content-Type", "application/json")
+	return rb
+}
+
+// SetXMLBody marshals provided value to XML and sets it as request body prefixed with
+// standard XML header, along with "application/xml" Content-Type header.
+// Marshalling error is returned by Build.
+func (rb *RequestBuilder) SetXMLBody(v any) *RequestBuilder {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		rb.err = fmt.Errorf("failed to marshal XML body: %w", err)
+		return rb
+	}
+
+	rb.body = append([]byte(xml.Header), body...)
+	rb.replaceHeader("Content-Type", "application/xml")
+	return rb
+}
+
+// SetProtoBody marshals provided Protocol Buffers message and sets it as request body along with
+// "application/x-protobuf" Content-Type header. Codec for MediaTypeProtobuf is registered by importing
+// optional github.com/hickar/httpr/protocodec module. Marshalling error is returned by Build.
+func (rb *RequestBuilder) SetProtoBody(msg any) *RequestBuilder {
+	return rb.SetCodecBody(MediaTypeProtobuf, msg)
+}
+
+// SetCodecBody marshals provided value with codec registered for media type (see RegisterCodec)
+// and sets it as request body along with corresponding Content-Type header.
+// Marshalling error is returned by Build.
+func (rb *RequestBuilder) SetCodecBody(mediaType string, v any) *RequestBuilder {
+	body, err := marshalBody(mediaType, v)
+	if err != nil {
+		rb.err = err
+		return rb
+	}
+
+	rb.body = body
+	rb.replaceHeader("Content-Type", mediaType)
+	return rb
+}
+
+// SetFormData encodes provided key/value pairs as "application/x-www-form-urlencoded" body
+// and sets corresponding Content-Type header.
+func (rb *RequestBuilder) SetFormData(data map[string]string) *RequestBuilder {
+	values := make(url.Values, len(data))
+	for key, value := range data {
+		values.Set(key, value)
+	}
+
+	return rb.setFormValues(values)
+}
+
+// SetFormDataFromStruct encodes fields of provided struct as "application/x-www-form-urlencoded" body
+// and sets corresponding Content-Type header. Field names are taken from "form" struct tags,
+// falling back to Go field names. Tag option "omitempty" skips zero values, tag "-" skips field.
+func (rb *RequestBuilder) SetFormDataFromStruct(v any) *RequestBuilder {
+	values, err := encodeFormStruct(v)
+	if err != nil {
+		rb.err = fmt.Errorf("malformed form data: %w", err)
+		return rb
+	}
+
+	return rb.setFormValues(values)
+}
+
+func (rb *RequestBuilder) setFormValues(values url.Values) *RequestBuilder {
+	rb.body = values.Encode()
+	rb.replaceHeader("Content-Type", "application/x-www-form-urlencoded")
+	return rb
+}
+
+// SetBodyFromFile sets content of file located at path as request body. File is opened
+// only on request execution (and reopened on retries), Content-Length is set to file size.
+// If Content-Type header isn't set, it's detected by file extension or content.
+func (rb *RequestBuilder) SetBodyFromFile(path string) *RequestBuilder {
+	rb.body = fileBody{path: path}
+	return rb
+}
+
+// SetFiles sets "multipart/form-data" request body with files located at provided paths.
+// Map keys are used as form field names. Files are streamed on request execution.
+func (rb *RequestBuilder) SetFiles(files map[string]string) *RequestBuilder {
+	body := rb.multipartBody()
+	for field, path := range files {
+		body.files[field] = path
+	}
+
+	return rb
+}
+
+// SetMultipartFields sets form fields, which are sent along with files set by SetFiles
+// in "multipart/form-data" request body.
+func (rb *RequestBuilder) SetMultipartFields(fields map[string]string) *RequestBuilder {
+	body := rb.multipartBody()
+	for key, value := range fields {
+		body.fields[key] = value
+	}
+
+	return rb
+}
+
+func (rb *RequestBuilder) multipartBody() *multipartBody {
+	body, ok := rb.body.(*multipartBody)
+	if !ok {
+		body = &multipartBody{
+			fields: make(map[string]string),
+			files:  make(map[string]string),
+		}
+		rb.body = body
+	}
+
+	return body
+}
+
+// SetContentTypeDetection enables or disables automatic detection of 'Content-Type' header for
+// []byte, *os.File and file (SetBodyFromFile) bodies. Detection is enabled by default and takes
+// place only if 'Content-Type' header wasn't set explicitly. Type is detected by file extension,
+// falling back to http.DetectContentType.
+func (rb *RequestBuilder) SetContentTypeDetection(enabled bool) *RequestBuilder {
+	rb.disableContentTypeDetection = !enabled
+	return rb
+}
+
+// SetContentLength sets explicit length of request body. This is needed for io.Reader bodies of
+// known size, which otherwise are sent with chunked transfer encoding, rejected by some servers.
+func (rb *RequestBuilder) SetContentLength(length int64) *RequestBuilder {
+	rb.contentLength = &length
+	return rb
+}
+
+// SetForceChunked makes request body to be sent with chunked transfer encoding
+// even if its length is known.
+func (rb *RequestBuilder) SetForceChunked(enabled bool) *RequestBuilder {
+	rb.forceChunked = enabled
+	return rb
+}
+
 // SetContext sets context for current request. If provided context is nil,
 // new one will be created with context.Background().
 func (rb *RequestBuilder) SetContext(ctx context.Context) *RequestBuilder {
@@ -142,6 +340,33 @@ func (rb *RequestBuilder) SetHeader(key, value string) *RequestBuilder {
 	return rb
 }
 
+// headerValue returns first value of header with provided key regardless of key case.
+func (rb *RequestBuilder) headerValue(key string) string {
+	key = http.CanonicalHeaderKey(key)
+	for existingKey, values := range rb.headers {
+		if http.CanonicalHeaderKey(existingKey) == key && len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+// replaceHeader sets header with provided key, replacing all previously set values.
+func (rb *RequestBuilder) replaceHeader(key, value string) {
+	if rb.headers == nil {
+		rb.headers = make(map[string][]string)
+	}
+
+	key = http.CanonicalHeaderKey(key)
+	for existingKey := range rb.headers {
+		if http.CanonicalHeaderKey(existingKey) == key {
+			delete(rb.headers, existingKey)
+		}
+	}
+	rb.headers[key] = []string{value}
+}
+
 // SetHeaders creates and sets headers for each key/value pair in provided map.
 func (rb *RequestBuilder) SetHeaders(headers map[string]string) *RequestBuilder {
 	for key, value := range headers {
@@ -201,6 +426,50 @@ func (rb *RequestBuilder) SetQueryParams(params map[string]string) *RequestBuild
 	return rb
 }
 
+// AddQueryParam adds query parameter value with following key. Unlike SetQueryParam,
+// it doesn't replace values previously set for the same key.
+func (rb *RequestBuilder) AddQueryParam(key, value string) *RequestBuilder {
+	if strings.TrimSpace(key) == "" {
+		return rb
+	}
+
+	if rb.queryParams == nil {
+		rb.queryParams = make(url.Values)
+	}
+
+	rb.queryParams.Add(key, value)
+	return rb
+}
+
+// AddQueryParams adds multiple query parameter values by calling AddQueryParam for each
+// key/value in map.
+func (rb *RequestBuilder) AddQueryParams(params map[string][]string) *RequestBuilder {
+	for key, values := range params {
+		for _, value := range values {
+			rb.AddQueryParam(key, value)
+		}
+	}
+
+	return rb
+}
+
+// QueryEncoding controls encoding of query parameters set with RequestBuilder.
+// Zero value corresponds to url.Values.Encode behavior.
+type QueryEncoding struct {
+	// SpaceAsPercent20 makes spaces to be encoded as "%20" instead of "+".
+	SpaceAsPercent20 bool
+	// Unescaped contains characters, which are left unescaped in keys and values.
+	Unescaped string
+}
+
+// SetQueryEncoding sets encoding of query parameters for current request.
+// This can be used for legacy servers, which reject "+" in query values or
+// expect some reserved characters to be sent as is.
+func (rb *RequestBuilder) SetQueryEncoding(encoding QueryEncoding) *RequestBuilder {
+	rb.queryEncoding = encoding
+	return rb
+}
+
 // SetCookies sets cookies for current request.
 func (rb *RequestBuilder) SetCookies(cookies []*http.Cookie) *RequestBuilder {
 	rb.cookies = cookies
@@ -219,6 +488,82 @@ func (rb *RequestBuilder) SetBasicAuth(user, pass string) *RequestBuilder {
 	return rb
 }
 
+// SetHostHeader sets 'Host' header value, which is sent instead of URL host. Connection
+// is still established to URL host, TLS server name can be set separately with tls.Config.
+func (rb *RequestBuilder) SetHostHeader(host string) *RequestBuilder {
+	rb.host = host
+	return rb
+}
+
+// Accept adds media type to 'Accept' header with optional quality value in range [0, 1].
+// Multiple calls compose single header value, e.g.:
+//
+//	rb.Accept("application/json").Accept("application/xml", 0.5) // "application/json, application/xml;q=0.5"
+//
+// Header composed this way replaces 'Accept' header values set with SetHeader.
+func (rb *RequestBuilder) Accept(mediaType string, quality ...float64) *RequestBuilder {
+	rb.accept = append(rb.accept, newQualityValue(mediaType, quality))
+	return rb
+}
+
+// SetAcceptLanguage sets 'Accept-Language' header from language tags listed in order of preference.
+// Tags without explicit quality value get decreasing weights, e.g. ("en-US", "en", "de;q=0.5")
+// results in "en-US, en;q=0.9, de;q=0.5".
+func (rb *RequestBuilder) SetAcceptLanguage(tags ...string) *RequestBuilder {
+	rb.replaceHeader("Accept-Language", formatAcceptLanguage(tags))
+	return rb
+}
+
+// IfNoneMatch sets 'If-None-Match' header for conditional requests. Unquoted entity tags are quoted.
+func (rb *RequestBuilder) IfNoneMatch(etag string) *RequestBuilder {
+	rb.replaceHeader("If-None-Match", quoteETag(etag))
+	return rb
+}
+
+// IfMatch sets 'If-Match' header for conditional requests, e.g. for optimistic concurrency control
+// of updates. Unquoted entity tags are quoted.
+func (rb *RequestBuilder) IfMatch(etag string) *RequestBuilder {
+	rb.replaceHeader("If-Match", quoteETag(etag))
+	return rb
+}
+
+// IfModifiedSince sets 'If-Modified-Since' header for conditional requests.
+func (rb *RequestBuilder) IfModifiedSince(t time.Time) *RequestBuilder {
+	rb.replaceHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+	return rb
+}
+
+// IfUnmodifiedSince sets 'If-Unmodified-Since' header for conditional requests.
+func (rb *RequestBuilder) IfUnmodifiedSince(t time.Time) *RequestBuilder {
+	rb.replaceHeader("If-Unmodified-Since", t.UTC().Format(http.TimeFormat))
+	return rb
+}
+
+// SetBearerToken sets 'Authorization' header with provided bearer token.
+func (rb *RequestBuilder) SetBearerToken(token string) *RequestBuilder {
+	return rb.SetAuthorization("Bearer", token)
+}
+
+// SetAuthorization sets 'Authorization' header with provided authentication scheme
+// and credentials, e.g. SetAuthorization("Token", "xxx").
+func (rb *RequestBuilder) SetAuthorization(scheme, credentials string) *RequestBuilder {
+	rb.replaceHeader("Authorization", scheme+" "+credentials)
+	return rb
+}
+
+// Send builds request and executes it with provided client and options. If client is nil,
+// DefaultClient is used. If ctx is nil, context set with SetContext is used.
+func (rb *RequestBuilder) Send(ctx context.Context, client *Client, opts ...Option) (*Response, error) {
+	if ctx != nil {
+		rb.SetContext(ctx)
+	}
+	if client == nil {
+		client = DefaultClient
+	}
+
+	return client.DoBuilder(rb, opts...)
+}
+
 // Build composes *http.Request instance. If errors occurred during previous building steps,
 // they will be returned.
 func (rb *RequestBuilder) Build() (*http.Request, error) {
@@ -228,9 +573,32 @@ func (rb *RequestBuilder) Build() (*http.Request, error) {
 	if rb.url == nil {
 		return nil, errors.New("request url is not set")
 	}
+	if rb.strictURLValidation {
+		if err := validateURL(rb.url, true); err != nil {
+			return nil, fmt.Errorf("invalid URL '%s': %w", rb.url.Redacted(), err)
+		}
+	}
+
+	reqURL := *rb.url
+	if rb.fragment != nil {
+		reqURL.Fragment = *rb.fragment
+		reqURL.RawFragment = ""
+	}
+	if rb.userInfo != nil {
+		reqURL.User = rb.userInfo
+	}
 
-	reqURL := composeURL(rb.url, rb.queryParams)
-	reqBody, err := convertBodyToReader(rb.body)
+	body := rb.body
+	if contentType := rb.headerValue("Content-Type"); contentType != "" && !isRawBody(body, contentType) {
+		encoded, err := marshalBody(contentType, body)
+		if err != nil {
+			return nil, err
+		}
+		body = encoded
+	}
+
+	composedURL := composeURL(&reqURL, rb.queryEncoding.encode(rb.queryParams))
+	reqBody, err := convertBodyToReader(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build request body: %w", err)
 	}
@@ -240,12 +608,19 @@ func (rb *RequestBuilder) Build() (*http.Request, error) {
 	if reqCtx == nil {
 		reqCtx = context.Background()
 	}
+	if len(rb.tags) > 0 {
+		reqCtx = ContextWithTags(reqCtx, rb.tags)
+	}
 
-	req, err := http.NewRequestWithContext(reqCtx, reqMethod, reqURL, reqBody)
+	req, err := http.NewRequestWithContext(reqCtx, reqMethod, composedURL, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
+	if rb.host != "" {
+		req.Host = rb.host
+	}
+
 	if rb.basicAuthCredentials != nil {
 		req.SetBasicAuth(rb.basicAuthCredentials.user, rb.basicAuthCredentials.pass)
 	}
@@ -255,16 +630,43 @@ func (rb *RequestBuilder) Build() (*http.Request, error) {
 			req.Header.Add(key, value)
 		}
 	}
+	if len(rb.accept) > 0 {
+		req.Header.Set("Accept", formatQualityValues(rb.accept))
+	}
 
 	for _, cookie := range rb.cookies {
 		req.AddCookie(cookie)
 	}
 
+	if rb.strictHeaderValidation {
+		if err = validateHeaders(req); err != nil {
+			return nil, err
+		}
+	}
+
+	detectContentType := !rb.disableContentTypeDetection && req.Header.Get("Content-Type") == ""
+	if provider, ok := rb.body.(bodyProvider); ok {
+		if err = attachBody(req, provider, detectContentType); err != nil {
+			return nil, fmt.Errorf("failed to build request body: %w", err)
+		}
+	} else if detectContentType {
+		if contentType := detectBodyContentType(body); contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+	}
+
+	switch {
+	case rb.forceChunked && req.Body != nil:
+		req.ContentLength = -1
+		req.TransferEncoding = []string{"chunked"}
+	case rb.contentLength != nil:
+		req.ContentLength = *rb.contentLength
+	}
+
 	return req, nil
 }
 
-func composeURL(reqURL *url.URL, params url.Values) string {
-	encodedQuery := params.Encode()
+func composeURL(reqURL *url.URL, encodedQuery string) string {
 	if encodedQuery == "" {
 		return reqURL.String()
 	}
@@ -278,6 +680,56 @@ func composeURL(reqURL *url.URL, params url.Values) string {
 	return reqURL.String()
 }
 
+func (e QueryEncoding) encode(params url.Values) string {
+	if e == (QueryEncoding{}) {
+		return params.Encode()
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, key := range keys {
+		escapedKey := e.escape(key)
+		for _, value := range params[key] {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(escapedKey)
+			buf.WriteByte('=')
+			buf.WriteString(e.escape(value))
+		}
+	}
+
+	return buf.String()
+}
+
+func (e QueryEncoding) escape(s string) string {
+	escaped := url.QueryEscape(s)
+	if e.SpaceAsPercent20 {
+		escaped = strings.ReplaceAll(escaped, "+", "%20")
+	}
+
+	for _, r := range e.Unescaped {
+		if encodedRune := url.QueryEscape(string(r)); encodedRune != string(r) {
+			escaped = strings.ReplaceAll(escaped, encodedRune, string(r))
+		}
+	}
+
+	return escaped
+}
+
+func quoteETag(etag string) string {
+	if etag == "*" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+
+	return `"` + etag + `"`
+}
+
 func composeMethod(method string) string {
 	if method == "" {
 		return http.MethodGet
@@ -287,8 +739,8 @@ func composeMethod(method string) string {
 }
 
 func parseURL(requestURL string) (*url.URL, error) {
-	if !IsValidURL(requestURL) {
-		return nil, fmt.Errorf("invalid URL '%s'", requestURL)
+	if err := ValidateURL(requestURL, false); err != nil {
+		return nil, fmt.Errorf("invalid URL '%s': %w", requestURL, err)
 	}
 
 	return url.Parse(requestURL)
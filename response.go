@@ -4,31 +4,112 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 )
 
 // Response is a wrapper above standard http.Response objects, with some
 // convenience methods.
 type Response struct {
-	rawResp *http.Response
-	body    []byte
+	rawResp     *http.Response
+	body        []byte
+	bodyFile    *os.File
+	errorResult any
+	tags        map[string]string
+	fromCache   bool
+	stale       bool
+	fallback    bool
 }
 
-// Bytes returns byte slice representation of response body.
+// NewResponse creates response with provided status, headers and body, which isn't received from
+// server, e.g. default response returned by FallbackFunc or AttemptDirective. Nil header is allowed.
+func NewResponse(statusCode int, header http.Header, body []byte) *Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &Response{
+		rawResp: &http.Response{
+			Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+			StatusCode:    statusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+		},
+		body: body,
+	}
+}
+
+// Tags returns request tags, see RequestBuilder.SetTag and WithTags. Returned map must not be modified.
+func (r *Response) Tags() map[string]string {
+	if r == nil {
+		return nil
+	}
+	return r.tags
+}
+
+// FromCache reports whether response was served from cache (see WithCache), including
+// responses revalidated with origin.
+func (r *Response) FromCache() bool {
+	return r != nil && r.fromCache
+}
+
+// Stale reports whether response is stale copy served from cache, because origin failed,
+// see WithStaleIfError.
+func (r *Response) Stale() bool {
+	return r != nil && r.stale
+}
+
+// IsFallback reports whether response was returned by fallback function instead of error, see WithFallback.
+func (r *Response) IsFallback() bool {
+	return r != nil && r.fallback
+}
+
+// Bytes returns byte slice representation of response body. Body of spilled response
+// (see WithBodySpillThreshold) is read from temporary file on every call.
 func (r *Response) Bytes() []byte {
-	if r == nil || r.rawResp == nil || r.body == nil {
+	if r == nil || r.rawResp == nil {
 		return []byte{}
 	}
 
-	return r.body
+	body, err := r.bodyBytes()
+	if err != nil || body == nil {
+		return []byte{}
+	}
+	return body
 }
 
-// Reader returns io.Reader.
+// bodyBytes returns response body, reading it from temporary file for spilled responses.
+// Nil is returned, if response has no body.
+func (r *Response) bodyBytes() ([]byte, error) {
+	if r.bodyFile == nil {
+		return r.body, nil
+	}
+
+	body, err := io.ReadAll(io.NewSectionReader(r.bodyFile, 0, r.spilledSize()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spilled response body: %w", err)
+	}
+	return body, nil
+}
+
+// Reader returns io.Reader. Body of spilled response (see WithBodySpillThreshold)
+// is streamed from temporary file, so every call returns independent reader.
 func (r *Response) Reader() io.Reader {
-	if r.rawResp == nil {
+	if r == nil || r.rawResp == nil {
 		return bytes.NewReader([]byte{})
 	}
+	if r.bodyFile != nil {
+		return io.NewSectionReader(r.bodyFile, 0, r.spilledSize())
+	}
 
 	return bytes.NewReader(r.body)
 }
@@ -49,7 +130,44 @@ func (r *Response) StatusCode() int {
 	return r.rawResp.StatusCode
 }
 
-// Headers returns a map of headers.
+// Header returns response headers with all their values. If underlying response is nil,
+// returns empty header.
+func (r *Response) Header() http.Header {
+	if r == nil || r.rawResp == nil || r.rawResp.Header == nil {
+		return make(http.Header)
+	}
+
+	return r.rawResp.Header
+}
+
+// HeaderValues returns all values of response header associated with provided key.
+func (r *Response) HeaderValues(key string) []string {
+	return r.Header().Values(key)
+}
+
+// ContentType returns media type and its parameters (like charset) parsed from 'Content-Type'
+// header. If header is absent, empty media type and nil error are returned.
+func (r *Response) ContentType() (string, map[string]string, error) {
+	contentType := r.Header().Get("Content-Type")
+	if contentType == "" {
+		return "", nil, nil
+	}
+
+	return mime.ParseMediaType(contentType)
+}
+
+// ContentLength returns length of response body reported by server, -1 if length is unknown.
+// If underlying response is nil, returns 0.
+func (r *Response) ContentLength() int64 {
+	if r == nil || r.rawResp == nil {
+		return 0
+	}
+
+	return r.rawResp.ContentLength
+}
+
+// Headers returns a map of headers. Only first value of each header is returned,
+// use Header or HeaderValues to access all values.
 func (r *Response) Headers() map[string]string {
 	headers := make(map[string]string)
 	if r.rawResp == nil {
@@ -64,6 +182,47 @@ func (r *Response) Headers() map[string]string {
 	return headers
 }
 
+// IsSuccess reports whether response status code is 2xx.
+func (r *Response) IsSuccess() bool {
+	return Is2xx(r.StatusCode())
+}
+
+// IsRedirect reports whether response status code is 3xx.
+func (r *Response) IsRedirect() bool {
+	return Is3xx(r.StatusCode())
+}
+
+// IsClientError reports whether response status code is 4xx.
+func (r *Response) IsClientError() bool {
+	return Is4xx(r.StatusCode())
+}
+
+// IsServerError reports whether response status code is 5xx.
+func (r *Response) IsServerError() bool {
+	return Is5xx(r.StatusCode())
+}
+
+// IsError reports whether response status code is either 4xx or 5xx.
+func (r *Response) IsError() bool {
+	return r.IsClientError() || r.IsServerError()
+}
+
+// NotModified reports whether response has status 304 Not Modified, which is returned
+// for conditional requests, when resource wasn't changed.
+func (r *Response) NotModified() bool {
+	return r.StatusCode() == http.StatusNotModified
+}
+
+// ETag returns value of 'ETag' header, which can be passed to RequestBuilder.IfNoneMatch
+// or RequestBuilder.IfMatch for subsequent conditional requests.
+func (r *Response) ETag() string {
+	if r == nil || r.rawResp == nil {
+		return ""
+	}
+
+	return r.rawResp.Header.Get("ETag")
+}
+
 // Cookies returns slice of response cookies.
 func (r *Response) Cookies() []*http.Cookie {
 	if r.rawResp == nil {
@@ -75,21 +234,177 @@ func (r *Response) Cookies() []*http.Cookie {
 
 // RequestURL returns request original URL.
 func (r *Response) RequestURL() string {
-	if r == nil || r.rawResp == nil {
+	if r == nil || r.rawResp == nil || r.rawResp.Request == nil || r.rawResp.Request.URL == nil {
 		return ""
 	}
 
 	return r.rawResp.Request.URL.String()
 }
 
+// Location returns URL from 'Location' header resolved against request URL. It can be used for
+// following redirects manually or for retrieving URL of created resource. If header is absent,
+// http.ErrNoLocation is returned.
+func (r *Response) Location() (*url.URL, error) {
+	if r == nil || r.rawResp == nil {
+		return nil, http.ErrNoLocation
+	}
+
+	return r.rawResp.Location()
+}
+
 // JSON unmarshalls response JSON body and stores result
 // in values pointed by p.
 func (r *Response) JSON(p any) error {
-	if r == nil || r.body == nil {
+	if r == nil || (r.body == nil && r.bodyFile == nil) {
+		return errors.New("response body is nil")
+	}
+
+	body, err := r.bodyBytes()
+	if err != nil {
+		return err
+	}
+	return withSentinel(ErrDecodeBody, json.Unmarshal(body, p))
+}
+
+// ErrorJSON unmarshalls JSON body of unsuccessful (non-2xx) response into value pointed by p.
+// For successful responses p is left untouched and nil is returned, so success and error
+// schemas can be decoded side by side:
+//
+//	if err = resp.ErrorJSON(&apiErr); err != nil {
+//		return err
+//	}
+//	if !resp.IsSuccess() {
+//		return fmt.Errorf("API error: %s", apiErr.Message)
+//	}
+//	err = resp.JSON(&item)
+func (r *Response) ErrorJSON(p any) error {
+	if r.IsSuccess() {
+		return nil
+	}
+
+	return r.JSON(p)
+}
+
+// ErrorResult returns value, in which body of unsuccessful (non-2xx) response was decoded with target
+// created by factory set with WithErrorTarget. Nil is returned for successful responses or
+// if factory isn't set.
+func (r *Response) ErrorResult() any {
+	if r == nil {
+		return nil
+	}
+
+	return r.errorResult
+}
+
+// Decode unmarshalls response body into value pointed by p with codec registered for
+// media type from response 'Content-Type' header (see RegisterCodec). Media types with
+// "+json" and "+xml" suffixes are decoded with JSON and XML codecs respectively.
+func (r *Response) Decode(p any) error {
+	if r == nil || (r.body == nil && r.bodyFile == nil) {
+		return errors.New("response body is nil")
+	}
+
+	contentType := r.Header().Get("Content-Type")
+	if contentType == "" {
+		return fmt.Errorf("%w: response has no Content-Type", ErrCodecNotRegistered)
+	}
+
+	codec, err := lookupCodec(contentType)
+	if err != nil {
+		return err
+	}
+
+	body, err := r.bodyBytes()
+	if err != nil {
+		return err
+	}
+	return withSentinel(ErrDecodeBody, codec.Unmarshal(body, p))
+}
+
+// DecodeAs unmarshalls response body into value pointed by p with codec of provided format,
+// ignoring Content-Type header. It's useful, when server reports wrong content type:
+//
+//	var rows []map[string]string
+//	err = resp.DecodeAs(httpr.FormatCSV, &rows)
+//
+// CSV can be decoded into *[][]string, *[]map[string]string keyed by header columns
+// or pointer to slice of structs, which fields are matched with columns by "csv" struct tags.
+func (r *Response) DecodeAs(format Format, p any) error {
+	if r == nil || (r.body == nil && r.bodyFile == nil) {
 		return errors.New("response body is nil")
 	}
 
-	return json.Unmarshal(r.body, p)
+	codec, err := lookupCodec(string(format))
+	if err != nil {
+		return err
+	}
+
+	body, err := r.bodyBytes()
+	if err != nil {
+		return err
+	}
+	return withSentinel(ErrDecodeBody, codec.Unmarshal(body, p))
+}
+
+// Proto unmarshalls response Protocol Buffers body into provided message. Codec for
+// MediaTypeProtobuf is registered by importing optional github.com/hickar/httpr/protocodec module.
+func (r *Response) Proto(msg any) error {
+	if r == nil || (r.body == nil && r.bodyFile == nil) {
+		return errors.New("response body is nil")
+	}
+
+	codec, err := lookupCodec(MediaTypeProtobuf)
+	if err != nil {
+		return err
+	}
+
+	body, err := r.bodyBytes()
+	if err != nil {
+		return err
+	}
+	return withSentinel(ErrDecodeBody, codec.Unmarshal(body, msg))
+}
+
+// SaveFile writes response body to file located at path with provided permissions.
+// Body is written to temporary file in the same directory first, which is then renamed,
+// so file at path is never left partially written. Spilled bodies are streamed from
+// their temporary files without loading into memory.
+func (r *Response) SaveFile(path string, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err = io.Copy(tmp, r.Reader()); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write response body: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync response body: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// SaveFileAll is like SaveFile, but also creates missing parent directories.
+func (r *Response) SaveFileAll(path string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to create parent directories: %w", err)
+	}
+
+	return r.SaveFile(path, perm)
 }
 
 // Raw returns reference to underlying http.Response object. Call to this method handles control
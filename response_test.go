@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -129,3 +132,187 @@ func assertNoPanic(t *testing.T, fn func()) {
 
 	fn()
 }
+
+func TestResponseNotModified(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer ts.Close()
+
+	c := New()
+	resp, err := c.Get(context.Background(), ts.URL, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.NotModified() {
+		t.Fatal("first response must not be 304 Not Modified")
+	}
+
+	resp, err = NewRequest().Get(ts.URL, nil).IfNoneMatch(resp.ETag()).Send(context.Background(), c)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !resp.NotModified() {
+		t.Fatalf("expected 304 Not Modified response, got %d", resp.StatusCode())
+	}
+}
+
+func TestResponseHeader(t *testing.T) {
+	t.Run("NilResponse", func(t *testing.T) {
+		resp := (*Response)(nil)
+		assertNoPanic(t, func() { resp.Header() })
+		assertNoPanic(t, func() { resp.HeaderValues("Link") })
+	})
+
+	t.Run("MultipleValues", func(t *testing.T) {
+		resp := &Response{rawResp: &http.Response{Header: http.Header{
+			"Link": {`<https://test.com?page=2>; rel="next"`, `<https://test.com?page=5>; rel="last"`},
+		}}}
+
+		if values := resp.HeaderValues("link"); len(values) != 2 {
+			t.Errorf("expected 2 header values, got %q", values)
+		}
+		if len(resp.Header()["Link"]) != 2 {
+			t.Errorf("expected header to contain all values, got %q", resp.Header()["Link"])
+		}
+	})
+}
+
+func TestResponseContentTypeAndLength(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+
+	resp, err := New().Get(context.Background(), ts.URL, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mediaType, params, err := resp.ContentType()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mediaType != "application/json" || params["charset"] != "utf-8" {
+		t.Errorf("unexpected media type %q with params %v", mediaType, params)
+	}
+
+	if resp.ContentLength() != int64(len(`{"ok":true}`)) {
+		t.Errorf("expected content length %d, got %d", len(`{"ok":true}`), resp.ContentLength())
+	}
+
+	if mediaType, _, err = (*Response)(nil).ContentType(); mediaType != "" || err != nil {
+		t.Errorf("expected empty media type for nil response, got %q, %v", mediaType, err)
+	}
+}
+
+func TestResponseStatusPredicates(t *testing.T) {
+	tests := []struct {
+		statusCode    int
+		isSuccess     bool
+		isRedirect    bool
+		isClientError bool
+		isServerError bool
+	}{
+		{statusCode: http.StatusOK, isSuccess: true},
+		{statusCode: http.StatusFound, isRedirect: true},
+		{statusCode: http.StatusNotFound, isClientError: true},
+		{statusCode: http.StatusBadGateway, isServerError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			resp := &Response{rawResp: &http.Response{StatusCode: tt.statusCode}}
+
+			if resp.IsSuccess() != tt.isSuccess ||
+				resp.IsRedirect() != tt.isRedirect ||
+				resp.IsClientError() != tt.isClientError ||
+				resp.IsServerError() != tt.isServerError ||
+				resp.IsError() != (tt.isClientError || tt.isServerError) {
+				t.Errorf("unexpected predicates result for status code %d", tt.statusCode)
+			}
+		})
+	}
+
+	if (*Response)(nil).IsSuccess() {
+		t.Error("nil response must not be successful")
+	}
+}
+
+func TestResponseLocation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			w.Header().Set("Location", "../items/42")
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer ts.Close()
+
+	c := New()
+	resp, err := c.Post(context.Background(), ts.URL+"/v1/create/item", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expected := ts.URL + "/v1/items/42"; location.String() != expected {
+		t.Errorf("expected location %q, got %q", expected, location.String())
+	}
+
+	resp, err = c.Get(context.Background(), ts.URL, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err = resp.Location(); !errors.Is(err, http.ErrNoLocation) {
+		t.Errorf("expected http.ErrNoLocation, got %v", err)
+	}
+}
+
+func TestResponseRequestURL(t *testing.T) {
+	if requestURL := NewResponse(http.StatusOK, nil, nil).RequestURL(); requestURL != "" {
+		t.Errorf("expected empty request URL of detached response, got %q instead", requestURL)
+	}
+	if requestURL := (*Response)(nil).RequestURL(); requestURL != "" {
+		t.Errorf("expected empty request URL of nil response, got %q instead", requestURL)
+	}
+}
+
+func TestResponseSaveFile(t *testing.T) {
+	resp := &Response{rawResp: &http.Response{}, body: []byte(_testMsg)}
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "nested", "dir", "file.txt")
+	if err := resp.SaveFile(path, 0o600); err == nil {
+		t.Fatal("expected error for missing parent directories, got nil")
+	}
+
+	if err := resp.SaveFileAll(path, 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(data) != _testMsg {
+		t.Errorf("expected file content %q, got %q", _testMsg, data)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected temporary files to be removed, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,63 @@
+package httpr
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+var _responsePool = sync.Pool{
+	New: func() any { return new(Response) },
+}
+
+// AcquireResponse returns empty response from pool to be filled with Client.DoInto.
+// Response should be returned to pool with Response.Release once it's no longer needed.
+func AcquireResponse() *Response {
+	r, _ := _responsePool.Get().(*Response)
+	return r
+}
+
+// Release resets response and returns it to pool used by AcquireResponse, so it and its body
+// buffer can be reused by subsequent requests. Neither response nor values returned by its
+// methods (e.g. Bytes) must be used after Release. Responses with bodies larger than
+// pooled buffer limit are released without their body buffer.
+func (r *Response) Release() {
+	if r == nil {
+		return
+	}
+
+	buf := r.reset()
+	if cap(buf) > _maxPooledBufferSize {
+		buf = nil
+	}
+	r.body = buf
+	_responsePool.Put(r)
+}
+
+// reset clears response for reuse, removing temporary body file of spilled response.
+// Emptied body buffer is returned, so its memory can be reused.
+func (r *Response) reset() []byte {
+	_ = r.Close()
+
+	buf := r.body[:0]
+	*r = Response{}
+	return buf
+}
+
+// readInto reads src until EOF, appending data to provided buffer.
+func readInto(buf []byte, src io.Reader) ([]byte, error) {
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+
+		n, err := src.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if errors.Is(err, io.EOF) {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+}
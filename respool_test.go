@@ -0,0 +1,108 @@
+package httpr
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDoInto(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"` + req.URL.Path + `"}`))
+	}))
+	defer ts.Close()
+
+	client := New()
+	resp := AcquireResponse()
+	defer resp.Release()
+
+	var firstBuf []byte
+	for i, path := range []string{"/first", "/second"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if err := client.DoInto(req, resp); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		var result struct {
+			Path string `json:"path"`
+		}
+		if err := resp.JSON(&result); err != nil {
+			t.Fatalf("failed to decode body: %s", err)
+		}
+		if result.Path != path {
+			t.Errorf("expected path '%s', got '%s' instead", path, result.Path)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Errorf("expected status %d, got %d instead", http.StatusOK, resp.StatusCode())
+		}
+
+		if i == 0 {
+			firstBuf = resp.body[:1]
+		} else if &firstBuf[0] != &resp.body[0] {
+			t.Error("expected body buffer to be reused")
+		}
+	}
+
+	if err := client.DoInto(nil, nil); err == nil {
+		t.Error("expected error for nil response, got nil instead")
+	}
+}
+
+func TestReleaseResetsResponse(t *testing.T) {
+	resp := AcquireResponse()
+	resp.rawResp = &http.Response{StatusCode: http.StatusTeapot}
+	resp.body = append(resp.body, "body"...)
+	resp.errorResult = "error"
+
+	resp.Release()
+	if resp.rawResp != nil || len(resp.body) != 0 || resp.errorResult != nil {
+		t.Errorf("expected response to be reset, got %+v instead", resp)
+	}
+}
+
+func TestReadInto(t *testing.T) {
+	data := strings.Repeat("abcdef", 1000)
+	for _, buf := range [][]byte{nil, make([]byte, 0, 10), make([]byte, 0, 10000)} {
+		body, err := readInto(buf, iotest.OneByteReader(strings.NewReader(data)))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !bytes.Equal(body, []byte(data)) {
+			t.Errorf("expected %d bytes of data, got %d instead", len(data), len(body))
+		}
+	}
+}
+
+func BenchmarkDoInto(b *testing.B) {
+	payload := bytes.Repeat([]byte{'x'}, 16<<10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write(payload)
+	}))
+	defer ts.Close()
+
+	client := New()
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+
+	b.Run("Do", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.Do(req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("DoInto", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resp := AcquireResponse()
+			if err := client.DoInto(req, resp); err != nil {
+				b.Fatal(err)
+			}
+			resp.Release()
+		}
+	})
+}
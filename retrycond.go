@@ -0,0 +1,86 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// RetryOn5xx is RetryConditionFunc, which requests retry of responses with 5xx status.
+func RetryOn5xx(resp *Response, _ error) bool {
+	return resp != nil && Is5xx(resp.StatusCode())
+}
+
+// RetryOnStatus returns RetryConditionFunc, which requests retry of responses with provided statuses.
+func RetryOnStatus(codes ...int) RetryConditionFunc {
+	statuses := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		statuses[code] = struct{}{}
+	}
+
+	return func(resp *Response, _ error) bool {
+		if resp == nil {
+			return false
+		}
+		_, ok := statuses[resp.StatusCode()]
+		return ok
+	}
+}
+
+// RetryOnNetworkError is RetryConditionFunc, which requests retry of requests failed with network
+// errors: dial, DNS and timeout errors and connections closed prematurely. Cancellation of request
+// context and requests rejected by client itself (see WithSSRFProtection) aren't retried.
+func RetryOnNetworkError(_ *Response, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrSSRFBlocked) {
+		return false
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// DefaultRetryCondition is RetryConditionFunc, which is used unless other is set with WithRetryCondition.
+// It requests retry of network errors, 5xx responses and 429 Too Many Requests responses.
+var DefaultRetryCondition = Or(RetryOnNetworkError, RetryOn5xx, RetryOnStatus(http.StatusTooManyRequests))
+
+// Not returns RetryConditionFunc, which negates provided one.
+func Not(conditionFn RetryConditionFunc) RetryConditionFunc {
+	return func(resp *Response, err error) bool {
+		return !conditionFn(resp, err)
+	}
+}
+
+// And returns RetryConditionFunc, which requests retry, if all provided conditions do.
+func And(conditionFns ...RetryConditionFunc) RetryConditionFunc {
+	return func(resp *Response, err error) bool {
+		for _, fn := range conditionFns {
+			if !fn(resp, err) {
+				return false
+			}
+		}
+		return len(conditionFns) > 0
+	}
+}
+
+// Or returns RetryConditionFunc, which requests retry, if any of provided conditions does.
+func Or(conditionFns ...RetryConditionFunc) RetryConditionFunc {
+	return func(resp *Response, err error) bool {
+		for _, fn := range conditionFns {
+			if fn(resp, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
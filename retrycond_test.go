@@ -0,0 +1,90 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func statusResponse(code int) *Response {
+	return &Response{rawResp: &http.Response{StatusCode: code}}
+}
+
+func TestRetryConditionPresets(t *testing.T) {
+	var (
+		dialErr    = &url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+		eofErr     = &url.Error{Op: "Get", URL: "http://localhost", Err: io.ErrUnexpectedEOF}
+		canceled   = &url.Error{Op: "Get", URL: "http://localhost", Err: context.Canceled}
+		ssrfErr    = &url.Error{Op: "Get", URL: "http://localhost", Err: withSentinel(ErrSSRFBlocked, errors.New("blocked"))}
+		plainErr   = errors.New("failed to build request")
+		wrappedDNS = fmt.Errorf("failed: %w", &net.DNSError{Err: "no such host", Name: "example.invalid"})
+	)
+
+	testCases := []struct {
+		name     string
+		fn       RetryConditionFunc
+		resp     *Response
+		err      error
+		expected bool
+	}{
+		{name: "5xx on 503", fn: RetryOn5xx, resp: statusResponse(http.StatusServiceUnavailable), expected: true},
+		{name: "5xx on 404", fn: RetryOn5xx, resp: statusResponse(http.StatusNotFound)},
+		{name: "5xx on error", fn: RetryOn5xx, err: dialErr},
+		{name: "status on matching", fn: RetryOnStatus(409, 423), resp: statusResponse(423), expected: true},
+		{name: "status on other", fn: RetryOnStatus(409, 423), resp: statusResponse(200)},
+		{name: "status on nil response", fn: RetryOnStatus(409), err: dialErr},
+		{name: "network on dial error", fn: RetryOnNetworkError, err: dialErr, expected: true},
+		{name: "network on DNS error", fn: RetryOnNetworkError, err: wrappedDNS, expected: true},
+		{name: "network on unexpected EOF", fn: RetryOnNetworkError, err: eofErr, expected: true},
+		{name: "network on cancellation", fn: RetryOnNetworkError, err: canceled},
+		{name: "network on SSRF block", fn: RetryOnNetworkError, err: ssrfErr},
+		{name: "network on other error", fn: RetryOnNetworkError, err: plainErr},
+		{name: "network on success", fn: RetryOnNetworkError, resp: statusResponse(200)},
+		{name: "not", fn: Not(RetryOn5xx), resp: statusResponse(200), expected: true},
+		{name: "and", fn: And(RetryOn5xx, Not(RetryOnStatus(501))), resp: statusResponse(501)},
+		{name: "and all", fn: And(RetryOn5xx, Not(RetryOnStatus(501))), resp: statusResponse(502), expected: true},
+		{name: "and empty", fn: And(), resp: statusResponse(502)},
+		{name: "or", fn: Or(RetryOn5xx, RetryOnStatus(429)), resp: statusResponse(429), expected: true},
+		{name: "or empty", fn: Or(), resp: statusResponse(502)},
+		{name: "default on 429", fn: DefaultRetryCondition, resp: statusResponse(429), expected: true},
+		{name: "default on 500", fn: DefaultRetryCondition, resp: statusResponse(500), expected: true},
+		{name: "default on 400", fn: DefaultRetryCondition, resp: statusResponse(400)},
+		{name: "default on 200", fn: DefaultRetryCondition, resp: statusResponse(200)},
+		{name: "default on network error", fn: DefaultRetryCondition, err: dialErr, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.fn(tc.resp, tc.err); got != tc.expected {
+				t.Errorf("expected %v, got %v instead", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDefaultRetryCondition(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(WithRetryCount(3), WithClock(NewFakeClock(time.Unix(0, 0))), WithRetryDelay(time.Second))
+	resp, err := client.Get(context.Background(), ts.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.StatusCode() != http.StatusOK || calls != 2 {
+		t.Errorf("expected successful response after 2 attempts, got %d after %d attempts instead", resp.StatusCode(), calls)
+	}
+}
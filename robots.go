@@ -0,0 +1,312 @@
+package httpr
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// _robotsCacheTTL is duration, for which fetched robots.txt is cached.
+	_robotsCacheTTL = 24 * time.Hour
+	// _robotsMaxSize is maximum size of parsed robots.txt, as recommended by RFC 9309.
+	_robotsMaxSize = 500 << 10
+)
+
+// RobotsPolicy specifies whether client complies with robots.txt rules of requested hosts.
+type RobotsPolicy int
+
+const (
+	// RobotsIgnore makes client ignore robots.txt. It is default policy.
+	RobotsIgnore RobotsPolicy = iota
+	// RobotsRespect makes client fetch robots.txt of each requested host and refuse to execute
+	// requests, which are disallowed for client User-Agent, with ErrDisallowedByRobots.
+	RobotsRespect
+)
+
+// robotsCache keeps parsed robots.txt files of hosts.
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+	// delayer enforces Crawl-delay, when per-host delay isn't set with WithPerHostDelay.
+	delayer *hostDelayer
+}
+
+type robotsEntry struct {
+	robots    *robotsTxt
+	expiresAt time.Time
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{
+		entries: make(map[string]*robotsEntry),
+		delayer: newHostDelayer(0, nil),
+	}
+}
+
+// lookup returns cached robots.txt of host, which requested URL belongs to.
+func (c *robotsCache) lookup(u *url.URL) (*robotsTxt, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[u.Scheme+"://"+u.Host]
+	if !ok {
+		return nil, false
+	}
+
+	return entry.robots, true
+}
+
+// get returns robots.txt of host, which requested URL belongs to, fetching it if needed.
+func (c *robotsCache) get(ctx context.Context, httpClient *http.Client, u *url.URL) (*robotsTxt, error) {
+	origin := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry, ok := c.entries[origin]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.robots, nil
+	}
+
+	robots, err := fetchRobots(ctx, httpClient, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[origin] = &robotsEntry{robots: robots, expiresAt: time.Now().Add(_robotsCacheTTL)}
+	c.mu.Unlock()
+
+	return robots, nil
+}
+
+// fetchRobots fetches and parses robots.txt of origin. According to RFC 9309, unavailable (4xx)
+// robots.txt allows everything and unreachable (5xx) one disallows everything.
+func fetchRobots(ctx context.Context, httpClient *http.Client, origin string) (*robotsTxt, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create robots.txt request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case Is2xx(resp.StatusCode):
+		return parseRobots(io.LimitReader(resp.Body, _robotsMaxSize)), nil
+	case Is4xx(resp.StatusCode):
+		return &robotsTxt{}, nil
+	default:
+		return &robotsTxt{disallowAll: true}, nil
+	}
+}
+
+// robotsTxt is parsed robots.txt file.
+type robotsTxt struct {
+	groups      []robotsGroup
+	disallowAll bool
+}
+
+type robotsGroup struct {
+	userAgents []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow bool
+	path  string
+}
+
+func parseRobots(r io.Reader) *robotsTxt {
+	var (
+		robots   = new(robotsTxt)
+		scanner  = bufio.NewScanner(r)
+		group    *robotsGroup
+		hasRules bool
+	)
+
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines start the same group.
+			if group == nil || hasRules {
+				robots.groups = append(robots.groups, robotsGroup{})
+				group = &robots.groups[len(robots.groups)-1]
+				hasRules = false
+			}
+			group.userAgents = append(group.userAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			if group == nil {
+				continue
+			}
+			hasRules = true
+			if value != "" {
+				group.rules = append(group.rules, robotsRule{allow: key == "allow", path: value})
+			}
+		case "crawl-delay":
+			if group == nil {
+				continue
+			}
+			hasRules = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				group.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	return robots
+}
+
+// group returns group of rules, which applies to provided User-Agent.
+func (r *robotsTxt) group(userAgent string) *robotsGroup {
+	token, _, _ := strings.Cut(strings.ToLower(userAgent), "/")
+	token = strings.TrimSpace(token)
+
+	var (
+		matched  *robotsGroup
+		wildcard *robotsGroup
+		longest  int
+	)
+	for i := range r.groups {
+		for _, agent := range r.groups[i].userAgents {
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = &r.groups[i]
+				}
+			case token != "" && strings.Contains(token, agent) && len(agent) > longest:
+				matched = &r.groups[i]
+				longest = len(agent)
+			}
+		}
+	}
+
+	if matched != nil {
+		return matched
+	}
+	return wildcard
+}
+
+// allowed reports whether path (with query) can be fetched by provided User-Agent.
+// The most specific (longest) matching rule wins, allow rule wins ties.
+func (r *robotsTxt) allowed(userAgent, path string) bool {
+	if r.disallowAll {
+		return false
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	group := r.group(userAgent)
+	if group == nil {
+		return true
+	}
+
+	var (
+		allow   = true
+		longest = -1
+	)
+	for _, rule := range group.rules {
+		if !matchRobotsPattern(rule.path, path) {
+			continue
+		}
+		if len(rule.path) > longest || (len(rule.path) == longest && rule.allow) {
+			allow = rule.allow
+			longest = len(rule.path)
+		}
+	}
+
+	return allow
+}
+
+// crawlDelay returns Crawl-delay specified for provided User-Agent.
+func (r *robotsTxt) crawlDelay(userAgent string) time.Duration {
+	if group := r.group(userAgent); group != nil {
+		return group.crawlDelay
+	}
+
+	return 0
+}
+
+// matchRobotsPattern reports whether path matches robots.txt path pattern, which may contain
+// "*" wildcards and "$" end anchor.
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+
+	return !anchored || rest == ""
+}
+
+// checkRobots returns ErrDisallowedByRobots, if request is disallowed by robots.txt of requested host.
+func checkRobots(httpClient *http.Client, req *http.Request, settings clientSettings) error {
+	if settings.robotsPolicy != RobotsRespect || settings.robotsCache == nil {
+		return nil
+	}
+
+	robots, err := settings.robotsCache.get(req.Context(), httpClient, req.URL)
+	if err != nil {
+		return err
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+
+	if !robots.allowed(robotsUserAgent(req), path) {
+		return fmt.Errorf("%w: %s", ErrDisallowedByRobots, req.URL.Redacted())
+	}
+
+	return nil
+}
+
+// robotsUserAgent returns User-Agent, which is sent with request.
+func robotsUserAgent(req *http.Request) string {
+	if userAgent := req.Header.Get("User-Agent"); userAgent != "" {
+		return userAgent
+	}
+
+	return "Go-http-client"
+}
@@ -0,0 +1,92 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const _testRobotsTxt = `# robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: testbot
+User-agent: otherbot
+Disallow: /
+Allow: /open
+Crawl-delay: 0.5
+`
+
+func TestRobotsRules(t *testing.T) {
+	robots := parseRobots(strings.NewReader(_testRobotsTxt))
+
+	tests := []struct {
+		userAgent string
+		path      string
+		allowed   bool
+	}{
+		{userAgent: "Mozilla/5.0", path: "/index.html", allowed: true},
+		{userAgent: "Mozilla/5.0", path: "/private/data", allowed: false},
+		{userAgent: "Mozilla/5.0", path: "/private/public/page", allowed: true},
+		{userAgent: "Mozilla/5.0", path: "/docs/file.pdf", allowed: false},
+		{userAgent: "Mozilla/5.0", path: "/docs/file.pdf?download=1", allowed: true},
+		{userAgent: "TestBot/1.0", path: "/index.html", allowed: false},
+		{userAgent: "TestBot/1.0", path: "/open/page", allowed: true},
+		{userAgent: "OtherBot", path: "/robots.txt", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userAgent+tt.path, func(t *testing.T) {
+			if allowed := robots.allowed(tt.userAgent, tt.path); allowed != tt.allowed {
+				t.Errorf("expected allowed %t, got %t instead", tt.allowed, allowed)
+			}
+		})
+	}
+
+	if delay := robots.crawlDelay("Mozilla/5.0"); delay != 2*time.Second {
+		t.Errorf("expected crawl delay 2s, got %v instead", delay)
+	}
+	if delay := robots.crawlDelay("testbot"); delay != 500*time.Millisecond {
+		t.Errorf("expected crawl delay 500ms, got %v instead", delay)
+	}
+}
+
+func TestRobotsPolicy(t *testing.T) {
+	var robotsRequests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsRequests, 1)
+			_, _ = w.Write([]byte(_testRobotsTxt))
+			return
+		}
+		_, _ = w.Write([]byte(_testMsg))
+	}))
+	defer ts.Close()
+
+	c := New(WithRobotsPolicy(RobotsRespect), WithDefaultHeader("User-Agent", "TestBot/1.0"))
+
+	if _, err := c.Get(context.Background(), ts.URL+"/open/page", nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err := c.Get(context.Background(), ts.URL+"/closed", nil)
+	if !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %v", err)
+	}
+
+	if _, err = c.Get(context.Background(), ts.URL+"/closed", nil, WithRobotsPolicy(RobotsIgnore)); err != nil {
+		t.Fatalf("expected no error with ignored robots.txt, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&robotsRequests); n != 1 {
+		t.Errorf("expected robots.txt to be fetched once, got %d requests", n)
+	}
+}
@@ -0,0 +1,42 @@
+package httpr
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// routePolicy holds options, which are applied to requests matching method and path pattern.
+type routePolicy struct {
+	method  string
+	pattern string
+	opts    []Option
+}
+
+func newRoutePolicy(route string, opts []Option) routePolicy {
+	policy := routePolicy{pattern: strings.TrimSpace(route), opts: opts}
+	if method, pattern, found := strings.Cut(policy.pattern, " "); found {
+		policy.method = strings.ToUpper(method)
+		policy.pattern = strings.TrimSpace(pattern)
+	}
+
+	return policy
+}
+
+func (p routePolicy) matches(req *http.Request) bool {
+	if p.method != "" && p.method != req.Method {
+		return false
+	}
+
+	reqPath := req.URL.Path
+	if reqPath == "" {
+		reqPath = "/"
+	}
+
+	if prefix := strings.TrimSuffix(p.pattern, "/*"); prefix != p.pattern {
+		return reqPath == prefix || strings.HasPrefix(reqPath, prefix+"/")
+	}
+
+	matched, err := path.Match(p.pattern, reqPath)
+	return err == nil && matched
+}
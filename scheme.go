@@ -0,0 +1,142 @@
+package httpr
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SchemeHTTPUnix is URL scheme for plain HTTP over Unix domain socket. Socket path and request path
+// are separated with colon, e.g. "http+unix:///var/run/docker.sock:/v1.41/containers/json".
+const SchemeHTTPUnix = "http+unix"
+
+// Scheme describes how requests to URLs with custom scheme are sent.
+type Scheme struct {
+	// Transport sends requests with custom scheme as is, e.g. in-memory test transport.
+	// If set, Dial and Underlying are ignored.
+	Transport http.RoundTripper
+	// Dial opens connection to address returned by Address.
+	Dial func(ctx context.Context, addr string) (net.Conn, error)
+	// Address splits URL into address passed to Dial and path of request sent over connection.
+	// Defaults to URL host and path.
+	Address func(u *url.URL) (addr, path string)
+	// Underlying is scheme of protocol spoken over connections opened with Dial,
+	// "http" (default) or "https".
+	Underlying string
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]Scheme{
+		SchemeHTTPUnix: {
+			Dial: func(ctx context.Context, addr string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", addr)
+			},
+			Address: func(u *url.URL) (string, string) {
+				socket, path, _ := strings.Cut(u.Path, ":")
+				return socket, path
+			},
+		},
+	}
+
+	schemeTransportsMu sync.Mutex
+	schemeTransports   = make(map[string]*http.Transport)
+)
+
+// RegisterScheme registers custom URL scheme globally, replacing previously registered one.
+// URLs with registered schemes pass URL validation without host checks and are sent with scheme
+// transport or dialer. Built-in scheme is SchemeHTTPUnix.
+// For example, to address in-memory test transport:
+//
+//	httpr.RegisterScheme("mock", httpr.Scheme{Transport: mockTransport})
+//	resp, err := client.Get(ctx, "mock://users-service/users/1", nil)
+func RegisterScheme(scheme string, s Scheme) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+
+	schemes[strings.ToLower(scheme)] = s
+}
+
+func lookupScheme(scheme string) (Scheme, bool) {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+
+	s, ok := schemes[strings.ToLower(scheme)]
+	return s, ok
+}
+
+// schemeTransport sends requests with registered custom schemes, passing other requests
+// (e.g. redirects to regular URLs) to next transport.
+type schemeTransport struct {
+	next http.RoundTripper
+}
+
+func (tr *schemeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s, ok := lookupScheme(req.URL.Scheme)
+	if !ok {
+		next := tr.next
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		return next.RoundTrip(req)
+	}
+	if s.Transport != nil {
+		return s.Transport.RoundTrip(req)
+	}
+
+	underlying := s.Underlying
+	if underlying == "" {
+		underlying = "http"
+	}
+
+	addr, path := req.URL.Host, req.URL.Path
+	if s.Address != nil {
+		addr, path = s.Address(req.URL)
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	// Connection target is defined by transport dialer, while placeholder host keeps
+	// 'Host' header valid for addresses like socket paths.
+	outReq := req.Clone(req.Context())
+	outReq.URL.Scheme = underlying
+	outReq.URL.Host = "localhost"
+	if path != req.URL.Path {
+		outReq.URL.Path, outReq.URL.RawPath = path, ""
+	}
+	if req.Host == "" || req.Host == req.URL.Host {
+		outReq.Host = "localhost"
+	}
+
+	resp, err := dialTransport(req.URL.Scheme, addr, s).RoundTrip(outReq)
+	if resp != nil {
+		resp.Request = req
+	}
+	return resp, err
+}
+
+// dialTransport returns transport, which connects to provided address with scheme dialer. Transports
+// are cached per scheme and address, so connections to different addresses never share pool.
+func dialTransport(scheme, addr string, s Scheme) *http.Transport {
+	key := strings.ToLower(scheme) + "://" + addr
+
+	schemeTransportsMu.Lock()
+	defer schemeTransportsMu.Unlock()
+
+	if tr, ok := schemeTransports[key]; ok {
+		return tr
+	}
+
+	tr := DefaultTransport()
+	tr.Proxy = nil
+	tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return s.Dial(ctx, addr)
+	}
+	schemeTransports[key] = tr
+	return tr
+}
@@ -0,0 +1,86 @@
+package httpr
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestHTTPUnixScheme(t *testing.T) {
+	dir, err := os.MkdirTemp("", "httpr")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "api.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets aren't supported: %s", err)
+	}
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, req.Host+" "+req.URL.RequestURI())
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	requestURL := SchemeHTTPUnix + "://" + socket + ":/v1/containers/json?all=1"
+	if err = ValidateURL(requestURL, true); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	resp, err := New().Get(context.Background(), requestURL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := "localhost /v1/containers/json?all=1"; resp.String() != expected {
+		t.Errorf("expected response '%s', got '%s' instead", expected, resp.String())
+	}
+	if resp.RequestURL() != requestURL {
+		t.Errorf("expected request URL '%s', got '%s' instead", requestURL, resp.RequestURL())
+	}
+}
+
+func TestRegisterSchemeTransport(t *testing.T) {
+	RegisterScheme("httpr-test", Scheme{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(req.URL.String())),
+				Request:    req,
+			}, nil
+		}),
+	})
+	defer func() {
+		schemesMu.Lock()
+		delete(schemes, "httpr-test")
+		schemesMu.Unlock()
+	}()
+
+	req, err := NewRequest().SetURL("httpr-test://users/1").SetStrictURLValidation(true).Build()
+	if err != nil {
+		t.Fatalf("failed to build request: %s", err)
+	}
+
+	resp, err := New().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.String() != "httpr-test://users/1" {
+		t.Errorf("expected response 'httpr-test://users/1', got '%s' instead", resp.String())
+	}
+}
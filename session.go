@@ -0,0 +1,246 @@
+package httpr
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// CSRFConfig describes, where session takes CSRF token from and how it sends token back.
+// Token is taken from cookie, response header or HTML meta tag, whichever is configured and present.
+type CSRFConfig struct {
+	// CookieName is name of cookie holding token, e.g. "XSRF-TOKEN".
+	CookieName string
+	// HeaderName is name of header, in which token is sent with unsafe (non GET, HEAD, OPTIONS
+	// or TRACE) requests, e.g. "X-CSRF-Token". Token is also taken from response header with this name.
+	HeaderName string
+	// MetaName is name of HTML meta tag holding token, e.g. "csrf-token".
+	MetaName string
+	// FormField is name of form field, in which token is sent by Session.PostForm, e.g. "_csrf".
+	FormField string
+}
+
+// Session is a "logged-in browser tab" built on Client. It keeps cookies in its own jar,
+// resolves request paths against base URL, sends session-wide headers and extracts
+// and injects CSRF tokens. Session is safe for concurrent use.
+type Session struct {
+	client  *Client
+	jar     *CookieJar
+	baseURL *url.URL
+
+	mu        sync.RWMutex
+	headers   http.Header
+	csrf      CSRFConfig
+	csrfToken string
+}
+
+// NewSession creates session with provided base URL. Options are passed to underlying client.
+func NewSession(baseURL string, opts ...Option) (*Session, error) {
+	base, err := parseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	jar := NewCookieJar()
+	return &Session{
+		client:  New(append([]Option{WithCookieJar(jar)}, opts...)...),
+		jar:     jar,
+		baseURL: base,
+		headers: make(http.Header),
+	}, nil
+}
+
+// Client returns underlying client.
+func (s *Session) Client() *Client {
+	return s.client
+}
+
+// Jar returns session cookie jar.
+func (s *Session) Jar() *CookieJar {
+	return s.jar
+}
+
+// SetHeader sets header sent with each subsequent session request, unless request sets it explicitly.
+func (s *Session) SetHeader(key, value string) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.headers.Set(key, value)
+	return s
+}
+
+// SetCSRF sets CSRF token extraction and injection settings.
+func (s *Session) SetCSRF(cfg CSRFConfig) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.csrf = cfg
+	return s
+}
+
+// CSRFToken returns last CSRF token extracted from responses.
+func (s *Session) CSRFToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.csrfToken
+}
+
+// SetCSRFToken sets CSRF token explicitly.
+func (s *Session) SetCSRFToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.csrfToken = token
+}
+
+// URL resolves provided path or URL against session base URL.
+func (s *Session) URL(path string) (string, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL '%s': %w", path, err)
+	}
+
+	return s.baseURL.ResolveReference(ref).String(), nil
+}
+
+// NewRequest creates request builder with provided method and path resolved against base URL.
+func (s *Session) NewRequest(method, path string) *RequestBuilder {
+	rb := NewRequest().SetMethod(method)
+
+	requestURL, err := s.URL(path)
+	if err != nil {
+		rb.err = err
+		return rb
+	}
+
+	return rb.SetURL(requestURL)
+}
+
+// Do executes request within session.
+func (s *Session) Do(req *http.Request, opts ...Option) (*Response, error) {
+	s.prepare(req)
+
+	resp, err := s.client.Do(req, opts...)
+	if resp != nil && resp.rawResp != nil {
+		s.extractCSRFToken(resp)
+	}
+
+	return resp, err
+}
+
+// DoBuilder builds and executes request within session.
+func (s *Session) DoBuilder(rb *RequestBuilder, opts ...Option) (*Response, error) {
+	req, err := rb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Do(req, opts...)
+}
+
+// Get executes GET request with path resolved against base URL.
+func (s *Session) Get(ctx context.Context, path string, opts ...Option) (*Response, error) {
+	return s.DoBuilder(s.NewRequest(http.MethodGet, path).SetContext(ctx), opts...)
+}
+
+// Post executes POST request with path resolved against base URL.
+func (s *Session) Post(ctx context.Context, path string, body any, opts ...Option) (*Response, error) {
+	return s.DoBuilder(s.NewRequest(http.MethodPost, path).SetContext(ctx).SetBody(body), opts...)
+}
+
+// PostForm submits "application/x-www-form-urlencoded" form with POST request. If CSRF form field
+// is configured, current CSRF token is added to form data, which is useful for form-login flows.
+func (s *Session) PostForm(ctx context.Context, path string, data map[string]string, opts ...Option) (*Response, error) {
+	s.mu.RLock()
+	field, token := s.csrf.FormField, s.csrfToken
+	s.mu.RUnlock()
+
+	if field != "" && token != "" {
+		if _, ok := data[field]; !ok {
+			form := make(map[string]string, len(data)+1)
+			for key, value := range data {
+				form[key] = value
+			}
+			form[field] = token
+			data = form
+		}
+	}
+
+	return s.DoBuilder(s.NewRequest(http.MethodPost, path).SetContext(ctx).SetFormData(data), opts...)
+}
+
+// prepare adds session headers and CSRF token to request.
+func (s *Session) prepare(req *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	for key, values := range s.headers {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+
+	if s.csrf.HeaderName == "" || isSafeMethod(req.Method) || req.Header.Get(s.csrf.HeaderName) != "" {
+		return
+	}
+
+	token := s.csrfToken
+	if s.csrf.CookieName != "" {
+		for _, cookie := range s.client.Cookies(req.URL) {
+			if cookie.Name == s.csrf.CookieName {
+				token = cookie.Value
+			}
+		}
+	}
+	if token != "" {
+		req.Header.Set(s.csrf.HeaderName, token)
+	}
+}
+
+// extractCSRFToken remembers CSRF token found in response.
+func (s *Session) extractCSRFToken(resp *Response) {
+	s.mu.RLock()
+	cfg := s.csrf
+	s.mu.RUnlock()
+
+	var token string
+	if cfg.CookieName != "" {
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == cfg.CookieName && cookie.Value != "" {
+				token = cookie.Value
+			}
+		}
+	}
+	if cfg.HeaderName != "" && token == "" {
+		token = resp.Header().Get(cfg.HeaderName)
+	}
+	if cfg.MetaName != "" && token == "" {
+		if mediaType, _, err := mime.ParseMediaType(resp.Header().Get("Content-Type")); err == nil && mediaType == "text/html" {
+			if doc, err := resp.HTML(); err == nil {
+				if meta, err := doc.FindFirst(fmt.Sprintf("meta[name=%q]", cfg.MetaName)); err == nil {
+					token = meta.Attr("content")
+				}
+			}
+		}
+	}
+
+	if token != "" {
+		s.SetCSRFToken(token)
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionFormLogin(t *testing.T) {
+	const token = "t0k3n"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got := req.Header.Get("X-Client"); got != "session" {
+			t.Errorf("expected session header 'session', got '%s' instead", got)
+		}
+
+		switch req.URL.Path {
+		case "/login":
+			if req.Method == http.MethodGet {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				_, _ = w.Write([]byte(`<html><head><meta name="csrf-token" content="` + token + `"></head><body></body></html>`))
+				return
+			}
+
+			if err := req.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %s", err)
+			}
+			if got := req.PostForm.Get("_csrf"); got != token {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "user:" + req.PostForm.Get("user"), Path: "/"})
+		case "/api/items":
+			cookie, err := req.Cookie("session")
+			if err != nil || cookie.Value != "user:alice" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if got := req.Header.Get("X-CSRF-Token"); got != token {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer ts.Close()
+
+	session, err := NewSession(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to create session: %s", err)
+	}
+	session.SetHeader("X-Client", "session").SetCSRF(CSRFConfig{
+		HeaderName: "X-CSRF-Token",
+		MetaName:   "csrf-token",
+		FormField:  "_csrf",
+	})
+
+	ctx := context.Background()
+
+	if _, err = session.Get(ctx, "login"); err != nil {
+		t.Fatalf("failed to get login page: %s", err)
+	}
+	if got := session.CSRFToken(); got != token {
+		t.Fatalf("expected CSRF token '%s', got '%s' instead", token, got)
+	}
+
+	resp, err := session.PostForm(ctx, "/login", map[string]string{"user": "alice"})
+	if err != nil {
+		t.Fatalf("failed to log in: %s", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("expected login status %d, got %d instead", http.StatusOK, resp.StatusCode())
+	}
+
+	resp, err = session.Post(ctx, "api/items", nil)
+	if err != nil {
+		t.Fatalf("failed to create item: %s", err)
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d instead", http.StatusCreated, resp.StatusCode())
+	}
+}
+
+func TestSessionCSRFCookie(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			http.SetCookie(w, &http.Cookie{Name: "XSRF-TOKEN", Value: "from-cookie", Path: "/"})
+			return
+		}
+		_, _ = w.Write([]byte(req.Header.Get("X-XSRF-TOKEN")))
+	}))
+	defer ts.Close()
+
+	session, err := NewSession(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create session: %s", err)
+	}
+	session.SetCSRF(CSRFConfig{CookieName: "XSRF-TOKEN", HeaderName: "X-XSRF-TOKEN"})
+
+	ctx := context.Background()
+	if _, err = session.Get(ctx, "/"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := session.Post(ctx, "/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := resp.String(); got != "from-cookie" {
+		t.Errorf("expected CSRF header 'from-cookie', got '%s' instead", got)
+	}
+}
@@ -0,0 +1,79 @@
+package httpr
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+)
+
+// readSpilled reads body into memory, if it doesn't exceed threshold. Otherwise, whole body
+// is written to temporary file, which is returned instead.
+func readSpilled(src io.Reader, sizeHint, threshold int64) ([]byte, *os.File, error) {
+	if sizeHint > threshold {
+		sizeHint = threshold + 1
+	}
+
+	buf, err := readSized(io.LimitReader(src, threshold+1), sizeHint)
+	if err != nil || int64(len(buf)) <= threshold {
+		return buf, nil, err
+	}
+
+	file, err := os.CreateTemp("", "httpr-body-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	if _, err = file.Write(buf); err == nil {
+		_, err = io.Copy(file, src)
+	}
+	if err != nil {
+		removeSpilled(file)
+		return nil, nil, err
+	}
+
+	return nil, file, nil
+}
+
+// attachSpilled sets temporary body file of response, which is removed on Response.Close
+// or when response is garbage collected.
+func (r *Response) attachSpilled(file *os.File) {
+	r.bodyFile = file
+	runtime.SetFinalizer(r, func(r *Response) { _ = r.Close() })
+}
+
+// spilledSize returns size of temporary body file.
+func (r *Response) spilledSize() int64 {
+	info, err := r.bodyFile.Stat()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// Spilled reports whether response body exceeded threshold set with WithBodySpillThreshold
+// and was written to temporary file.
+func (r *Response) Spilled() bool {
+	return r != nil && r.bodyFile != nil
+}
+
+// Close removes temporary file holding body of spilled response. It's no-op for
+// responses with bodies kept in memory. Response body isn't available after Close.
+func (r *Response) Close() error {
+	if r == nil || r.bodyFile == nil {
+		return nil
+	}
+
+	err := removeSpilled(r.bodyFile)
+	r.bodyFile = nil
+	runtime.SetFinalizer(r, nil)
+	return err
+}
+
+func removeSpilled(file *os.File) error {
+	closeErr := file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,99 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBodySpillThreshold(t *testing.T) {
+	large := strings.Repeat(`{"item":"value"},`, 100)
+	large = "[" + strings.TrimSuffix(large, ",") + "]"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/small" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(large))
+	}))
+	defer ts.Close()
+
+	client := New(WithBodySpillThreshold(64))
+	ctx := context.Background()
+
+	t.Run("Small", func(t *testing.T) {
+		resp, err := client.Get(ctx, ts.URL+"/small", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer resp.Close()
+
+		if resp.Spilled() {
+			t.Error("expected small body to be kept in memory")
+		}
+		if resp.String() != "[]" {
+			t.Errorf("expected body '[]', got '%s' instead", resp.String())
+		}
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		resp, err := client.Get(ctx, ts.URL+"/large", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !resp.Spilled() {
+			t.Fatal("expected large body to be spilled")
+		}
+		path := resp.bodyFile.Name()
+
+		for i := 0; i < 2; i++ {
+			body, err := io.ReadAll(resp.Reader())
+			if err != nil {
+				t.Fatalf("failed to read body: %s", err)
+			}
+			if !bytes.Equal(body, []byte(large)) {
+				t.Fatalf("expected spilled body to match, got %d bytes instead", len(body))
+			}
+		}
+
+		var items []map[string]string
+		if err = resp.JSON(&items); err != nil {
+			t.Fatalf("failed to decode body: %s", err)
+		}
+		if len(items) != 100 {
+			t.Errorf("expected 100 items, got %d instead", len(items))
+		}
+
+		dst := filepath.Join(t.TempDir(), "body.json")
+		if err = resp.SaveFile(dst, 0o600); err != nil {
+			t.Fatalf("failed to save body: %s", err)
+		}
+		if saved, _ := os.ReadFile(dst); string(saved) != large {
+			t.Errorf("expected saved body to match, got %d bytes instead", len(saved))
+		}
+
+		if err = resp.Close(); err != nil {
+			t.Fatalf("failed to close response: %s", err)
+		}
+		if _, err = os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected temporary file to be removed, got %v instead", err)
+		}
+	})
+
+	t.Run("MaxResponseSize", func(t *testing.T) {
+		_, err := client.Get(ctx, ts.URL+"/large", nil, WithMaxResponseSize(128))
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("expected ErrResponseTooLarge, got %v instead", err)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package httpr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// _ssrfBlockedNetworks lists ranges, which aren't covered by net.IP classification methods,
+// but must never be reached from user-supplied URLs.
+var _ssrfBlockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT, also hosts some cloud metadata services
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved
+	"64:ff9b::/96",  // NAT64, may map to any IPv4 address
+)
+
+// isSSRFBlockedIP reports whether connections to provided IP address must be refused by SSRF protection.
+// Loopback, private (RFC 1918 and RFC 4193), link-local (including cloud metadata 169.254.169.254),
+// multicast, unspecified and reserved addresses are blocked.
+func isSSRFBlockedIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+
+	for _, network := range _ssrfBlockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ssrfDialContext wraps dial function, so target host is resolved beforehand and connection is made
+// only to allowed addresses. Since resolved address is dialed directly, DNS rebinding between check
+// and connection is impossible.
+func ssrfDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		dial = dialer.DialContext
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		ips, err := resolveSSRFSafe(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var dialErr error
+		for _, ip := range ips {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = err
+		}
+		return nil, dialErr
+	}
+}
+
+// resolveSSRFSafe resolves host and returns its addresses. Error is returned, if any of
+// addresses is blocked, so hosts having both public and internal records can't be used for bypass.
+func resolveSSRFSafe(ctx context.Context, host string) ([]net.IP, error) {
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	for _, ip := range ips {
+		if isSSRFBlockedIP(ip) {
+			return nil, withSentinel(ErrSSRFBlocked, fmt.Errorf("host '%s' resolves to blocked address %s", host, ip))
+		}
+	}
+	return ips, nil
+}
+
+// ssrfProxy wraps transport proxy function, so target host of proxied request is checked before
+// round trip. Dialer only connects to proxy, so it can't check target host itself.
+func ssrfProxy(proxy func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxy(req)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+		if _, err = resolveSSRFSafe(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		return proxyURL, nil
+	}
+}
+
+// ssrfCheckTransport checks request host before passing request to transport, which isn't
+// *http.Transport and thus can't be protected at dialer. Unlike dialer-level check, it doesn't
+// protect against DNS rebinding.
+type ssrfCheckTransport struct {
+	tr http.RoundTripper
+}
+
+func (tr *ssrfCheckTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, err := resolveSSRFSafe(req.Context(), req.URL.Hostname()); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	return tr.tr.RoundTrip(req)
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,147 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestIsSSRFBlockedIP(t *testing.T) {
+	testCases := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.100.100.200", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"::", true},
+		{"fe80::1", true},
+		{"fd00:ec2::254", true},
+		{"::ffff:127.0.0.1", true},
+		{"224.0.0.1", true},
+		{"8.8.8.8", false},
+		{"93.184.216.34", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ip, func(t *testing.T) {
+			if got := isSSRFBlockedIP(net.ParseIP(tc.ip)); got != tc.blocked {
+				t.Errorf("expected blocked %t, got %t instead", tc.blocked, got)
+			}
+		})
+	}
+}
+
+func TestSSRFDialContext(t *testing.T) {
+	var dialed string
+	dial := ssrfDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = addr
+		return nil, errors.New("stub")
+	})
+
+	if _, err := dial(context.Background(), "tcp", "93.184.216.34:443"); err == nil || err.Error() != "stub" {
+		t.Errorf("expected stub dial error, got %v instead", err)
+	}
+	if dialed != "93.184.216.34:443" {
+		t.Errorf("expected dialed address '93.184.216.34:443', got '%s' instead", dialed)
+	}
+
+	dialed = ""
+	if _, err := dial(context.Background(), "tcp", "localhost:80"); !errors.Is(err, ErrSSRFBlocked) {
+		t.Errorf("expected ErrSSRFBlocked, got %v instead", err)
+	}
+	if dialed != "" {
+		t.Errorf("expected blocked address not to be dialed, got '%s' instead", dialed)
+	}
+}
+
+func TestSSRFProtection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer ts.Close()
+
+	testCases := []struct {
+		name      string
+		client    *Client
+		opts      []Option
+		wantError bool
+	}{
+		{
+			name:   "disabled",
+			client: New(),
+		},
+		{
+			name:      "client-scoped",
+			client:    New(WithSSRFProtection()),
+			wantError: true,
+		},
+		{
+			name:      "request-scoped",
+			client:    New(),
+			opts:      []Option{WithSSRFProtection()},
+			wantError: true,
+		},
+		{
+			name:      "derived client",
+			client:    New().With(WithSSRFProtection()),
+			wantError: true,
+		},
+		{
+			name:      "derived client with new transport",
+			client:    New(WithSSRFProtection()).With(WithTransport(DefaultTransport())),
+			wantError: true,
+		},
+		{
+			name: "custom round tripper",
+			client: New(
+				WithTransport(NewBearerAuthTransport(http.DefaultTransport, "token")),
+				WithSSRFProtection(),
+			),
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+
+			_, err := tc.client.Do(req, tc.opts...)
+			if tc.wantError && !errors.Is(err, ErrSSRFBlocked) {
+				t.Errorf("expected ErrSSRFBlocked, got %v instead", err)
+			}
+			if !tc.wantError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestSSRFProtectionProxy(t *testing.T) {
+	proxyURL, _ := url.Parse("http://93.184.216.35:3128")
+	var settings clientSettings
+	WithSSRFProtection()(&settings)
+
+	tr, ok := tuneTransport(&http.Transport{Proxy: http.ProxyURL(proxyURL)}, settings).(*http.Transport)
+	if !ok {
+		t.Fatal("expected *http.Transport to be tuned")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data", nil)
+	if _, err := tr.Proxy(req); !errors.Is(err, ErrSSRFBlocked) {
+		t.Errorf("expected ErrSSRFBlocked for proxied internal target, got %v instead", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://93.184.216.34/", nil)
+	if u, err := tr.Proxy(req); err != nil || u != proxyURL {
+		t.Errorf("expected proxy %s for public target, got %v, %v instead", proxyURL, u, err)
+	}
+}
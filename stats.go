@@ -0,0 +1,131 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// StatsHandler receives structured events of request execution, see WithStatsHandler. It's single
+// integration point for metrics and tracing systems. Handler is called synchronously, so it must
+// be fast and safe for concurrent use.
+type StatsHandler interface {
+	HandleStats(ctx context.Context, event StatsEvent)
+}
+
+// StatsHandlerFunc is adapter, which allows usage of ordinary function as StatsHandler.
+type StatsHandlerFunc func(ctx context.Context, event StatsEvent)
+
+// HandleStats calls fn(ctx, event).
+func (fn StatsHandlerFunc) HandleStats(ctx context.Context, event StatsEvent) {
+	fn(ctx, event)
+}
+
+// StatsEvent is one of RequestStart, ResponseReceived, RetryScheduled and RequestEnd.
+type StatsEvent interface {
+	statsEvent()
+}
+
+// RequestStart is sent once Client.Do starts request execution.
+type RequestStart struct {
+	Request *http.Request
+	// Tags are request tags, see RequestBuilder.SetTag and WithTags.
+	Tags map[string]string
+	Time time.Time
+}
+
+// ResponseReceived is sent after every attempt, successful or not.
+type ResponseReceived struct {
+	Request *http.Request
+	// Tags are request tags, see RequestBuilder.SetTag and WithTags.
+	Tags map[string]string
+	// Attempt is number of attempt, starting with 1.
+	Attempt int
+	// StatusCode is response status or 0, if attempt failed with Err.
+	StatusCode int
+	// BodySize is number of response body bytes read, or Content-Length for unread bodies.
+	BodySize int64
+	// Duration is time taken by attempt.
+	Duration time.Duration
+	Err      error
+}
+
+// RetryReason tells, why request is retried.
+type RetryReason int
+
+const (
+	// RetryByCondition means retry condition requested retry.
+	RetryByCondition RetryReason = iota
+	// RetryThrottled means response was throttled with 429 Too Many Requests, see WithAutoRateLimitRetry.
+	RetryThrottled
+	// RetryForced means retry was forced by post-attempt hook, see AttemptDirective.
+	RetryForced
+)
+
+// RetryScheduled is sent, when request is going to be retried after delay.
+type RetryScheduled struct {
+	Request *http.Request
+	// Tags are request tags, see RequestBuilder.SetTag and WithTags.
+	Tags map[string]string
+	// Attempt is number of failed attempt, starting with 1.
+	Attempt int
+	Reason  RetryReason
+	Delay   time.Duration
+	// StatusCode is status of failed attempt response or 0, if attempt failed with Err.
+	StatusCode int
+	Err        error
+}
+
+// RequestEnd is sent once Client.Do finishes request execution.
+type RequestEnd struct {
+	Request *http.Request
+	// Tags are request tags, see RequestBuilder.SetTag and WithTags.
+	Tags     map[string]string
+	Attempts int
+	// StatusCode is final response status or 0, if request failed without response.
+	StatusCode int
+	// RequestSize is request Content-Length or -1, if it's unknown.
+	RequestSize int64
+	// ResponseSize is number of response body bytes read, or Content-Length for unread bodies.
+	ResponseSize int64
+	// Duration is total time of request execution, including delays between attempts.
+	Duration time.Duration
+	Err      error
+}
+
+func (RequestStart) statsEvent()     {}
+func (ResponseReceived) statsEvent() {}
+func (RetryScheduled) statsEvent()   {}
+func (RequestEnd) statsEvent()       {}
+
+// handleStats passes event to stats handler, if it's set.
+func handleStats(ctx context.Context, settings clientSettings, event StatsEvent) {
+	if settings.statsHandler != nil {
+		settings.statsHandler.HandleStats(ctx, event)
+	}
+}
+
+// bodySize returns number of response body bytes read, or Content-Length for unread bodies.
+func (r *Response) bodySize() int64 {
+	switch {
+	case r == nil || r.rawResp == nil:
+		return 0
+	case r.bodyFile != nil:
+		return r.spilledSize()
+	case r.body != nil:
+		return int64(len(r.body))
+	default:
+		return r.rawResp.ContentLength
+	}
+}
+
+// requestSize returns request Content-Length or -1, if it's unknown.
+func requestSize(req *http.Request) int64 {
+	if req.Body == nil || req.Body == http.NoBody {
+		return 0
+	}
+	if req.ContentLength == 0 {
+		return -1
+	}
+	return req.ContentLength
+}
@@ -0,0 +1,102 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatsHandler(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer ts.Close()
+
+	var (
+		mu     sync.Mutex
+		events []StatsEvent
+		clock  = NewFakeClock(time.Unix(0, 0))
+	)
+	client := New(
+		WithClock(clock),
+		WithRetryCount(3),
+		WithRetryDelay(time.Second),
+		WithRetryCondition(func(resp *Response, err error) bool {
+			return err != nil || resp.StatusCode() >= http.StatusInternalServerError
+		}),
+		WithStatsHandler(StatsHandlerFunc(func(_ context.Context, event StatsEvent) {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		})),
+	)
+
+	resp, err := client.Post(context.Background(), ts.URL, []byte("payload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(resp.Bytes()) != "hello" {
+		t.Fatalf("expected body %q, got %q instead", "hello", resp.Bytes())
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d instead: %#v", len(events), events)
+	}
+	if e, ok := events[0].(RequestStart); !ok || !e.Time.Equal(time.Unix(0, 0)) {
+		t.Errorf("expected RequestStart event, got %#v instead", events[0])
+	}
+	if e, ok := events[1].(ResponseReceived); !ok || e.Attempt != 1 || e.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected ResponseReceived event of 1 attempt with status 503, got %#v instead", events[1])
+	}
+	if e, ok := events[2].(RetryScheduled); !ok || e.Attempt != 1 || e.Reason != RetryByCondition || e.Delay != time.Second {
+		t.Errorf("expected RetryScheduled event after 1 attempt with 1s delay, got %#v instead", events[2])
+	}
+	if e, ok := events[3].(ResponseReceived); !ok || e.Attempt != 2 || e.StatusCode != http.StatusOK || e.BodySize != 5 {
+		t.Errorf("expected ResponseReceived event of 2 attempt with 5 bytes body, got %#v instead", events[3])
+	}
+
+	end, ok := events[4].(RequestEnd)
+	if !ok {
+		t.Fatalf("expected RequestEnd event, got %#v instead", events[4])
+	}
+	expected := RequestEnd{
+		Request:      end.Request,
+		Attempts:     2,
+		StatusCode:   http.StatusOK,
+		RequestSize:  7,
+		ResponseSize: 5,
+		Duration:     time.Second,
+	}
+	if !reflect.DeepEqual(end, expected) {
+		t.Errorf("expected %#v, got %#v instead", expected, end)
+	}
+}
+
+func TestStatsHandlerError(t *testing.T) {
+	var end *RequestEnd
+	client := New(WithStatsHandler(StatsHandlerFunc(func(_ context.Context, event StatsEvent) {
+		if e, ok := event.(RequestEnd); ok {
+			end = &e
+		}
+	})))
+
+	if _, err := client.Get(context.Background(), "http://127.0.0.1:1", nil); err == nil {
+		t.Fatal("expected error, got nil instead")
+	}
+	if end == nil {
+		t.Fatal("expected RequestEnd event to be sent")
+	}
+	if end.Err == nil || end.StatusCode != 0 || end.Attempts != 1 {
+		t.Errorf("expected failed RequestEnd event with 1 attempt, got %#v instead", *end)
+	}
+}
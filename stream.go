@@ -0,0 +1,120 @@
+package httpr
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StreamJSON incrementally decodes response body, which is either JSON array or newline-delimited
+// JSON (NDJSON), sending elements to returned channel as they arrive. It's intended for responses
+// returned by Client.DoRaw, so whole payload is never held in memory:
+//
+//	resp, err := client.DoRaw(req)
+//	if err != nil {
+//		return err
+//	}
+//
+//	items, errs := httpr.StreamJSON[Item](resp)
+//	for item := range items {
+//		...
+//	}
+//	if err = <-errs; err != nil {
+//		return err
+//	}
+//
+// Items channel is closed after body is exhausted or decoding failed, error channel receives at most
+// one error and is closed afterwards. Response body is closed when decoding ends. Decoding stops, when
+// context of request is cancelled, so consumers can abandon stream without leaking goroutine.
+func StreamJSON[T any](resp *http.Response) (<-chan T, <-chan error) {
+	items := make(chan T)
+	errs := make(chan error, 1)
+
+	ctx := context.Background()
+	if resp != nil && resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+
+	go func() {
+		defer close(errs)
+		defer close(items)
+
+		if resp == nil || resp.Body == nil {
+			errs <- errors.New("response body is nil")
+			return
+		}
+		defer resp.Body.Close() //nolint:errcheck
+
+		if err := streamJSON(ctx, resp.Body, items); err != nil {
+			if ctx.Err() == nil {
+				err = withSentinel(ErrDecodeBody, err)
+			}
+			errs <- err
+		}
+	}()
+
+	return items, errs
+}
+
+func streamJSON[T any](ctx context.Context, body io.Reader, items chan<- T) error {
+	reader := bufio.NewReader(body)
+	array, err := isJSONArray(reader)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	dec := json.NewDecoder(reader)
+	if array {
+		// Opening bracket was already checked.
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if array && !dec.More() {
+			if _, err = dec.Token(); err != nil {
+				return fmt.Errorf("failed to read end of array: %w", err)
+			}
+			return nil
+		}
+
+		var item T
+		if err = dec.Decode(&item); err != nil {
+			if !array && errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		select {
+		case items <- item:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isJSONArray skips leading whitespace and reports whether stream starts with JSON array.
+func isJSONArray(reader *bufio.Reader) (bool, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return false, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			return b == '[', reader.UnreadByte()
+		}
+	}
+}
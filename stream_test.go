@@ -0,0 +1,93 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamJSON(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	testCases := []struct {
+		name      string
+		body      string
+		expected  []int
+		wantError bool
+	}{
+		{name: "array", body: ` [{"id":1}, {"id":2},{"id":3}]`, expected: []int{1, 2, 3}},
+		{name: "empty array", body: `[]`},
+		{name: "NDJSON", body: "{\"id\":1}\n{\"id\":2}\n", expected: []int{1, 2}},
+		{name: "empty body", body: ""},
+		{name: "truncated array", body: `[{"id":1},{"id":`, expected: []int{1}, wantError: true},
+		{name: "invalid element", body: `[{"id":1},"two"]`, expected: []int{1}, wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer ts.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+			resp, err := New().DoRaw(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			items, errs := StreamJSON[item](resp)
+
+			var ids []int
+			for it := range items {
+				ids = append(ids, it.ID)
+			}
+			err = <-errs
+
+			if len(ids) != len(tc.expected) {
+				t.Fatalf("expected items %v, got %v instead", tc.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.expected[i] {
+					t.Fatalf("expected items %v, got %v instead", tc.expected, ids)
+				}
+			}
+			if tc.wantError && !errors.Is(err, ErrDecodeBody) {
+				t.Errorf("expected ErrDecodeBody, got %v instead", err)
+			}
+			if !tc.wantError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestStreamJSONCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`[1,2,3,4,5]`))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	resp, err := New().DoRaw(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	items, errs := StreamJSON[int](resp)
+	if first := <-items; first != 1 {
+		t.Fatalf("expected first item 1, got %d instead", first)
+	}
+	cancel()
+
+	for range items {
+	}
+	if err = <-errs; err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v instead", err)
+	}
+}
@@ -0,0 +1,55 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+)
+
+type tagsContextKey struct{}
+
+// ContextWithTags returns copy of parent context carrying provided request tags. Tags are
+// merged with ones already present in parent context, see TagsFromContext.
+func ContextWithTags(parent context.Context, tags map[string]string) context.Context {
+	return context.WithValue(parent, tagsContextKey{}, mergeTags(TagsFromContext(parent), tags))
+}
+
+// TagsFromContext returns request tags carried by context. During request execution, context of
+// request passed to hooks holds all tags of request, so they can be used for logging.
+// Returned map must not be modified.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsContextKey{}).(map[string]string)
+	return tags
+}
+
+// SetTag sets request tag, e.g. logical operation name. Tags are passed to StatsHandler events,
+// request context (see TagsFromContext) and Response, so traffic can be broken down by operation
+// rather than raw URL.
+func (rb *RequestBuilder) SetTag(key, value string) *RequestBuilder {
+	if rb.tags == nil {
+		rb.tags = make(map[string]string)
+	}
+	rb.tags[key] = value
+	return rb
+}
+
+// requestTags returns tags of request: ones set with WithTags, overridden by ones carried
+// by request context.
+func requestTags(req *http.Request, settings clientSettings) map[string]string {
+	ctxTags := TagsFromContext(req.Context())
+	if len(settings.tags) == 0 {
+		return ctxTags
+	}
+	return mergeTags(settings.tags, ctxTags)
+}
+
+// mergeTags returns new map with tags of both maps, values of src taking precedence.
+func mergeTags(dst, src map[string]string) map[string]string {
+	merged := make(map[string]string, len(dst)+len(src))
+	for key, value := range dst {
+		merged[key] = value
+	}
+	for key, value := range src {
+		merged[key] = value
+	}
+	return merged
+}
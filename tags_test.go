@@ -0,0 +1,73 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRequestTags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var (
+		hookTags  map[string]string
+		statsTags []map[string]string
+	)
+	client := New(
+		WithTags(map[string]string{"service": "billing", "operation": "default"}),
+		WithPreRequestHook(func(req *http.Request) error {
+			hookTags = TagsFromContext(req.Context())
+			return nil
+		}),
+		WithStatsHandler(StatsHandlerFunc(func(_ context.Context, event StatsEvent) {
+			switch e := event.(type) {
+			case RequestStart:
+				statsTags = append(statsTags, e.Tags)
+			case ResponseReceived:
+				statsTags = append(statsTags, e.Tags)
+			case RequestEnd:
+				statsTags = append(statsTags, e.Tags)
+			}
+		})),
+	)
+
+	rb := NewRequest().Get(ts.URL, nil).SetTag("operation", "get-invoice")
+	resp, err := client.DoBuilder(rb, WithTags(map[string]string{"region": "eu"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]string{"service": "billing", "operation": "get-invoice", "region": "eu"}
+	if !reflect.DeepEqual(resp.Tags(), expected) {
+		t.Errorf("expected response tags %v, got %v instead", expected, resp.Tags())
+	}
+	if !reflect.DeepEqual(hookTags, expected) {
+		t.Errorf("expected hook tags %v, got %v instead", expected, hookTags)
+	}
+	if len(statsTags) != 3 {
+		t.Fatalf("expected 3 stats events, got %d instead", len(statsTags))
+	}
+	for _, tags := range statsTags {
+		if !reflect.DeepEqual(tags, expected) {
+			t.Errorf("expected stats event tags %v, got %v instead", expected, tags)
+		}
+	}
+}
+
+func TestContextWithTags(t *testing.T) {
+	ctx := ContextWithTags(context.Background(), map[string]string{"a": "1", "b": "2"})
+	ctx = ContextWithTags(ctx, map[string]string{"b": "3"})
+
+	expected := map[string]string{"a": "1", "b": "3"}
+	if tags := TagsFromContext(ctx); !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected tags %v, got %v instead", expected, tags)
+	}
+	if tags := TagsFromContext(context.Background()); tags != nil {
+		t.Errorf("expected no tags, got %v instead", tags)
+	}
+}
@@ -0,0 +1,96 @@
+package httpr
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RequestTemplate captures common parts of requests to the same endpoint - method, URL
+// with path placeholders, headers, query parameters and body factory, from which
+// RequestBuilder instances are created for each call.
+//
+//	tmpl := httpr.NewRequestTemplate().
+//		SetMethod(http.MethodGet).
+//		SetURL("https://mysite.com/v1/users/{id}").
+//		SetHeader("Accept", "application/json")
+//
+//	req, err := tmpl.NewRequest(map[string]string{"id": "42"}).SetQueryParam("expand", "true").Build()
+type RequestTemplate struct {
+	method      string
+	url         string
+	headers     map[string][]string
+	queryParams url.Values
+	bodyFn      func() any
+}
+
+// NewRequestTemplate creates new empty RequestTemplate instance.
+func NewRequestTemplate() *RequestTemplate {
+	return &RequestTemplate{
+		headers:     make(map[string][]string),
+		queryParams: make(url.Values),
+	}
+}
+
+// SetMethod sets method of templated requests.
+func (t *RequestTemplate) SetMethod(method string) *RequestTemplate {
+	t.method = method
+	return t
+}
+
+// SetURL sets URL of templated requests. URL may contain placeholders in form of "{name}",
+// which are substituted with path parameters passed to NewRequest.
+func (t *RequestTemplate) SetURL(requestURL string) *RequestTemplate {
+	t.url = requestURL
+	return t
+}
+
+// SetHeader sets header with provided key and value.
+func (t *RequestTemplate) SetHeader(key, value string) *RequestTemplate {
+	t.headers[key] = append(t.headers[key], value)
+	return t
+}
+
+// SetHeaders sets headers for each key/value pair in provided map.
+func (t *RequestTemplate) SetHeaders(headers map[string]string) *RequestTemplate {
+	for key, value := range headers {
+		t.SetHeader(key, value)
+	}
+
+	return t
+}
+
+// SetQueryParam sets query parameter with following key and value.
+func (t *RequestTemplate) SetQueryParam(key, value string) *RequestTemplate {
+	t.queryParams.Set(key, value)
+	return t
+}
+
+// SetBodyFactory sets function, which creates body for each templated request.
+// Factory is used instead of body value, since body readers can't be reused between requests.
+func (t *RequestTemplate) SetBodyFactory(bodyFn func() any) *RequestTemplate {
+	t.bodyFn = bodyFn
+	return t
+}
+
+// NewRequest creates new RequestBuilder from template. URL placeholders are substituted
+// with escaped values of provided path parameters. Returned builder can be modified
+// without affecting the template.
+func (t *RequestTemplate) NewRequest(pathParams map[string]string) *RequestBuilder {
+	requestURL := t.url
+	for key, value := range pathParams {
+		requestURL = strings.ReplaceAll(requestURL, "{"+key+"}", url.PathEscape(value))
+	}
+
+	rb := NewRequest().SetMethod(t.method).SetURL(requestURL)
+	for key, values := range t.headers {
+		rb.headers[key] = append([]string(nil), values...)
+	}
+	for key, values := range t.queryParams {
+		rb.queryParams[key] = append([]string(nil), values...)
+	}
+	if t.bodyFn != nil {
+		rb.SetBody(t.bodyFn())
+	}
+
+	return rb
+}
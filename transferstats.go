@@ -0,0 +1,167 @@
+package httpr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// _transferStatsInterval is minimal interval between consecutive transfer stats reports.
+const _transferStatsInterval = 500 * time.Millisecond
+
+// TransferDirection tells, whether transfer stats describe request or response body.
+type TransferDirection int
+
+const (
+	// TransferUpload describes request body being sent.
+	TransferUpload TransferDirection = iota
+	// TransferDownload describes response body being received.
+	TransferDownload
+)
+
+// TransferStats describes progress of body transfer.
+type TransferStats struct {
+	Direction TransferDirection
+	// URL is request URL.
+	URL string
+	// Bytes is number of bytes transferred so far.
+	Bytes int64
+	// Total is expected body size taken from Content-Length or -1, if it's unknown.
+	Total int64
+	// BytesPerSec is throughput since previous report.
+	BytesPerSec float64
+	// Elapsed is time passed since transfer start.
+	Elapsed time.Duration
+	// ETA is estimated time left, based on average throughput, or -1, if total size is unknown.
+	ETA time.Duration
+	// Done is set for final report, sent once body is exhausted or closed.
+	Done bool
+}
+
+// TransferStatsFunc receives transfer progress reports, see WithTransferStats.
+type TransferStatsFunc func(stats TransferStats)
+
+// statsBody is io.ReadCloser, which counts bytes read from underlying body and reports progress.
+type statsBody struct {
+	io.ReadCloser
+	fn        TransferStatsFunc
+	direction TransferDirection
+	url       string
+	total     int64
+
+	mu         sync.Mutex
+	bytes      int64
+	start      time.Time
+	lastReport time.Time
+	lastBytes  int64
+	done       bool
+}
+
+func newStatsBody(body io.ReadCloser, fn TransferStatsFunc, direction TransferDirection, url string, total int64) *statsBody {
+	now := time.Now()
+	return &statsBody{
+		ReadCloser: body,
+		fn:         fn,
+		direction:  direction,
+		url:        url,
+		total:      total,
+		start:      now,
+		lastReport: now,
+	}
+}
+
+func (b *statsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mu.Lock()
+	b.bytes += int64(n)
+	stats, ok := b.stats(errors.Is(err, io.EOF), false)
+	b.mu.Unlock()
+
+	if ok {
+		b.fn(stats)
+	}
+	return n, err
+}
+
+func (b *statsBody) Close() error {
+	err := b.ReadCloser.Close()
+
+	b.mu.Lock()
+	stats, ok := b.stats(true, true)
+	b.mu.Unlock()
+
+	if ok {
+		b.fn(stats)
+	}
+	return err
+}
+
+// stats returns progress report, if it's due. Final report is returned only once.
+func (b *statsBody) stats(done, force bool) (TransferStats, bool) {
+	if b.done {
+		return TransferStats{}, false
+	}
+
+	now := time.Now()
+	sinceLast := now.Sub(b.lastReport)
+	if !done && !force && sinceLast < _transferStatsInterval {
+		return TransferStats{}, false
+	}
+
+	stats := TransferStats{
+		Direction: b.direction,
+		URL:       b.url,
+		Bytes:     b.bytes,
+		Total:     b.total,
+		Elapsed:   now.Sub(b.start),
+		ETA:       -1,
+		Done:      done,
+	}
+	if sinceLast > 0 {
+		stats.BytesPerSec = float64(b.bytes-b.lastBytes) / sinceLast.Seconds()
+	}
+	if b.total >= 0 {
+		switch {
+		case b.bytes >= b.total || done:
+			stats.ETA = 0
+		case b.bytes > 0:
+			avg := float64(b.bytes) / stats.Elapsed.Seconds()
+			stats.ETA = time.Duration(float64(b.total-b.bytes) / avg * float64(time.Second))
+		}
+	}
+
+	b.lastReport, b.lastBytes, b.done = now, b.bytes, done
+	return stats, true
+}
+
+// trackRequestBody wraps request body, so its upload progress is reported.
+func trackRequestBody(req *http.Request, fn TransferStatsFunc) {
+	if fn == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	if _, ok := req.Body.(*statsBody); ok {
+		return
+	}
+
+	total := req.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+	req.Body = newStatsBody(req.Body, fn, TransferUpload, req.URL.Redacted(), total)
+}
+
+// trackResponseBody wraps response body, so its download progress is reported.
+func trackResponseBody(resp *http.Response, fn TransferStatsFunc) {
+	if fn == nil || resp.Body == nil {
+		return
+	}
+
+	var url string
+	if resp.Request != nil {
+		url = resp.Request.URL.Redacted()
+	}
+	resp.Body = newStatsBody(resp.Body, fn, TransferDownload, url, resp.ContentLength)
+}
@@ -0,0 +1,103 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransferStats(t *testing.T) {
+	payload := bytes.Repeat([]byte{'x'}, 4096)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		_, _ = w.Write(payload)
+	}))
+	defer ts.Close()
+
+	var (
+		mu      sync.Mutex
+		reports []TransferStats
+	)
+	client := New(WithTransferStats(func(stats TransferStats) {
+		mu.Lock()
+		reports = append(reports, stats)
+		mu.Unlock()
+	}))
+
+	if _, err := client.Post(context.Background(), ts.URL, bytes.Repeat([]byte{'y'}, 1024)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	final := make(map[TransferDirection]TransferStats)
+	for _, stats := range reports {
+		if stats.Done {
+			if _, ok := final[stats.Direction]; ok {
+				t.Errorf("expected single final report for direction %d", stats.Direction)
+			}
+			final[stats.Direction] = stats
+		}
+	}
+
+	testCases := []struct {
+		direction TransferDirection
+		bytes     int64
+	}{
+		{direction: TransferUpload, bytes: 1024},
+		{direction: TransferDownload, bytes: 4096},
+	}
+	for _, tc := range testCases {
+		stats, ok := final[tc.direction]
+		if !ok {
+			t.Fatalf("expected final report for direction %d", tc.direction)
+		}
+		if stats.Bytes != tc.bytes || stats.Total != tc.bytes {
+			t.Errorf("expected %d of %d bytes, got %d of %d instead", tc.bytes, tc.bytes, stats.Bytes, stats.Total)
+		}
+		if stats.ETA != 0 {
+			t.Errorf("expected zero ETA, got %s instead", stats.ETA)
+		}
+		if stats.URL != ts.URL {
+			t.Errorf("expected URL '%s', got '%s' instead", ts.URL, stats.URL)
+		}
+	}
+}
+
+func TestStatsBodyPeriodicReports(t *testing.T) {
+	var reports []TransferStats
+	body := newStatsBody(io.NopCloser(bytes.NewReader(make([]byte, 100))), func(stats TransferStats) {
+		reports = append(reports, stats)
+	}, TransferDownload, "", 100)
+
+	buf := make([]byte, 25)
+	_, _ = body.Read(buf)
+	if len(reports) != 0 {
+		t.Fatalf("expected no reports before interval elapsed, got %d instead", len(reports))
+	}
+
+	body.lastReport = body.lastReport.Add(-_transferStatsInterval)
+	body.start = body.start.Add(-time.Second)
+	_, _ = body.Read(buf)
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d instead", len(reports))
+	}
+	if stats := reports[0]; stats.Bytes != 50 || stats.Done || stats.ETA <= 0 || stats.BytesPerSec <= 0 {
+		t.Errorf("unexpected intermediate report %+v", stats)
+	}
+
+	_ = body.Close()
+	_ = body.Close()
+	if len(reports) != 2 || !reports[1].Done {
+		t.Errorf("expected single final report, got %+v instead", reports)
+	}
+}
@@ -4,6 +4,57 @@ import (
 	"net/http"
 )
 
+// tuneTransport applies settings, which can only be configured at *http.Transport level.
+// If any of such settings is set, transport is cloned, so original instance is never modified.
+// Transports of other types are returned as is.
+func tuneTransport(rt http.RoundTripper, settings clientSettings) http.RoundTripper {
+	if settings.expectContinueTimeout <= 0 && settings.responseHeaderTimeout <= 0 &&
+		len(settings.acceptEncodings) == 0 && !settings.ssrfProtection &&
+		settings.ipPreference == IPAny && settings.dialFallbackDelay == 0 {
+		return rt
+	}
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	tr, ok := rt.(*http.Transport)
+	if !ok {
+		if settings.ssrfProtection {
+			return &ssrfCheckTransport{tr: rt}
+		}
+		return rt
+	}
+
+	tr = tr.Clone()
+	if settings.expectContinueTimeout > 0 {
+		tr.ExpectContinueTimeout = settings.expectContinueTimeout
+	}
+	if settings.responseHeaderTimeout > 0 {
+		tr.ResponseHeaderTimeout = settings.responseHeaderTimeout
+	}
+	if len(settings.acceptEncodings) > 0 {
+		tr.DisableCompression = true
+	}
+	if settings.ssrfProtection {
+		tr.DialContext = ssrfDialContext(tr.DialContext)
+		if tr.DialTLSContext != nil {
+			tr.DialTLSContext = ssrfDialContext(tr.DialTLSContext)
+		}
+		if tr.Proxy != nil {
+			tr.Proxy = ssrfProxy(tr.Proxy)
+		}
+	}
+	if settings.ipPreference != IPAny || settings.dialFallbackDelay != 0 {
+		// Address is resolved and ordered before SSRF check, so every dialed address is still checked.
+		tr.DialContext = ipDialContext(tr.DialContext, settings.ipPreference, settings.dialFallbackDelay)
+		if tr.DialTLSContext != nil {
+			tr.DialTLSContext = ipDialContext(tr.DialTLSContext, settings.ipPreference, settings.dialFallbackDelay)
+		}
+	}
+
+	return tr
+}
+
 type basicAuthTransport struct {
 	user string
 	pass string
@@ -0,0 +1,40 @@
+package httpr
+
+import (
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+)
+
+// RotationStrategy specifies how value is picked from rotated set, see WithUserAgentRotation.
+type RotationStrategy int
+
+const (
+	// RotateRoundRobin picks values in order, starting over after the last one.
+	RotateRoundRobin RotationStrategy = iota
+	// RotateRandom picks random value for each request.
+	RotateRandom
+)
+
+// UserAgentProviderFunc returns User-Agent for provided request.
+type UserAgentProviderFunc func(req *http.Request) string
+
+// newUserAgentRotation returns provider, which rotates provided User-Agents with strategy.
+func newUserAgentRotation(userAgents []string, strategy RotationStrategy) UserAgentProviderFunc {
+	userAgents = append([]string(nil), userAgents...)
+	if len(userAgents) == 0 {
+		return nil
+	}
+
+	if strategy == RotateRandom {
+		return func(_ *http.Request) string {
+			return userAgents[rand.Intn(len(userAgents))] //nolint:gosec
+		}
+	}
+
+	var counter uint64
+	return func(_ *http.Request) string {
+		n := atomic.AddUint64(&counter, 1) - 1
+		return userAgents[n%uint64(len(userAgents))]
+	}
+}
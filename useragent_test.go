@@ -0,0 +1,64 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserAgentRotation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.UserAgent()))
+	}))
+	defer ts.Close()
+
+	userAgents := []string{"agent-1", "agent-2", "agent-3"}
+
+	t.Run("RoundRobin", func(t *testing.T) {
+		c := New(WithDefaultHeader("User-Agent", "default"), WithUserAgentRotation(userAgents, RotateRoundRobin))
+
+		for i := 0; i < 2*len(userAgents); i++ {
+			resp, err := c.Get(context.Background(), ts.URL, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if expected := userAgents[i%len(userAgents)]; resp.String() != expected {
+				t.Errorf("expected User-Agent %q, got %q instead", expected, resp.String())
+			}
+		}
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		c := New(WithUserAgentRotation(userAgents, RotateRandom))
+
+		for i := 0; i < 10; i++ {
+			resp, err := c.Get(context.Background(), ts.URL, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !containsString(userAgents, resp.String()) {
+				t.Errorf("expected one of rotated User-Agents, got %q instead", resp.String())
+			}
+		}
+	})
+
+	t.Run("ExplicitUserAgent", func(t *testing.T) {
+		c := New(WithUserAgentProvider(func(req *http.Request) string { return "provided " + req.URL.Path }))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/path", nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if resp.String() != "provided /path" {
+			t.Errorf("expected provided User-Agent, got %q instead", resp.String())
+		}
+
+		req, _ = http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, nil)
+		req.Header.Set("User-Agent", "explicit")
+		if resp, err = c.Do(req); err != nil || resp.String() != "explicit" {
+			t.Errorf("expected explicit User-Agent to be kept, got %q (error %v)", resp.String(), err)
+		}
+	})
+}
@@ -2,28 +2,20 @@ package httpr
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
-func wrapWithCompressionReader(resp *http.Response, req *http.Request) (io.ReadCloser, error) {
-	for _, mimeType := range req.Header.Values("Accept") {
-		if strings.ToLower(mimeType) == "application/gzip" {
-			return gzip.NewReader(resp.Body)
-		}
-	}
-
-	return resp.Body, nil
-}
-
 func buildRequest(ctx context.Context, requestURL, method string, body any) (*http.Request, error) {
 	reqBody, err := convertBodyToReader(body)
 	if err != nil {
@@ -89,39 +81,99 @@ func Is5xx(code int) bool {
 	return code >= 500 && code < 600
 }
 
-// IsValidURL checks whether provided URL is valid or not.
+// IsValidURL checks whether provided URL is valid or not. Absolute URLs with any scheme are accepted,
+// including ones with localhost, single-label (e.g. cluster-internal) hostnames, IPv4 and IPv6 literals
+// and ports. See ValidateURL for strict validation.
 func IsValidURL(rawURL string) bool {
+	return ValidateURL(rawURL, false) == nil
+}
+
+// ValidateURL returns error describing why provided URL is malformed. In strict mode URL must also
+// have "http", "https" or registered custom scheme (see RegisterScheme) and its host must be either
+// IP literal or fully qualified domain name, so "localhost" and single-label hostnames are rejected.
+// Hosts of URLs with custom schemes aren't checked, since such URLs may address e.g. socket paths.
+func ValidateURL(rawURL string, strict bool) error {
 	parsedURL, err := url.ParseRequestURI(rawURL)
 	if err != nil {
-		return false
+		return err
 	}
 
-	if strings.TrimSpace(parsedURL.Host) == "" {
-		return false
+	return validateURL(parsedURL, strict)
+}
+
+func validateURL(u *url.URL, strict bool) error {
+	if u.Scheme == "" {
+		return errors.New("missing scheme")
+	}
+	_, customScheme := lookupScheme(u.Scheme)
+	if strict && u.Scheme != "http" && u.Scheme != "https" && !customScheme {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
 	}
 
-	if len(strings.Split(parsedURL.Host, ".")) < 2 {
-		return false
+	if customScheme {
+		return nil
+	}
+
+	host := u.Hostname()
+	if strings.TrimSpace(host) == "" {
+		return errors.New("missing host")
 	}
 
-	if strings.Contains(parsedURL.Host, "http:") || strings.Contains(parsedURL.Host, "https:") {
+	if port := u.Port(); port != "" || strings.HasSuffix(u.Host, ":") {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid port %q", port)
+		}
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return nil
+	}
+	if strings.HasPrefix(u.Host, "[") {
+		return fmt.Errorf("invalid IP address %q", host)
+	}
+
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(host) > 253 {
+		return errors.New("hostname is too long")
+	}
+	for _, label := range labels {
+		if !isValidHostLabel(label) {
+			return fmt.Errorf("invalid hostname %q", host)
+		}
+	}
+
+	if strict {
+		if len(labels) < 2 {
+			return fmt.Errorf("hostname %q is not fully qualified", host)
+		}
+		if _, err := strconv.Atoi(labels[len(labels)-1]); err == nil {
+			return fmt.Errorf("invalid hostname %q", host)
+		}
+	}
+
+	return nil
+}
+
+// isValidHostLabel checks whether hostname label consists of letters, digits, hyphens and underscores
+// and doesn't start or end with hyphen. Non-ASCII letters are allowed for internationalized names.
+func isValidHostLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
 		return false
 	}
 
+	for _, r := range label {
+		if r != '-' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
 	return true
 }
 
 // RandomDelay is pre-built PreRequestHookFn compliant function used for delaying request execution
-// by random delay. Random delay is calculated by calling rand.Int63n with provided delayLimit argument value.
+// by random delay in [0, delayLimit) range. Waiting is interrupted, when request context is done.
+// See Delay and Jitter for other distributions and reproducible randomness.
 func RandomDelay(delayLimit time.Duration) PreRequestHookFn {
-	return func(req *http.Request) error {
-		if delayLimit < 0 {
-			return nil
-		}
-
-		//nolint:gosec
-		delayMs := rand.Int63n(int64(delayLimit))
-		time.Sleep(time.Millisecond * time.Duration(delayMs))
-		return nil
-	}
+	return Delay(func() time.Duration {
+		return _defaultJitter.Full(delayLimit)
+	})
 }
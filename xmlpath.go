@@ -0,0 +1,164 @@
+package httpr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is an element of XML document tree used by Response.XMLPath.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlNode
+	content  []any // string and *xmlNode items in document order
+}
+
+// XMLPath returns text content of the first element matching provided path in response XML body,
+// which is useful for quick extraction from XML, Atom or RSS responses. Supported path syntax
+// is a subset of XPath: absolute location path of element names (namespace prefixes are ignored)
+// with "*" wildcard and 1-based position predicates, optionally ending with attribute step,
+// e.g. "/feed/entry[1]/title" or "/feed/entry[2]/link/@href". If nothing matches,
+// error wrapping ErrPathNotFound is returned.
+func (r *Response) XMLPath(path string) (string, error) {
+	root, err := parseXMLTree(r.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid XML path %q: path must be absolute", path)
+	}
+
+	nodes := []*xmlNode{{children: []*xmlNode{root}}}
+	steps := strings.Split(path[1:], "/")
+	for i, step := range steps {
+		if strings.HasPrefix(step, "@") {
+			if i != len(steps)-1 {
+				return "", fmt.Errorf("invalid XML path %q: attribute step must be the last one", path)
+			}
+			for _, node := range nodes {
+				for _, attr := range node.attrs {
+					if attr.Name.Local == step[1:] {
+						return attr.Value, nil
+					}
+				}
+			}
+			return "", fmt.Errorf("%w: %q", ErrPathNotFound, path)
+		}
+
+		name, position, err := parseXMLStep(step)
+		if err != nil {
+			return "", fmt.Errorf("invalid XML path %q: %w", path, err)
+		}
+
+		var matched []*xmlNode
+		for _, node := range nodes {
+			n := 0
+			for _, child := range node.children {
+				if name != "*" && child.name != name {
+					continue
+				}
+				n++
+				if position == 0 || position == n {
+					matched = append(matched, child)
+				}
+			}
+		}
+		if len(matched) == 0 {
+			return "", fmt.Errorf("%w: %q", ErrPathNotFound, path)
+		}
+		nodes = matched
+	}
+
+	return nodes[0].text(), nil
+}
+
+// parseXMLStep parses location step like "entry" or "entry[2]" into element name and position.
+// Zero position means, that step has no position predicate.
+func parseXMLStep(step string) (string, int, error) {
+	name, predicate, found := strings.Cut(step, "[")
+	if name == "" {
+		return "", 0, errors.New("empty location step")
+	}
+	if !found {
+		return name, 0, nil
+	}
+
+	position, err := strconv.Atoi(strings.TrimSuffix(predicate, "]"))
+	if err != nil || !strings.HasSuffix(predicate, "]") || position < 1 {
+		return "", 0, fmt.Errorf("unsupported predicate in step %q", step)
+	}
+
+	return name, position, nil
+}
+
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	var (
+		dec   = xml.NewDecoder(bytes.NewReader(data))
+		stack []*xmlNode
+		root  *xmlNode
+	)
+	dec.Strict = false
+
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+				parent.content = append(parent.content, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.content = append(parent.content, string(t))
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("failed to decode response XML: no root element")
+	}
+
+	return root, nil
+}
+
+// text returns concatenated text content of node and its descendants.
+func (n *xmlNode) text() string {
+	var sb strings.Builder
+	n.writeText(&sb)
+
+	return strings.TrimSpace(sb.String())
+}
+
+func (n *xmlNode) writeText(sb *strings.Builder) {
+	for _, item := range n.content {
+		switch v := item.(type) {
+		case string:
+			sb.WriteString(v)
+		case *xmlNode:
+			v.writeText(sb)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package httpr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseXMLPath(t *testing.T) {
+	resp := &Response{
+		rawResp: &http.Response{},
+		body: []byte(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Example Feed</title>
+	<entry>
+		<title>First entry</title>
+		<link href="https://example.com/1"/>
+	</entry>
+	<entry>
+		<title>Second <em>entry</em></title>
+		<link href="https://example.com/2"/>
+	</entry>
+</feed>`),
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/feed/title", expected: "Example Feed"},
+		{path: "/feed/entry[1]/title", expected: "First entry"},
+		{path: "/feed/entry/title", expected: "First entry"},
+		{path: "/feed/entry[2]/title", expected: "Second entry"},
+		{path: "/feed/entry[2]/link/@href", expected: "https://example.com/2"},
+		{path: "/feed/*[2]/title", expected: "First entry"},
+		{path: "/*/entry[2]/link/@href", expected: "https://example.com/2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := resp.XMLPath(tt.path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected value %q, got %q instead", tt.expected, value)
+			}
+		})
+	}
+
+	for _, path := range []string{"/feed/entry[3]/title", "/rss/channel", "/feed/entry/@id"} {
+		if _, err := resp.XMLPath(path); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("expected ErrPathNotFound for path %q, got %v", path, err)
+		}
+	}
+
+	for _, path := range []string{"feed/title", "/feed/entry[last()]", "/feed/@id/title"} {
+		if _, err := resp.XMLPath(path); err == nil || errors.Is(err, ErrPathNotFound) {
+			t.Errorf("expected invalid path error for path %q, got %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/hickar/httpr/yamlconfig
+
+go 1.18
+
+require github.com/hickar/httpr v0.1.0
+
+require gopkg.in/yaml.v3 v3.0.1
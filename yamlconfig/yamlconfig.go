@@ -0,0 +1,38 @@
+// Package yamlconfig loads httpr client configuration from YAML files.
+// It's separate module, so core httpr module stays free of dependencies.
+package yamlconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hickar/httpr"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads httpr.Config from YAML file located at path. Durations are written as strings
+// accepted by time.ParseDuration, e.g. "1m30s":
+//
+//	timeout: 30s
+//	retryCount: 3
+//	retryDelay: 100ms
+//	retryBackoff: 2
+//	retryMaxDelay: 5s
+func Load(path string) (httpr.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return httpr.Config{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse unmarshals YAML document into httpr.Config.
+func Parse(data []byte) (httpr.Config, error) {
+	var cfg httpr.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return cfg, nil
+}
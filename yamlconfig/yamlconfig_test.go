@@ -0,0 +1,53 @@
+package yamlconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+timeout: 30s
+retryCount: 3
+retryDelay: 10ms
+retryBackoff: 2
+retryMaxDelay: 1s
+auth:
+  token: secret
+defaultHeaders:
+  X-Service: test
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if time.Duration(cfg.Timeout) != 30*time.Second {
+		t.Errorf("expected timeout %v, got %v", 30*time.Second, time.Duration(cfg.Timeout))
+	}
+	if cfg.RetryCount != 3 || time.Duration(cfg.RetryDelay) != 10*time.Millisecond {
+		t.Errorf("expected 3 retries with 10ms delay, got %d and %v", cfg.RetryCount, time.Duration(cfg.RetryDelay))
+	}
+	if cfg.RetryBackoff != 2 || time.Duration(cfg.RetryMaxDelay) != time.Second {
+		t.Errorf("expected retry backoff 2 with max delay 1s, got %v and %v", cfg.RetryBackoff, time.Duration(cfg.RetryMaxDelay))
+	}
+	if cfg.Auth.Token != "secret" || cfg.DefaultHeaders["X-Service"] != "test" {
+		t.Errorf("unexpected auth and headers: %+v, %v", cfg.Auth, cfg.DefaultHeaders)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse([]byte("timeout: forever")); err == nil {
+		t.Error("expected error for malformed duration")
+	}
+	if _, err := Load("/nonexistent/config.yaml"); err == nil {
+		t.Error("expected error for missing config file")
+	}
+}
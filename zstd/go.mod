@@ -0,0 +1,7 @@
+module github.com/hickar/httpr/zstd
+
+go 1.18
+
+require github.com/hickar/httpr v0.1.0
+
+require github.com/klauspost/compress v1.16.7
@@ -0,0 +1,39 @@
+// Package zstd adds Zstandard (RFC 8878) content encoding support to httpr. It's separate module,
+// so core httpr module stays free of dependencies. Importing package registers "zstd" decompressor
+// globally, so it's used with WithAutoDecompression and can be negotiated with WithAcceptEncoding:
+//
+//	import _ "github.com/hickar/httpr/zstd"
+//
+//	client := httpr.New(httpr.WithAcceptEncoding("zstd", "gzip"))
+package zstd
+
+import (
+	"io"
+
+	"github.com/hickar/httpr"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encoding is 'Content-Encoding' token of Zstandard.
+const Encoding = "zstd"
+
+// MaxWindowSize is maximum window size accepted by decoder. RFC 9659 limits window of
+// HTTP zstd content to 8MB, so malicious responses can't make decoder allocate more.
+const MaxWindowSize = 8 << 20
+
+func init() {
+	httpr.RegisterDecompressor(Encoding, Decompress)
+}
+
+// Decompress is httpr.Decompressor, which decodes Zstandard stream. It can be passed
+// to httpr.WithDecompressor, if only some clients must support zstd.
+func Decompress(r io.Reader) (io.ReadCloser, error) {
+	// Single-threaded decoder is used, since response bodies are read sequentially
+	// and decoder goroutines would outlive unclosed bodies otherwise.
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(MaxWindowSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return decoder.IOReadCloser(), nil
+}
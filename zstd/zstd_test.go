@@ -0,0 +1,55 @@
+package zstd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hickar/httpr"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDecompress(t *testing.T) {
+	content := []byte("zstd decompressed content")
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	compressed := encoder.EncodeAll(content, nil)
+	_ = encoder.Close()
+
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", Encoding)
+		_, _ = w.Write(compressed)
+	}))
+	defer server.Close()
+
+	resp, err := httpr.New(httpr.WithAcceptEncoding("zstd", "gzip")).Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected request error: %v", err)
+	}
+	if resp.String() != string(content) {
+		t.Fatalf("expected body %q, got %q instead", content, resp.String())
+	}
+	if acceptEncoding != "zstd, gzip" {
+		t.Fatalf("expected zstd to be advertised, got %q instead", acceptEncoding)
+	}
+	if resp.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("expected Content-Encoding to be removed, got %q instead", resp.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestDecompressMalformed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", Encoding)
+		_, _ = w.Write([]byte("not zstd"))
+	}))
+	defer server.Close()
+
+	if _, err := httpr.New(httpr.WithAutoDecompression(true)).Get(context.Background(), server.URL, nil); err == nil {
+		t.Fatal("expected error for malformed zstd content")
+	}
+}